@@ -0,0 +1,3 @@
+// Package apierrors contains the errors returned by the services, which can be inspected with `errors.Is` against the
+// sentinel errors or with `errors.As` to retrieve the structured details of the failure.
+package apierrors
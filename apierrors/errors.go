@@ -0,0 +1,239 @@
+package apierrors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/RedisLabs/rediscloud-go-api/redis"
+)
+
+var (
+	// ErrNotFound matches errors caused by the resource not existing.
+	ErrNotFound = errors.New("not found")
+	// ErrUnauthorized matches errors caused by the credentials being invalid or not allowed to make the request.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrConflict matches errors caused by the resource being in a state that doesn't allow the request.
+	ErrConflict = errors.New("conflict")
+	// ErrRateLimited matches errors caused by too many requests being made.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrTaskFailed matches errors caused by a task not being processed successfully.
+	ErrTaskFailed = errors.New("task failed")
+	// ErrInvalid matches errors caused by a request failing validation before it was sent.
+	ErrInvalid = errors.New("invalid request")
+)
+
+// HTTPError is returned when the API responds to a request with an unsuccessful status code.
+type HTTPError struct {
+	Name       string
+	StatusCode int
+	// Body is the raw body of the response, which is kept even if it could be parsed into the fields below.
+	Body []byte
+	// Attempts is the number of times the request was sent before giving up.
+	Attempts int
+	// ErrorCode is the `type` of the error document returned by the API, e.g. `SUBSCRIPTION_NOT_ACTIVE`.
+	ErrorCode string
+	// Description is the human-readable `description` of the error document returned by the API.
+	Description string
+	// Status is the `status` of the error document returned by the API, e.g. `400 BAD_REQUEST`.
+	Status string
+	// CorrelationID is the `X-Correlation-Id` that the request was sent with, to quote when contacting support.
+	CorrelationID string
+	// RequestID is the identifier that the API returned for the request, if any.
+	RequestID string
+	// ResourceKind and ResourceID identify the resource that the request was for - see `WithResource`.
+	ResourceKind string
+	ResourceID   string
+}
+
+// NewHTTPError creates an HTTPError for the response, parsing the body if it was an error document.
+func NewHTTPError(name string, statusCode int, body []byte, attempts int) *HTTPError {
+	h := &HTTPError{
+		Name:       name,
+		StatusCode: statusCode,
+		Body:       body,
+		Attempts:   attempts,
+	}
+
+	var document Error
+	if err := json.Unmarshal(body, &document); err == nil {
+		h.ErrorCode = redis.StringValue(document.Type)
+		h.Description = redis.StringValue(document.Description)
+		h.Status = redis.StringValue(document.Status)
+	}
+
+	return h
+}
+
+func (h *HTTPError) Error() string {
+	detail := string(h.Body)
+	if h.ErrorCode != "" || h.Description != "" {
+		detail = fmt.Sprintf("%s: %s", h.ErrorCode, h.Description)
+	}
+
+	message := fmt.Sprintf("failed to %s: %d - %s", h.Name, h.StatusCode, detail)
+	if h.Attempts > 1 {
+		message += fmt.Sprintf(" (after %d attempts)", h.Attempts)
+	}
+	if h.CorrelationID != "" {
+		message += fmt.Sprintf(" (correlation ID %s)", h.CorrelationID)
+	}
+	if h.RequestID != "" {
+		message += fmt.Sprintf(" (request ID %s)", h.RequestID)
+	}
+	return message
+}
+
+func (h *HTTPError) Is(target error) bool {
+	return target != nil && target == sentinelForStatus(h.StatusCode)
+}
+
+// Error is an error document returned by the API, such as the reason a task failed.
+type Error struct {
+	Type        *string `json:"type,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Status      *string `json:"status,omitempty"`
+}
+
+func (o Error) String() string {
+	output, err := json.Marshal(o)
+	if err != nil {
+		return fmt.Sprintf("%#v", o)
+	}
+	return string(output)
+}
+
+// StatusCode returns the HTTP status code at the start of the `Status` field, or an empty string if there isn't one.
+func (e *Error) StatusCode() string {
+	matches := errorStatusCode.FindStringSubmatch(redis.StringValue(e.Status))
+	if len(matches) == 2 {
+		return matches[1]
+	}
+	return ""
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s - %s: %s", redis.StringValue(e.Status), redis.StringValue(e.Type), redis.StringValue(e.Description))
+}
+
+func (e *Error) Is(target error) bool {
+	code, err := strconv.Atoi(e.StatusCode())
+	if err != nil {
+		return false
+	}
+	return target != nil && target == sentinelForStatus(code)
+}
+
+var errorStatusCode = regexp.MustCompile("^(\\d*).*$")
+
+// TaskError is returned when a task that was being waited on finished without being processed successfully.
+type TaskError struct {
+	TaskID      string
+	CommandType string
+	Status      string
+	Description string
+	// CorrelationID is the `X-Correlation-Id` that the task was waited on with, to quote when contacting support.
+	CorrelationID string
+	// ResourceKind and ResourceID identify the resource that the task was for - see `WithResource`.
+	ResourceKind string
+	ResourceID   string
+	// Err is the error reported by the task, if there was one.
+	Err error
+}
+
+func (t *TaskError) Error() string {
+	message := fmt.Sprintf("task %s failed %s - %s", t.TaskID, t.Status, t.Description)
+	if t.Err != nil {
+		message = fmt.Sprintf("task %s failed: %s", t.TaskID, t.Err)
+	}
+	if t.CorrelationID != "" {
+		message += fmt.Sprintf(" (correlation ID %s)", t.CorrelationID)
+	}
+	return message
+}
+
+func (t *TaskError) Is(target error) bool {
+	return target == ErrTaskFailed
+}
+
+func (t *TaskError) Unwrap() error {
+	return t.Err
+}
+
+// The kinds of resource that are set on errors by `WithResource`.
+const (
+	ResourceSubscription = "subscription"
+	ResourceDatabase     = "database"
+	ResourceCloudAccount = "cloud account"
+	ResourceVPCPeering   = "VPC peering"
+	ResourceTask         = "task"
+)
+
+// WithResource sets the kind and identifier of the resource that a request or task was for on any HTTPError or
+// TaskError in `err` that doesn't already have one, returning `err`. The identifier of a resource that belongs to a
+// subscription is prefixed with the subscription's, e.g. `12/3` for database 3 of subscription 12, and a request to
+// create or list such resources is for the subscription.
+func WithResource(err error, kind string, id string) error {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.ResourceKind == "" {
+		httpErr.ResourceKind = kind
+		httpErr.ResourceID = id
+	}
+	var taskErr *TaskError
+	if errors.As(err, &taskErr) && taskErr.ResourceKind == "" {
+		taskErr.ResourceKind = kind
+		taskErr.ResourceID = id
+	}
+	return err
+}
+
+// FieldError describes a single field of a request that isn't valid.
+type FieldError struct {
+	// Path of the field, using the names of the JSON fields - such as `cloudProviders[0].regions[0].region`.
+	Path    string
+	Message string
+}
+
+func (f FieldError) String() string {
+	return f.Path + " " + f.Message
+}
+
+// ValidationError is returned when a request fails validation, listing every field that isn't valid.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (v *ValidationError) Error() string {
+	var fields []string
+	for _, field := range v.Errors {
+		fields = append(fields, field.String())
+	}
+	return "invalid request: " + strings.Join(fields, ", ")
+}
+
+func (v *ValidationError) Is(target error) bool {
+	return target == ErrInvalid
+}
+
+func sentinelForStatus(statusCode int) error {
+	switch statusCode {
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrUnauthorized
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	}
+	return nil
+}
+
+var _ error = &HTTPError{}
+var _ error = &Error{}
+var _ error = &TaskError{}
+var _ error = &ValidationError{}
@@ -0,0 +1,185 @@
+package apierrors
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/RedisLabs/rediscloud-go-api/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestError_StatusCode(t *testing.T) {
+	tests := []struct {
+		name    string
+		subject *Error
+		want    string
+	}{
+		{
+			name: "no status code",
+			subject: &Error{
+				Status: redis.String("doesn't start with a number"),
+			},
+			want: "",
+		},
+		{
+			name: "starts with a status code",
+			subject: &Error{
+				Status: redis.String("418 I'm a teapot"),
+			},
+			want: "418",
+		},
+		{
+			name: "includes a number but doesn't start with it",
+			subject: &Error{
+				Status: redis.String("The number 42 should not be found"),
+			},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.subject.StatusCode(); got != tt.want {
+				t.Errorf("StatusCode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPError_Is(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		want       error
+	}{
+		{statusCode: 401, want: ErrUnauthorized},
+		{statusCode: 403, want: ErrUnauthorized},
+		{statusCode: 404, want: ErrNotFound},
+		{statusCode: 409, want: ErrConflict},
+		{statusCode: 429, want: ErrRateLimited},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.statusCode), func(t *testing.T) {
+			err := fmt.Errorf("wrapped: %w", &HTTPError{StatusCode: tt.statusCode})
+			assert.True(t, errors.Is(err, tt.want))
+			assert.False(t, errors.Is(err, ErrTaskFailed))
+		})
+	}
+
+	assert.False(t, errors.Is(&HTTPError{StatusCode: 500}, ErrNotFound))
+}
+
+func TestTaskError_IsAndUnwrap(t *testing.T) {
+	subject := &TaskError{
+		TaskID: "task",
+		Status: "processing-error",
+		Err: &Error{
+			Type:   redis.String("SUBSCRIPTION_NOT_FOUND"),
+			Status: redis.String("404 NOT_FOUND"),
+		},
+	}
+
+	assert.True(t, errors.Is(subject, ErrTaskFailed))
+	assert.True(t, errors.Is(subject, ErrNotFound))
+	assert.False(t, errors.Is(subject, ErrConflict))
+
+	var apiErr *Error
+	require.True(t, errors.As(subject, &apiErr))
+	assert.Equal(t, "SUBSCRIPTION_NOT_FOUND", redis.StringValue(apiErr.Type))
+}
+
+func TestNewHTTPError(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		want        *HTTPError
+		wantMessage string
+	}{
+		{
+			name: "error document",
+			body: `{"type":"SUBSCRIPTION_NOT_ACTIVE","status":"400 BAD_REQUEST","description":"Cannot preform any actions for subscription that is not in an active state"}`,
+			want: &HTTPError{
+				Name:        "create database",
+				StatusCode:  400,
+				Body:        []byte(`{"type":"SUBSCRIPTION_NOT_ACTIVE","status":"400 BAD_REQUEST","description":"Cannot preform any actions for subscription that is not in an active state"}`),
+				Attempts:    1,
+				ErrorCode:   "SUBSCRIPTION_NOT_ACTIVE",
+				Description: "Cannot preform any actions for subscription that is not in an active state",
+				Status:      "400 BAD_REQUEST",
+			},
+			wantMessage: "failed to create database: 400 - SUBSCRIPTION_NOT_ACTIVE: Cannot preform any actions for subscription that is not in an active state",
+		},
+		{
+			name: "not json",
+			body: `<html>Bad Gateway</html>`,
+			want: &HTTPError{
+				Name:       "create database",
+				StatusCode: 400,
+				Body:       []byte(`<html>Bad Gateway</html>`),
+				Attempts:   1,
+			},
+			wantMessage: "failed to create database: 400 - <html>Bad Gateway</html>",
+		},
+		{
+			name: "other json",
+			body: `{"message":"something else"}`,
+			want: &HTTPError{
+				Name:       "create database",
+				StatusCode: 400,
+				Body:       []byte(`{"message":"something else"}`),
+				Attempts:   1,
+			},
+			wantMessage: `failed to create database: 400 - {"message":"something else"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := NewHTTPError("create database", 400, []byte(tt.body), 1)
+			assert.Equal(t, tt.want, actual)
+			assert.Equal(t, tt.wantMessage, actual.Error())
+		})
+	}
+}
+
+func TestHTTPError_Error_includesIdentifiers(t *testing.T) {
+	subject := NewHTTPError("create database", 500, []byte(`oops`), 3)
+	subject.CorrelationID = "correlation"
+	subject.RequestID = "request"
+
+	assert.Equal(t, "failed to create database: 500 - oops (after 3 attempts) (correlation ID correlation) (request ID request)", subject.Error())
+}
+
+func TestTaskError_Error_includesCorrelationID(t *testing.T) {
+	subject := &TaskError{
+		TaskID:        "task",
+		Status:        "processing-error",
+		Description:   "Task request failed during processing.",
+		CorrelationID: "correlation",
+	}
+	assert.Equal(t, "task task failed processing-error - Task request failed during processing. (correlation ID correlation)", subject.Error())
+
+	subject.Err = &Error{
+		Type:        redis.String("SUBSCRIPTION_NOT_FOUND"),
+		Status:      redis.String("404 NOT_FOUND"),
+		Description: redis.String("Subscription was not found"),
+	}
+	assert.Equal(t, "task task failed: 404 NOT_FOUND - SUBSCRIPTION_NOT_FOUND: Subscription was not found (correlation ID correlation)", subject.Error())
+}
+
+func TestWithResource(t *testing.T) {
+	httpErr := NewHTTPError("update database", 409, []byte(`conflict`), 1)
+	err := WithResource(fmt.Errorf("wrapped: %w", httpErr), ResourceDatabase, "12/3")
+	WithResource(err, ResourceSubscription, "12")
+
+	assert.Equal(t, ResourceDatabase, httpErr.ResourceKind)
+	assert.Equal(t, "12/3", httpErr.ResourceID)
+
+	taskErr := &TaskError{TaskID: "task"}
+	WithResource(taskErr, ResourceSubscription, "12")
+
+	assert.Equal(t, ResourceSubscription, taskErr.ResourceKind)
+	assert.Equal(t, "12", taskErr.ResourceID)
+
+	assert.Nil(t, WithResource(nil, ResourceSubscription, "12"))
+}
@@ -0,0 +1,264 @@
+// Package cassette provides a RoundTripper that records the interactions with the Redis Cloud API to a file, and can
+// later replay them without network access, so that tests can be run deterministically:
+//
+//	recorder, err := cassette.New("testdata/create_database.json", cassette.ModeReplay)
+//	...
+//	client, err := rediscloud_api.NewClient(rediscloud_api.Transporter(recorder))
+//
+// When recording, the credential headers and any password or secret fields in the bodies are removed before anything
+// is written to the cassette.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+// Mode controls whether a Recorder is recording new interactions or replaying existing ones.
+type Mode int
+
+const (
+	// ModeRecord sends requests to the API, recording each interaction so it can be saved to the cassette
+	ModeRecord Mode = iota
+	// ModeReplay responds to requests with the interactions previously saved to the cassette
+	ModeReplay
+)
+
+// ErrMissingInteraction is returned, wrapped with the details of the request, when replaying a request that isn't
+// in the cassette.
+var ErrMissingInteraction = errors.New("cassette has no matching interaction")
+
+const version = 1
+
+type cassette struct {
+	Version      int            `json:"version"`
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a single request made to the API and the response it received.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+
+	replayed bool
+}
+
+type Request struct {
+	Method  string      `json:"method"`
+	Path    string      `json:"path"`
+	Query   string      `json:"query,omitempty"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+type Response struct {
+	StatusCode int         `json:"statusCode"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Recorder is a RoundTripper, which should be passed to the client with the `Transporter` option.
+type Recorder struct {
+	path      string
+	mode      Mode
+	transport http.RoundTripper
+
+	mu       sync.Mutex
+	cassette cassette
+}
+
+type Option func(*Recorder)
+
+// Transport sets the RoundTripper used to send requests while recording - will default to the Go default.
+func Transport(transport http.RoundTripper) Option {
+	return func(r *Recorder) {
+		r.transport = transport
+	}
+}
+
+// New creates a Recorder for the cassette at `path`. When replaying, the cassette must already exist; when recording,
+// `Save` must be called to write the interactions to the cassette.
+func New(path string, mode Mode, options ...Option) (*Recorder, error) {
+	r := &Recorder{
+		path:      path,
+		mode:      mode,
+		transport: http.DefaultTransport,
+		cassette:  cassette{Version: version},
+	}
+	for _, option := range options {
+		option(r)
+	}
+
+	if mode == ModeReplay {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cassette: %w", err)
+		}
+		if err := json.Unmarshal(data, &r.cassette); err != nil {
+			return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+		}
+		if r.cassette.Version != version {
+			return nil, fmt.Errorf("cassette %s has unsupported version %d", path, r.cassette.Version)
+		}
+	}
+
+	return r, nil
+}
+
+// Save writes the recorded interactions to the cassette, replacing anything already in it.
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return fmt.Errorf("cassette %s is not being recorded", r.path)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.path, append(data, '\n'), 0644)
+}
+
+// Interactions returns the interactions either recorded so far or loaded from the cassette.
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var interactions []Interaction
+	for _, interaction := range r.cassette.Interactions {
+		interactions = append(interactions, *interaction)
+	}
+	return interactions
+}
+
+func (r *Recorder) RoundTrip(request *http.Request) (*http.Response, error) {
+	body, err := readBody(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(request, body)
+	}
+	return r.record(request, body)
+}
+
+func (r *Recorder) record(request *http.Request, body []byte) (*http.Response, error) {
+	response, err := r.transport.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+
+	responseBody, err := ioutil.ReadAll(response.Body)
+	_ = response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = ioutil.NopCloser(bytes.NewReader(responseBody))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, &Interaction{
+		Request: Request{
+			Method:  request.Method,
+			Path:    request.URL.Path,
+			Query:   request.URL.Query().Encode(),
+			Headers: redactHeaders(request.Header),
+			Body:    redactBody(body),
+		},
+		Response: Response{
+			StatusCode: response.StatusCode,
+			Headers:    redactHeaders(response.Header),
+			Body:       redactBody(responseBody),
+		},
+	})
+
+	return response, nil
+}
+
+// replay responds with the first interaction matching the request that hasn't already been replayed, so a request
+// that is repeated (such as polling a task) receives each of the recorded responses in turn.
+func (r *Recorder) replay(request *http.Request, body []byte) (*http.Response, error) {
+	want := Request{
+		Method: request.Method,
+		Path:   request.URL.Path,
+		Query:  request.URL.Query().Encode(),
+		Body:   redactBody(body),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, interaction := range r.cassette.Interactions {
+		if interaction.replayed || !matches(interaction.Request, want) {
+			continue
+		}
+		interaction.replayed = true
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        interaction.Response.Headers.Clone(),
+			Body:          ioutil.NopCloser(bytes.NewReader([]byte(interaction.Response.Body))),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       request,
+		}, nil
+	}
+
+	description := want.Method + " " + want.Path
+	if want.Query != "" {
+		description += "?" + want.Query
+	}
+	if want.Body != "" {
+		description += " with body " + want.Body
+	}
+	return nil, fmt.Errorf("%w for %s in %s", ErrMissingInteraction, description, r.path)
+}
+
+func matches(recorded Request, want Request) bool {
+	return recorded.Method == want.Method &&
+		recorded.Path == want.Path &&
+		recorded.Query == want.Query &&
+		equalBodies(recorded.Body, want.Body)
+}
+
+// equalBodies compares JSON bodies by their content, so that the ordering of the fields and whitespace don't matter.
+func equalBodies(a string, b string) bool {
+	if a == b {
+		return true
+	}
+
+	var left, right interface{}
+	if json.Unmarshal([]byte(a), &left) != nil || json.Unmarshal([]byte(b), &right) != nil {
+		return false
+	}
+
+	leftData, _ := json.Marshal(left)
+	rightData, _ := json.Marshal(right)
+	return bytes.Equal(leftData, rightData)
+}
+
+func readBody(request *http.Request) ([]byte, error) {
+	if request.Body == nil {
+		return nil, nil
+	}
+
+	body, err := ioutil.ReadAll(request.Body)
+	_ = request.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
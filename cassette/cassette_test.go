@@ -0,0 +1,124 @@
+package cassette_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	rediscloud_api "github.com/RedisLabs/rediscloud-go-api"
+	"github.com/RedisLabs/rediscloud-go-api/cassette"
+	"github.com/RedisLabs/rediscloud-go-api/redis"
+	"github.com/RedisLabs/rediscloud-go-api/rediscloudtest"
+	"github.com/RedisLabs/rediscloud-go-api/service/databases"
+	"github.com/RedisLabs/rediscloud-go-api/service/subscriptions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCassette_RecordsAndReplaysInteractions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	fake := rediscloudtest.NewServer()
+	subID := fake.AddSubscription(subscriptions.Subscription{Name: redis.String("example")})
+
+	recorder, err := cassette.New(path, cassette.ModeRecord)
+	require.NoError(t, err)
+
+	recorded := createDatabase(t, fake.URL, recorder, subID)
+	require.NoError(t, recorder.Save())
+	fake.Close()
+
+	player, err := cassette.New(path, cassette.ModeReplay)
+	require.NoError(t, err)
+	assert.Len(t, player.Interactions(), len(recorder.Interactions()))
+
+	replayed := createDatabase(t, fake.URL, player, subID)
+	recorded.Security.Password = redis.String(cassette.Redacted)
+	assert.Equal(t, recorded, replayed)
+}
+
+func TestCassette_RemovesCredentialsAndPasswords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	fake := rediscloudtest.NewServer()
+	defer fake.Close()
+	subID := fake.AddSubscription(subscriptions.Subscription{Name: redis.String("example")})
+
+	recorder, err := cassette.New(path, cassette.ModeRecord)
+	require.NoError(t, err)
+	createDatabase(t, fake.URL, recorder, subID)
+	require.NoError(t, recorder.Save())
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "api-key-value")
+	assert.NotContains(t, string(data), "secret-key-value")
+	assert.NotContains(t, string(data), "database-password")
+	assert.Contains(t, string(data), cassette.Redacted)
+
+	for _, interaction := range recorder.Interactions() {
+		assert.Empty(t, interaction.Request.Headers.Get("X-Api-Key"))
+		assert.Empty(t, interaction.Request.Headers.Get("X-Api-Secret-Key"))
+	}
+}
+
+func TestCassette_FailsWhenAnInteractionIsMissing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	fake := rediscloudtest.NewServer()
+	subID := fake.AddSubscription(subscriptions.Subscription{Name: redis.String("example")})
+
+	recorder, err := cassette.New(path, cassette.ModeRecord)
+	require.NoError(t, err)
+	createDatabase(t, fake.URL, recorder, subID)
+	require.NoError(t, recorder.Save())
+	fake.Close()
+
+	player, err := cassette.New(path, cassette.ModeReplay)
+	require.NoError(t, err)
+
+	client := newClient(t, fake.URL, player)
+	_, err = client.Database.Create(context.Background(), subID, databases.CreateDatabase{
+		Name:            redis.String("other"),
+		MemoryLimitInGB: redis.Float64(1),
+	})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, cassette.ErrMissingInteraction), "got %v", err)
+	assert.Contains(t, err.Error(), `POST /subscriptions/`)
+	assert.Contains(t, err.Error(), `"name":"other"`)
+}
+
+func TestCassette_ReplayRequiresAnExistingCassette(t *testing.T) {
+	_, err := cassette.New(filepath.Join(t.TempDir(), "missing.json"), cassette.ModeReplay)
+	assert.Error(t, err)
+}
+
+func createDatabase(t *testing.T, url string, recorder *cassette.Recorder, subID int) *databases.Database {
+	client := newClient(t, url, recorder)
+	ctx := context.Background()
+
+	id, err := client.Database.Create(ctx, subID, databases.CreateDatabase{
+		Name:            redis.String("example"),
+		MemoryLimitInGB: redis.Float64(1),
+		Password:        redis.String("database-password"),
+	})
+	require.NoError(t, err)
+
+	db, err := client.Database.Get(ctx, subID, id)
+	require.NoError(t, err)
+	return db
+}
+
+func newClient(t *testing.T, url string, recorder *cassette.Recorder) *rediscloud_api.Client {
+	client, err := rediscloud_api.NewClient(
+		rediscloud_api.BaseURL(url),
+		rediscloud_api.Auth("api-key-value", "secret-key-value"),
+		rediscloud_api.Transporter(recorder),
+		rediscloud_api.TaskPolling(rediscloud_api.TaskPollingPolicy{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond}),
+	)
+	require.NoError(t, err)
+	return client
+}
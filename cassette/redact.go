@@ -0,0 +1,52 @@
+package cassette
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/RedisLabs/rediscloud-go-api/internal"
+)
+
+// Redacted replaces the value of any sensitive field in a recorded body.
+const Redacted = internal.Redacted
+
+// Headers that are never written to a cassette.
+var sensitiveHeaders = []string{
+	"X-Api-Key",
+	"X-Api-Secret-Key",
+	"Authorization",
+}
+
+func redactHeaders(headers http.Header) http.Header {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	redacted := headers.Clone()
+	for _, header := range sensitiveHeaders {
+		redacted.Del(header)
+	}
+	return redacted
+}
+
+// bodyRedactor redacts the value of any field in a JSON body whose name contains `password` or `secret`, such as a
+// database's password or a cloud account's secret key.
+var bodyRedactor = internal.Redactor{Sensitive: isSensitive}
+
+// redactBody redacts the sensitive fields of a JSON body. Anything that isn't JSON is kept unchanged.
+func redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	redacted, _, err := bodyRedactor.RedactJSON(body)
+	if err != nil {
+		return string(body)
+	}
+	return string(redacted)
+}
+
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	return strings.Contains(key, "password") || strings.Contains(key, "secret")
+}
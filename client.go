@@ -2,18 +2,27 @@ package rediscloud_api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"net/http/httputil"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/RedisLabs/rediscloud-go-api/internal"
+	"github.com/RedisLabs/rediscloud-go-api/logging"
+	"github.com/RedisLabs/rediscloud-go-api/metrics"
 	"github.com/RedisLabs/rediscloud-go-api/service/account"
 	"github.com/RedisLabs/rediscloud-go-api/service/cloud_accounts"
 	"github.com/RedisLabs/rediscloud-go-api/service/databases"
 	"github.com/RedisLabs/rediscloud-go-api/service/subscriptions"
+	"github.com/RedisLabs/rediscloud-go-api/service/tasks"
+	"github.com/RedisLabs/rediscloud-go-api/tracing"
 )
 
 type Client struct {
@@ -21,6 +30,9 @@ type Client struct {
 	CloudAccount *cloud_accounts.API
 	Database     *databases.API
 	Subscription *subscriptions.API
+	Task         *tasks.API
+
+	queue *internal.SubscriptionQueue
 }
 
 func NewClient(configs ...Option) (*Client, error) {
@@ -29,7 +41,8 @@ func NewClient(configs ...Option) (*Client, error) {
 		userAgent: userAgent,
 		apiKey:    os.Getenv(AccessKeyEnvVar),
 		secretKey: os.Getenv(SecretKeyEnvVar),
-		logger:    &defaultLogger{},
+		logger:    logging.FromPrintf(&defaultLogger{}),
+		tracer:    tracing.Noop,
 		transport: http.DefaultTransport,
 	}
 
@@ -41,44 +54,141 @@ func NewClient(configs ...Option) (*Client, error) {
 		Transport: config.roundTripper(),
 	}
 
-	client, err := internal.NewHttpClient(httpClient, config.baseUrl)
+	client, err := internal.NewHttpClient(httpClient, config.baseUrl, config.httpClientOptions()...)
 	if err != nil {
 		return nil, err
 	}
 
-	t := internal.NewAPI(client, config.logger)
+	logger := config.structuredLogger()
+	t := internal.NewAPI(client, logger, config.taskOptions()...)
+	b := config.busyRetry(client, logger)
+	q := config.subscriptionQueue(t)
 
-	a := account.NewAPI(client)
-	c := cloud_accounts.NewAPI(client, t, config.logger)
-	d := databases.NewAPI(client, t, config.logger)
-	s := subscriptions.NewAPI(client, t, config.logger)
+	a := account.NewAPI(client, account.WithTracer(config.tracer))
+	c := cloud_accounts.NewAPI(client, t, logger, cloud_accounts.WithTracer(config.tracer))
+	d := databases.NewAPI(client, t, logger, databases.WithTracer(config.tracer), databases.WithBusyRetry(b), databases.WithQueue(q))
+	s := subscriptions.NewAPI(client, t, logger, subscriptions.WithTracer(config.tracer), subscriptions.WithBusyRetry(b), subscriptions.WithQueue(q))
+	k := tasks.NewAPI(client, t, tasks.WithTracer(config.tracer))
 
 	return &Client{
 		Account:      a,
 		CloudAccount: c,
 		Database:     d,
 		Subscription: s,
+		Task:         k,
+		queue:        q,
 	}, nil
 }
 
+// SubscriptionQueueDepth returns the number of changes to the subscription, or its databases, that are either in
+// progress or waiting to start. This is always zero unless `SerializeSubscriptionChanges` has been enabled.
+func (c *Client) SubscriptionQueueDepth(subscription int) int {
+	return c.queue.Depth(subscription)
+}
+
 type Options struct {
 	baseUrl     string
 	apiKey      string
 	secretKey   string
 	userAgent   string
-	logger      Log
+	logger      logging.Logger
 	transport   http.RoundTripper
 	logRequests bool
+	retry       *RetryPolicy
+	rateLimit   *rateLimit
+	polling     *TaskPollingPolicy
+	observers   []TaskObserver
+	busyTimeout time.Duration
+	serialize   bool
+	validate    bool
+	redaction   *LogRedactionPolicy
+	metrics     metrics.Recorder
+	tracer      tracing.Tracer
+}
+
+func (o Options) taskOptions() []internal.APIOption {
+	var options []internal.APIOption
+	if o.polling != nil {
+		options = append(options, internal.WithPollingPolicy(o.polling.pollingPolicy()))
+	}
+	for _, observer := range o.observers {
+		options = append(options, internal.WithTaskObserver(taskObserver(observer)))
+	}
+	if o.metrics != nil {
+		options = append(options, internal.WithTaskMetrics(o.metrics))
+	}
+	options = append(options, internal.WithTaskTracer(o.tracer))
+	return options
+}
+
+// structuredLogger adds the fields attached to the context of a call, such as the operation and the resource IDs, to
+// every event logged for it.
+func (o Options) structuredLogger() logging.Logger {
+	return logging.WithContextFields(o.logger)
+}
+
+func (o Options) busyRetry(client *internal.HttpClient, logger logging.Logger) *internal.BusyRetry {
+	if o.busyTimeout <= 0 {
+		return nil
+	}
+
+	var polling internal.PollingPolicy
+	if o.polling != nil {
+		polling = o.polling.pollingPolicy()
+	}
+	return internal.NewBusyRetry(client, logger, o.busyTimeout, polling)
+}
+
+func (o Options) subscriptionQueue(waiter internal.TaskWaiter) *internal.SubscriptionQueue {
+	if !o.serialize {
+		return nil
+	}
+	return internal.NewSubscriptionQueue(waiter)
+}
+
+type rateLimit struct {
+	requestsPerSecond float64
+	burst             int
+}
+
+func (o Options) httpClientOptions() []internal.HttpClientOption {
+	var options []internal.HttpClientOption
+	if o.retry != nil {
+		options = append(options, internal.WithRetryPolicy(internal.RetryPolicy{
+			MaxAttempts:   o.retry.MaxAttempts,
+			InitialDelay:  o.retry.InitialDelay,
+			MaxDelay:      o.retry.MaxDelay,
+			MaxRetryAfter: o.retry.MaxRetryAfter,
+			RetryPost:     o.retry.RetryPost,
+		}))
+	}
+	if o.rateLimit != nil {
+		options = append(options, internal.WithRateLimiter(internal.NewRateLimiter(o.rateLimit.requestsPerSecond, o.rateLimit.burst)))
+	}
+	if o.validate {
+		options = append(options, internal.WithValidation())
+	}
+	if o.metrics != nil {
+		options = append(options, internal.WithMetrics(o.metrics))
+	}
+	options = append(options, internal.WithTracer(o.tracer))
+	return options
 }
 
 func (o Options) roundTripper() http.RoundTripper {
+	var redaction LogRedactionPolicy
+	if o.redaction != nil {
+		redaction = *o.redaction
+	}
+
 	return &credentialTripper{
 		apiKey:      o.apiKey,
 		secretKey:   o.secretKey,
 		wrapped:     o.transport,
 		logRequests: o.logRequests,
-		logger:      o.logger,
+		logger:      o.structuredLogger(),
 		userAgent:   o.userAgent,
+		redactor:    redaction.redactor(),
 	}
 }
 
@@ -100,7 +210,8 @@ func BaseURL(url string) Option {
 	}
 }
 
-// LogRequests allows the logging of HTTP request and responses - will default to false (disabled).
+// LogRequests allows the logging of HTTP request and responses - will default to false (disabled). Secrets are
+// redacted from what is logged, as configured by `LogRedaction`.
 func LogRequests(enable bool) Option {
 	return func(options *Options) {
 		options.logRequests = enable
@@ -122,14 +233,205 @@ func AdditionalUserAgent(additional string) Option {
 	}
 }
 
-// Logger allows for a custom implementation to handle the debug log messages - defaults to using the Go standard log
-// package.
+// Logger allows for a custom implementation to handle the log messages - defaults to using the Go standard log
+// package. Each event is written as its level, message and values, e.g.
+// `INFO: Waiting for the database to finish being updated operation="update database" subscriptionId=12`.
 func Logger(log Log) Option {
 	return func(options *Options) {
-		options.logger = log
+		options.logger = logging.FromPrintf(log)
 	}
 }
 
+// StructuredLogger sends the log events to a leveled, key/value logger instead of `Logger`. Lifecycle events, such as
+// waiting for a task, are logged at info; the polling of tasks and the requests logged by `LogRequests` are logged at
+// debug. Every event carries the operation and, where known, the subscription, database and task IDs.
+func StructuredLogger(logger logging.Logger) Option {
+	return func(options *Options) {
+		options.logger = logger
+	}
+}
+
+// Retry enables retrying of requests that fail with a 429 (Too Many Requests) or 5xx status - will default to no
+// retries. Only idempotent requests are retried unless `RetryPost` is set in the policy.
+func Retry(policy RetryPolicy) Option {
+	return func(options *Options) {
+		options.retry = &policy
+	}
+}
+
+// RateLimit restricts the rate of requests made by the client, across all services and including the polling of
+// tasks, to `requestsPerSecond` with bursts of up to `burst` requests - will default to no limit.
+func RateLimit(requestsPerSecond float64, burst int) Option {
+	return func(options *Options) {
+		if requestsPerSecond <= 0 {
+			options.rateLimit = nil
+			return
+		}
+		options.rateLimit = &rateLimit{requestsPerSecond: requestsPerSecond, burst: burst}
+	}
+}
+
+// ValidateRequests checks the create and update requests that have a `Validate` method before sending them, so that
+// mistakes such as an unknown eviction policy fail straight away with a `*apierrors.ValidationError` instead of in the
+// task started by the request - will default to disabled.
+func ValidateRequests(enable bool) Option {
+	return func(options *Options) {
+		options.validate = enable
+	}
+}
+
+// Metrics reports every request made to the API, including retries and the polling of tasks, and every task waited
+// on to the recorder, e.g. a `metrics.NewPrometheus()` - will default to not recording anything.
+func Metrics(recorder metrics.Recorder) Option {
+	return func(options *Options) {
+		options.metrics = recorder
+	}
+}
+
+// Tracer records a span for every operation, such as `create database for subscription 12`, with child spans for each
+// HTTP request and for waiting on the operation's task, and propagates the spans to the API in the W3C `traceparent`
+// header - will default to not tracing anything.
+func Tracer(tracer tracing.Tracer) Option {
+	return func(options *Options) {
+		if tracer == nil {
+			tracer = tracing.Noop
+		}
+		options.tracer = tracer
+	}
+}
+
+// RetryPolicy configures the retries made by the `Retry` option. Any zero values will be replaced with the defaults.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts made for a single request, including the first - defaults to 3.
+	MaxAttempts int
+	// InitialDelay is the delay before the first retry, which is then doubled (with jitter) for each subsequent
+	// retry - defaults to 1 second.
+	InitialDelay time.Duration
+	// MaxDelay caps the delay between two attempts - defaults to 30 seconds. A `Retry-After` header returned by the
+	// API is honoured instead, up to `MaxRetryAfter`.
+	MaxDelay time.Duration
+	// MaxRetryAfter is the longest `Retry-After` that will be waited for - defaults to 5 minutes. If the API asks for
+	// a longer delay, the request fails without being retried.
+	MaxRetryAfter time.Duration
+	// RetryPost allows POST requests, which may create resources, to be retried as well.
+	RetryPost bool
+}
+
+// TaskPolling changes how often tasks are polled while waiting for a create, update or delete to complete - will
+// default to an initial delay of 1 second, doubling up to a maximum of 30 seconds between polls with no timeout.
+func TaskPolling(policy TaskPollingPolicy) Option {
+	return func(options *Options) {
+		options.polling = &policy
+	}
+}
+
+// WithTaskPolling returns a context that overrides the client's task polling policy for any call made with it.
+// Only the non-zero values of `policy` are applied, with the rest coming from the client's policy.
+func WithTaskPolling(ctx context.Context, policy TaskPollingPolicy) context.Context {
+	return internal.ContextWithPollingPolicy(ctx, policy.pollingPolicy())
+}
+
+// TaskPollingPolicy configures the polling of tasks. Any zero values will be replaced with the defaults.
+type TaskPollingPolicy struct {
+	// InitialDelay is the delay between the first and second poll - defaults to 1 second.
+	InitialDelay time.Duration
+	// MaxDelay caps the delay between two polls - defaults to 30 seconds.
+	MaxDelay time.Duration
+	// Multiplier is applied to the delay after every poll - defaults to 2, with a value below 1 being treated as 1.
+	Multiplier float64
+	// Timeout is the total time to wait for a task before giving up - defaults to waiting until the context is
+	// cancelled.
+	Timeout time.Duration
+	// Max404Errors is the number of times the task can be reported as not found before giving up, which allows for
+	// the task service not yet knowing about a newly created task - defaults to 5. A negative value will give up on
+	// the first 404.
+	Max404Errors int
+}
+
+func (p TaskPollingPolicy) pollingPolicy() internal.PollingPolicy {
+	return internal.PollingPolicy{
+		InitialDelay: p.InitialDelay,
+		MaxDelay:     p.MaxDelay,
+		Multiplier:   p.Multiplier,
+		Timeout:      p.Timeout,
+		Max404Errors: p.Max404Errors,
+	}
+}
+
+// RetryWhenSubscriptionBusy enables retrying the changes to a subscription, or its databases, that are rejected because
+// the subscription is still busy with a previous change. The subscription is polled, as configured by `TaskPolling`,
+// until it is active again and then the change is retried, giving up after `timeout` in total - will default to
+// disabled.
+func RetryWhenSubscriptionBusy(timeout time.Duration) Option {
+	return func(options *Options) {
+		options.busyTimeout = timeout
+	}
+}
+
+// SerializeSubscriptionChanges makes the client only allow one change at a time, including waiting for the change to
+// complete, to each subscription and its databases. A change made with one of the `...Async` calls doesn't wait for its
+// task, but the next change to the subscription does before it's made. Changes to different subscriptions are still
+// made concurrently - will default to disabled.
+func SerializeSubscriptionChanges(enable bool) Option {
+	return func(options *Options) {
+		options.serialize = enable
+	}
+}
+
+// ObserveTasks registers an observer that will be notified every time a task, that the client is waiting on, changes
+// status. This can be used to report the progress of long-running operations.
+func ObserveTasks(observer TaskObserver) Option {
+	return func(options *Options) {
+		options.observers = append(options.observers, observer)
+	}
+}
+
+// WithTaskObserver returns a context that will additionally notify `observer` of the status changes of any task
+// waited on by a call made with it, along with any observers already added to the context.
+func WithTaskObserver(ctx context.Context, observer TaskObserver) context.Context {
+	return internal.ContextWithTaskObserver(ctx, taskObserver(observer))
+}
+
+// TaskObserver is notified of the status changes of the tasks that are being waited on.
+type TaskObserver interface {
+	TaskStatusChanged(change TaskStatusChange)
+}
+
+// TaskObserverFunc allows a function to be used as a TaskObserver.
+type TaskObserverFunc func(change TaskStatusChange)
+
+func (f TaskObserverFunc) TaskStatusChanged(change TaskStatusChange) {
+	f(change)
+}
+
+// TaskStatusChange describes a task being seen with a different status to the previous poll, e.g. moving from
+// `received` to `processing-in-progress`.
+type TaskStatusChange struct {
+	TaskID      string
+	CommandType string
+	// PreviousStatus is empty the first time the task is seen.
+	PreviousStatus string
+	Status         string
+	Description    string
+	// Timestamp is the time the task reported for the change, or the time it was seen if the task had none.
+	Timestamp time.Time
+}
+
+func taskObserver(observer TaskObserver) internal.TaskObserver {
+	return func(change internal.TaskStatusChange) {
+		observer.TaskStatusChanged(TaskStatusChange{
+			TaskID:         change.TaskID,
+			CommandType:    change.CommandType,
+			PreviousStatus: change.PreviousStatus,
+			Status:         change.Status,
+			Description:    change.Description,
+			Timestamp:      change.Timestamp,
+		})
+	}
+}
+
+// Log is the unstructured logger accepted by `Logger`. Only `Printf` is used, with `Println` being kept so that
+// existing implementations still satisfy it.
 type Log interface {
 	Printf(format string, v ...interface{})
 	Println(v ...interface{})
@@ -150,8 +452,9 @@ type credentialTripper struct {
 	secretKey   string
 	wrapped     http.RoundTripper
 	logRequests bool
-	logger      Log
+	logger      logging.Logger
 	userAgent   string
+	redactor    *logRedactor
 }
 
 func (c *credentialTripper) RoundTrip(request *http.Request) (*http.Response, error) {
@@ -159,11 +462,12 @@ func (c *credentialTripper) RoundTrip(request *http.Request) (*http.Response, er
 	request.Header.Set("User-Agent", c.userAgent)
 
 	if c.logRequests {
-		data, _ := httputil.DumpRequestOut(request, true)
+		data, _ := httputil.DumpRequestOut(request, false)
 		if data != nil {
-			c.logger.Printf(`DEBUG: Request %s:
+			data = append(data, c.redact(readBody(&request.Body))...)
+			c.logger.Log(request.Context(), logging.LevelDebug, fmt.Sprintf(`Request %s:
 ---[ REQUEST ]---
-%s`, request.URL.Path, prettyPrint(data))
+%s`, request.URL.Path, prettyPrint(data)))
 		}
 	}
 
@@ -177,16 +481,67 @@ func (c *credentialTripper) RoundTrip(request *http.Request) (*http.Response, er
 	}
 
 	if c.logRequests {
-		data, _ := httputil.DumpResponse(response, true)
+		data := c.dumpResponse(response)
 		if data != nil {
-			c.logger.Printf(`DEBUG: Response %s:
+			c.logger.Log(request.Context(), logging.LevelDebug, fmt.Sprintf(`Response %s:
 ---[ RESPONSE ]---
-%s`, request.URL.Path, prettyPrint(data))
+%s`, request.URL.Path, prettyPrint(data)))
 		}
 	}
 	return response, nil
 }
 
+// dumpResponse dumps the response with its body redacted by the credentialTripper. The body is read first and dumped as
+// it is, as the dump of a chunked body would be its chunks rather than the JSON that was sent.
+func (c *credentialTripper) dumpResponse(response *http.Response) []byte {
+	body := readBody(&response.Body)
+
+	dechunked := *response
+	dechunked.TransferEncoding = nil
+	dechunked.Body = ioutil.NopCloser(bytes.NewReader(body))
+	data, err := httputil.DumpResponse(&dechunked, true)
+	if err != nil || !bytes.HasSuffix(data, body) {
+		return nil
+	}
+
+	return append(data[:len(data)-len(body)], c.redact(body)...)
+}
+
+// readBody reads the whole of a request or response body, replacing it with one that will return the same data - and
+// any error reading it - so that it can still be sent or read.
+func readBody(body *io.ReadCloser) []byte {
+	if *body == nil || *body == http.NoBody {
+		return nil
+	}
+
+	data, err := ioutil.ReadAll(*body)
+	_ = (*body).Close()
+	var reader io.Reader = bytes.NewReader(data)
+	if err != nil {
+		reader = io.MultiReader(reader, &errorReader{err: err})
+	}
+	*body = ioutil.NopCloser(reader)
+	return data
+}
+
+type errorReader struct {
+	err error
+}
+
+func (r *errorReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+// redact removes any secrets from the body of a request or response, using the default redaction if none was
+// configured.
+func (c *credentialTripper) redact(data []byte) []byte {
+	redactor := c.redactor
+	if redactor == nil {
+		redactor = LogRedactionPolicy{}.redactor()
+	}
+	return redactor.redact(data)
+}
+
 func prettyPrint(data []byte) string {
 	lines := strings.Split(string(data), "\n")
 	// A JSON body that wasn't indented would have ended up as a single line in the dumped information,
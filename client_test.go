@@ -9,6 +9,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/RedisLabs/rediscloud-go-api/logging"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -44,7 +45,7 @@ func TestCredentialTripper_DisabledLoggingLogsNothing(t *testing.T) {
 		secretKey:   "SECRET KEY THAT SHOULD NOT BE LOGGED",
 		wrapped:     mockTripper,
 		logRequests: false,
-		logger:      mockLogger,
+		logger:      logging.FromPrintf(mockLogger),
 		userAgent:   "test-user-agent",
 	}
 
@@ -91,7 +92,7 @@ func TestCredentialTripper_LogsRequestAndResponseBodies(t *testing.T) {
 		secretKey:   "SECRET KEY THAT SHOULD NOT BE LOGGED",
 		wrapped:     mockTripper,
 		logRequests: true,
-		logger:      mockLogger,
+		logger:      logging.FromPrintf(mockLogger),
 		userAgent:   "test-user-agent",
 	}
 
@@ -157,7 +158,7 @@ func TestCredentialTripper_HandlesNoBodies(t *testing.T) {
 		secretKey:   "SECRET KEY THAT SHOULD NOT BE LOGGED",
 		wrapped:     mockTripper,
 		logRequests: true,
-		logger:      mockLogger,
+		logger:      logging.FromPrintf(mockLogger),
 		userAgent:   "test-user-agent",
 	}
 
@@ -2,10 +2,12 @@ package rediscloud_api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/RedisLabs/rediscloud-go-api/apierrors"
 	"github.com/RedisLabs/rediscloud-go-api/redis"
 	"github.com/RedisLabs/rediscloud-go-api/service/cloud_accounts"
 	"github.com/stretchr/testify/assert"
@@ -156,7 +158,10 @@ func TestCloudAccount_Get_wraps404(t *testing.T) {
 	actual, err := subject.CloudAccount.Get(context.TODO(), 98765)
 
 	assert.Nil(t, actual)
-	assert.IsType(t, &cloud_accounts.NotFound{}, err)
+	var notFound *cloud_accounts.NotFound
+	require.True(t, errors.As(err, &notFound))
+	assert.Equal(t, 98765, notFound.ID)
+	assert.True(t, errors.Is(err, apierrors.ErrNotFound))
 }
 
 func TestCloudAccount_List(t *testing.T) {
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+)
+
+var accountCommands = map[string]command{
+	"regions":         {"List the regions that subscriptions can be created in", (*app).accountRegions},
+	"payment-methods": {"List the payment methods of the account", (*app).accountPaymentMethods},
+	"modules":         {"List the modules that databases can use", (*app).accountModules},
+	"persistence":     {"List the data persistence options that databases can use", (*app).accountPersistence},
+}
+
+func (a *app) accountRegions(ctx context.Context, name string, args []string) error {
+	fs, g := a.newFlags(name, false)
+	if err := parse(fs, g, args); err != nil {
+		return err
+	}
+
+	client, err := a.client(g)
+	if err != nil {
+		return err
+	}
+
+	list, err := client.Account.ListRegions(ctx)
+	if err != nil {
+		return err
+	}
+	return a.print(g, list, "name", "provider")
+}
+
+func (a *app) accountPaymentMethods(ctx context.Context, name string, args []string) error {
+	fs, g := a.newFlags(name, false)
+	if err := parse(fs, g, args); err != nil {
+		return err
+	}
+
+	client, err := a.client(g)
+	if err != nil {
+		return err
+	}
+
+	list, err := client.Account.ListPaymentMethods(ctx)
+	if err != nil {
+		return err
+	}
+	return a.print(g, list, "id", "type", "creditCardEndsWith", "expirationMonth", "expirationYear")
+}
+
+func (a *app) accountModules(ctx context.Context, name string, args []string) error {
+	fs, g := a.newFlags(name, false)
+	if err := parse(fs, g, args); err != nil {
+		return err
+	}
+
+	client, err := a.client(g)
+	if err != nil {
+		return err
+	}
+
+	list, err := client.Account.ListDatabaseModules(ctx)
+	if err != nil {
+		return err
+	}
+	return a.print(g, list, "name", "description")
+}
+
+func (a *app) accountPersistence(ctx context.Context, name string, args []string) error {
+	fs, g := a.newFlags(name, false)
+	if err := parse(fs, g, args); err != nil {
+		return err
+	}
+
+	client, err := a.client(g)
+	if err != nil {
+		return err
+	}
+
+	list, err := client.Account.ListDataPersistence(ctx)
+	if err != nil {
+		return err
+	}
+	return a.print(g, list, "name", "description")
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+
+	"github.com/RedisLabs/rediscloud-go-api/service/cloud_accounts"
+)
+
+var cloudAccountColumns = []string{"id", "name", "provider", "status", "accessKeyId"}
+
+var cloudAccountCommands = map[string]command{
+	"list":   {"List all cloud accounts", (*app).cloudAccountsList},
+	"get":    {"Get a cloud account", (*app).cloudAccountsGet},
+	"create": {"Create a cloud account from a JSON or YAML file", (*app).cloudAccountsCreate},
+	"update": {"Update a cloud account from a JSON or YAML file", (*app).cloudAccountsUpdate},
+	"delete": {"Delete a cloud account", (*app).cloudAccountsDelete},
+}
+
+func (a *app) cloudAccountsList(ctx context.Context, name string, args []string) error {
+	fs, g := a.newFlags(name, false)
+	if err := parse(fs, g, args); err != nil {
+		return err
+	}
+
+	client, err := a.client(g)
+	if err != nil {
+		return err
+	}
+
+	list, err := client.CloudAccount.List(ctx)
+	if err != nil {
+		return err
+	}
+	return a.print(g, list, cloudAccountColumns...)
+}
+
+func (a *app) cloudAccountsGet(ctx context.Context, name string, args []string) error {
+	fs, g := a.newFlags(name, false)
+	id := fs.Int("id", 0, "identifier of the cloud account")
+	if err := parse(fs, g, args, "id"); err != nil {
+		return err
+	}
+
+	client, err := a.client(g)
+	if err != nil {
+		return err
+	}
+
+	return a.printCloudAccount(ctx, g, client.CloudAccount, *id)
+}
+
+func (a *app) printCloudAccount(ctx context.Context, g *globalFlags, api *cloud_accounts.API, id int) error {
+	cloudAccount, err := api.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	return a.print(g, cloudAccount, cloudAccountColumns...)
+}
+
+func (a *app) cloudAccountsCreate(ctx context.Context, name string, args []string) error {
+	fs, g := a.newFlags(name, true)
+	file := fs.String("file", "", "file containing the cloud account to create, or - for stdin")
+	if err := parse(fs, g, args, "file"); err != nil {
+		return err
+	}
+
+	var create cloud_accounts.CreateCloudAccount
+	if err := a.readRequest(*file, &create); err != nil {
+		return err
+	}
+
+	client, err := a.client(g)
+	if err != nil {
+		return err
+	}
+
+	task, err := client.CloudAccount.CreateAsync(ctx, create)
+	if err != nil {
+		return err
+	}
+	return a.completeWithID(ctx, g, client, task, func(id int) error {
+		return a.printCloudAccount(ctx, g, client.CloudAccount, id)
+	})
+}
+
+func (a *app) cloudAccountsUpdate(ctx context.Context, name string, args []string) error {
+	fs, g := a.newFlags(name, true)
+	id := fs.Int("id", 0, "identifier of the cloud account")
+	file := fs.String("file", "", "file containing the changes to the cloud account, or - for stdin")
+	if err := parse(fs, g, args, "id", "file"); err != nil {
+		return err
+	}
+
+	var update cloud_accounts.UpdateCloudAccount
+	if err := a.readRequest(*file, &update); err != nil {
+		return err
+	}
+
+	client, err := a.client(g)
+	if err != nil {
+		return err
+	}
+
+	task, err := client.CloudAccount.UpdateAsync(ctx, *id, update)
+	if err != nil {
+		return err
+	}
+	return a.complete(ctx, g, client, task, func() error {
+		return a.printCloudAccount(ctx, g, client.CloudAccount, *id)
+	})
+}
+
+func (a *app) cloudAccountsDelete(ctx context.Context, name string, args []string) error {
+	fs, g := a.newFlags(name, true)
+	id := fs.Int("id", 0, "identifier of the cloud account")
+	if err := parse(fs, g, args, "id"); err != nil {
+		return err
+	}
+
+	client, err := a.client(g)
+	if err != nil {
+		return err
+	}
+
+	task, err := client.CloudAccount.DeleteAsync(ctx, *id)
+	if err != nil {
+		return err
+	}
+	return a.complete(ctx, g, client, task, nil)
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+
+	"github.com/RedisLabs/rediscloud-go-api/service/databases"
+)
+
+var databaseColumns = []string{"databaseId", "name", "status", "protocol", "memoryLimitInGb", "publicEndpoint"}
+
+var databaseCommands = map[string]command{
+	"list":   {"List the databases in a subscription", (*app).databasesList},
+	"get":    {"Get a database", (*app).databasesGet},
+	"create": {"Create a database from a JSON or YAML file", (*app).databasesCreate},
+	"update": {"Update a database from a JSON or YAML file", (*app).databasesUpdate},
+	"delete": {"Delete a database", (*app).databasesDelete},
+	"backup": {"Back up a database", (*app).databasesBackup},
+	"import": {"Import data into a database", (*app).databasesImport},
+}
+
+func (a *app) databasesList(ctx context.Context, name string, args []string) error {
+	fs, g := a.newFlags(name, false)
+	subscription := fs.Int("subscription", 0, "identifier of the subscription")
+	if err := parse(fs, g, args, "subscription"); err != nil {
+		return err
+	}
+
+	client, err := a.client(g)
+	if err != nil {
+		return err
+	}
+
+	list := []*databases.Database{}
+	iterator := client.Database.List(ctx, *subscription)
+	for iterator.Next() {
+		list = append(list, iterator.Value())
+	}
+	if err := iterator.Err(); err != nil {
+		return err
+	}
+	return a.print(g, list, databaseColumns...)
+}
+
+func (a *app) databasesGet(ctx context.Context, name string, args []string) error {
+	fs, g := a.newFlags(name, false)
+	subscription := fs.Int("subscription", 0, "identifier of the subscription")
+	id := fs.Int("id", 0, "identifier of the database")
+	if err := parse(fs, g, args, "subscription", "id"); err != nil {
+		return err
+	}
+
+	client, err := a.client(g)
+	if err != nil {
+		return err
+	}
+
+	return a.printDatabase(ctx, g, client.Database, *subscription, *id)
+}
+
+func (a *app) printDatabase(ctx context.Context, g *globalFlags, api *databases.API, subscription int, id int) error {
+	db, err := api.Get(ctx, subscription, id)
+	if err != nil {
+		return err
+	}
+	return a.print(g, db, databaseColumns...)
+}
+
+func (a *app) databasesCreate(ctx context.Context, name string, args []string) error {
+	fs, g := a.newFlags(name, true)
+	subscription := fs.Int("subscription", 0, "identifier of the subscription")
+	file := fs.String("file", "", "file containing the database to create, or - for stdin")
+	if err := parse(fs, g, args, "subscription", "file"); err != nil {
+		return err
+	}
+
+	var create databases.CreateDatabase
+	if err := a.readRequest(*file, &create); err != nil {
+		return err
+	}
+
+	client, err := a.client(g)
+	if err != nil {
+		return err
+	}
+
+	task, err := client.Database.CreateAsync(ctx, *subscription, create)
+	if err != nil {
+		return err
+	}
+	return a.completeWithID(ctx, g, client, task, func(id int) error {
+		return a.printDatabase(ctx, g, client.Database, *subscription, id)
+	})
+}
+
+func (a *app) databasesUpdate(ctx context.Context, name string, args []string) error {
+	fs, g := a.newFlags(name, true)
+	subscription := fs.Int("subscription", 0, "identifier of the subscription")
+	id := fs.Int("id", 0, "identifier of the database")
+	file := fs.String("file", "", "file containing the changes to the database, or - for stdin")
+	if err := parse(fs, g, args, "subscription", "id", "file"); err != nil {
+		return err
+	}
+
+	var update databases.UpdateDatabase
+	if err := a.readRequest(*file, &update); err != nil {
+		return err
+	}
+
+	client, err := a.client(g)
+	if err != nil {
+		return err
+	}
+
+	task, err := client.Database.UpdateAsync(ctx, *subscription, *id, update)
+	if err != nil {
+		return err
+	}
+	return a.complete(ctx, g, client, task, func() error {
+		return a.printDatabase(ctx, g, client.Database, *subscription, *id)
+	})
+}
+
+func (a *app) databasesDelete(ctx context.Context, name string, args []string) error {
+	fs, g := a.newFlags(name, true)
+	subscription := fs.Int("subscription", 0, "identifier of the subscription")
+	id := fs.Int("id", 0, "identifier of the database")
+	if err := parse(fs, g, args, "subscription", "id"); err != nil {
+		return err
+	}
+
+	client, err := a.client(g)
+	if err != nil {
+		return err
+	}
+
+	task, err := client.Database.DeleteAsync(ctx, *subscription, *id)
+	if err != nil {
+		return err
+	}
+	return a.complete(ctx, g, client, task, nil)
+}
+
+func (a *app) databasesBackup(ctx context.Context, name string, args []string) error {
+	fs, g := a.newFlags(name, true)
+	subscription := fs.Int("subscription", 0, "identifier of the subscription")
+	id := fs.Int("id", 0, "identifier of the database")
+	if err := parse(fs, g, args, "subscription", "id"); err != nil {
+		return err
+	}
+
+	client, err := a.client(g)
+	if err != nil {
+		return err
+	}
+
+	task, err := client.Database.BackupAsync(ctx, *subscription, *id)
+	if err != nil {
+		return err
+	}
+	return a.complete(ctx, g, client, task, nil)
+}
+
+func (a *app) databasesImport(ctx context.Context, name string, args []string) error {
+	fs, g := a.newFlags(name, true)
+	subscription := fs.Int("subscription", 0, "identifier of the subscription")
+	id := fs.Int("id", 0, "identifier of the database")
+	sourceType := fs.String("source-type", "", "type of storage the data is imported from, such as http or aws-s3")
+	var uris stringList
+	fs.Var(&uris, "uri", "URI of the data to import - can be repeated")
+	if err := parse(fs, g, args, "subscription", "id", "source-type", "uri"); err != nil {
+		return err
+	}
+
+	client, err := a.client(g)
+	if err != nil {
+		return err
+	}
+
+	task, err := client.Database.ImportAsync(ctx, *subscription, *id, databases.Import{
+		SourceType:    sourceType,
+		ImportFromURI: uris.pointers(),
+	})
+	if err != nil {
+		return err
+	}
+	return a.complete(ctx, g, client, task, nil)
+}
@@ -0,0 +1,249 @@
+// Command rediscloud manages Redis Cloud resources from the command line, using the same services as the SDK:
+//
+//	rediscloud <resource> <command> [flags]
+//
+// Credentials are read from the REDISCLOUD_ACCESS_KEY and REDISCLOUD_SECRET_KEY environment variables. Commands that
+// start a task will wait for the task to complete unless `--no-wait` is given, in which case the task is printed
+// instead.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	rediscloud_api "github.com/RedisLabs/rediscloud-go-api"
+)
+
+// URLEnvVar is the environment variable that will be used for the API's URL by default.
+const URLEnvVar = "REDISCLOUD_URL"
+
+func main() {
+	a := &app{
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+		getenv: os.Getenv,
+	}
+	os.Exit(a.run(context.Background(), os.Args[1:]))
+}
+
+type app struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(string) string
+}
+
+type command struct {
+	description string
+	run         func(a *app, ctx context.Context, name string, args []string) error
+}
+
+var resources = map[string]map[string]command{
+	"subscriptions":  subscriptionCommands,
+	"databases":      databaseCommands,
+	"cloud-accounts": cloudAccountCommands,
+	"peering":        peeringCommands,
+	"cidr":           cidrCommands,
+	"account":        accountCommands,
+	"tasks":          taskCommands,
+}
+
+func (a *app) run(ctx context.Context, args []string) int {
+	if len(args) < 2 {
+		a.usage(args)
+		return 2
+	}
+
+	commands, ok := resources[args[0]]
+	if !ok {
+		a.usage(nil)
+		return 2
+	}
+	cmd, ok := commands[args[1]]
+	if !ok {
+		a.usage(args[:1])
+		return 2
+	}
+
+	if err := cmd.run(a, ctx, args[0]+" "+args[1], args[2:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 2
+		}
+		_, _ = fmt.Fprintf(a.stderr, "Error: %s\n", err)
+		return 1
+	}
+	return 0
+}
+
+func (a *app) usage(args []string) {
+	if len(args) > 0 {
+		if commands, ok := resources[args[0]]; ok {
+			_, _ = fmt.Fprintf(a.stderr, "Usage: rediscloud %s <command> [flags]\n\nCommands:\n", args[0])
+			for _, name := range sortedKeys(commands) {
+				_, _ = fmt.Fprintf(a.stderr, "  %-16s %s\n", name, commands[name].description)
+			}
+			return
+		}
+	}
+
+	_, _ = fmt.Fprintf(a.stderr, "Usage: rediscloud <resource> <command> [flags]\n\nResources:\n")
+	for _, name := range sortedKeys(resources) {
+		_, _ = fmt.Fprintf(a.stderr, "  %s\n", name)
+	}
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch v := m.(type) {
+	case map[string]map[string]command:
+		for key := range v {
+			keys = append(keys, key)
+		}
+	case map[string]command:
+		for key := range v {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// globalFlags are the flags accepted by every command.
+type globalFlags struct {
+	output      string
+	url         string
+	logRequests bool
+	wait        bool
+	noWait      bool
+}
+
+// newFlags creates the flags for a command, including the global flags, with `taskBacked` adding the flags controlling
+// whether to wait for the task.
+func (a *app) newFlags(name string, taskBacked bool) (*flag.FlagSet, *globalFlags) {
+	g := &globalFlags{}
+	fs := flag.NewFlagSet("rediscloud "+name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.StringVar(&g.output, "output", "table", "output format: json, yaml or table")
+	fs.StringVar(&g.url, "url", "", "URL of the API (defaults to $"+URLEnvVar+" or the public API)")
+	fs.BoolVar(&g.logRequests, "log-requests", false, "log the HTTP requests and responses")
+	if taskBacked {
+		fs.BoolVar(&g.wait, "wait", true, "wait for the task to complete")
+		fs.BoolVar(&g.noWait, "no-wait", false, "print the task without waiting for it to complete")
+	}
+	return fs, g
+}
+
+// parse parses the flags, checking that the output format is valid and that all of the `required` flags were set.
+func parse(fs *flag.FlagSet, g *globalFlags, args []string, required ...string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	if _, ok := formats[g.output]; !ok {
+		return fmt.Errorf("unknown output format %q", g.output)
+	}
+
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	for _, name := range required {
+		if !set[name] {
+			return fmt.Errorf("the --%s flag is required", name)
+		}
+	}
+	return nil
+}
+
+func (g *globalFlags) shouldWait() bool {
+	return g.wait && !g.noWait
+}
+
+func (a *app) client(g *globalFlags) (*rediscloud_api.Client, error) {
+	options := []rediscloud_api.Option{
+		rediscloud_api.AdditionalUserAgent("rediscloud-cli"),
+		rediscloud_api.LogRequests(g.logRequests),
+		rediscloud_api.Auth(a.getenv(rediscloud_api.AccessKeyEnvVar), a.getenv(rediscloud_api.SecretKeyEnvVar)),
+	}
+
+	url := g.url
+	if url == "" {
+		url = a.getenv(URLEnvVar)
+	}
+	if url != "" {
+		options = append(options, rediscloud_api.BaseURL(url))
+	}
+
+	return rediscloud_api.NewClient(options...)
+}
+
+// stringList is a flag that can be repeated, or given a comma separated list, to build up a list of values.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
+
+func (l stringList) pointers() []*string {
+	ret := []*string{}
+	for i := range l {
+		ret = append(ret, &l[i])
+	}
+	return ret
+}
+
+// optionalString is a flag which records whether it was set, so that only the fields given are sent in an update.
+type optionalString struct {
+	value *string
+}
+
+func (o *optionalString) String() string {
+	if o.value == nil {
+		return ""
+	}
+	return *o.value
+}
+
+func (o *optionalString) Set(value string) error {
+	o.value = &value
+	return nil
+}
+
+type optionalInt struct {
+	value *int
+}
+
+func (o *optionalInt) String() string {
+	if o.value == nil {
+		return ""
+	}
+	return strconv.Itoa(*o.value)
+}
+
+func (o *optionalInt) Set(value string) error {
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+	o.value = &i
+	return nil
+}
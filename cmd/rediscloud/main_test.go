@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	rediscloud_api "github.com/RedisLabs/rediscloud-go-api"
+	"github.com/RedisLabs/rediscloud-go-api/redis"
+	"github.com/RedisLabs/rediscloud-go-api/rediscloudtest"
+	"github.com/RedisLabs/rediscloud-go-api/service/databases"
+	"github.com/RedisLabs/rediscloud-go-api/service/subscriptions"
+	"github.com/RedisLabs/rediscloud-go-api/service/tasks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type result struct {
+	code   int
+	stdout string
+	stderr string
+}
+
+func runCLI(t *testing.T, fake *rediscloudtest.Server, stdin string, args ...string) result {
+	var stdout, stderr bytes.Buffer
+	a := &app{
+		stdin:  strings.NewReader(stdin),
+		stdout: &stdout,
+		stderr: &stderr,
+		getenv: func(key string) string {
+			return map[string]string{
+				URLEnvVar:                      fake.URL,
+				rediscloud_api.AccessKeyEnvVar: "key",
+				rediscloud_api.SecretKeyEnvVar: "secret",
+			}[key]
+		},
+	}
+	code := a.run(context.Background(), args)
+	return result{code: code, stdout: stdout.String(), stderr: stderr.String()}
+}
+
+func newFake(t *testing.T) *rediscloudtest.Server {
+	// Tasks complete on their first poll, as the CLI uses the default polling delays
+	fake := rediscloudtest.NewServer(rediscloudtest.Credentials("key", "secret"), rediscloudtest.TaskPolls(0))
+	t.Cleanup(fake.Close)
+	return fake
+}
+
+func TestCLI_SubscriptionsTable(t *testing.T) {
+	fake := newFake(t)
+	fake.AddSubscription(subscriptions.Subscription{Name: redis.String("first"), PaymentMethodID: redis.Int(1)})
+	fake.AddSubscription(subscriptions.Subscription{Name: redis.String("second"), PaymentMethodID: redis.Int(1)})
+
+	r := runCLI(t, fake, "", "subscriptions", "list")
+	require.Equal(t, 0, r.code, r.stderr)
+
+	lines := strings.Split(strings.TrimSpace(r.stdout), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, []string{"ID", "NAME", "STATUS", "PAYMENT", "METHOD", "ID", "MEMORY", "STORAGE", "NUMBER", "OF", "DATABASES"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"1", "first", "active", "1", "0"}, strings.Fields(lines[1]))
+	assert.Equal(t, []string{"2", "second", "active", "1", "0"}, strings.Fields(lines[2]))
+}
+
+func TestCLI_DatabaseLifecycle(t *testing.T) {
+	fake := newFake(t)
+	subID := fake.AddSubscription(subscriptions.Subscription{Name: redis.String("example")})
+	sub := strconv.Itoa(subID)
+
+	r := runCLI(t, fake, "name: example\nmemoryLimitInGb: 1\n", "databases", "create", "--subscription", sub, "--file", "-", "--output", "json")
+	require.Equal(t, 0, r.code, r.stderr)
+
+	var created databases.Database
+	require.NoError(t, json.Unmarshal([]byte(r.stdout), &created))
+	assert.Equal(t, "example", redis.StringValue(created.Name))
+	assert.Equal(t, 1.0, redis.Float64Value(created.MemoryLimitInGB))
+	id := strconv.Itoa(redis.IntValue(created.ID))
+
+	update := filepath.Join(t.TempDir(), "update.json")
+	require.NoError(t, ioutil.WriteFile(update, []byte(`{"name": "renamed"}`), 0644))
+	r = runCLI(t, fake, "", "databases", "update", "--subscription", sub, "--id", id, "--file", update, "--output", "yaml")
+	require.Equal(t, 0, r.code, r.stderr)
+	assert.Contains(t, r.stdout, "name: renamed\n")
+	assert.Contains(t, r.stdout, "databaseId: "+id+"\n")
+
+	r = runCLI(t, fake, "", "databases", "backup", "--subscription", sub, "--id", id)
+	require.Equal(t, 0, r.code, r.stderr)
+	assert.Empty(t, r.stdout)
+
+	r = runCLI(t, fake, "", "databases", "list", "--subscription", sub, "--output", "json")
+	require.Equal(t, 0, r.code, r.stderr)
+	var list []*databases.Database
+	require.NoError(t, json.Unmarshal([]byte(r.stdout), &list))
+	require.Len(t, list, 1)
+
+	r = runCLI(t, fake, "", "databases", "delete", "--subscription", sub, "--id", id)
+	require.Equal(t, 0, r.code, r.stderr)
+	assert.Nil(t, fake.Database(subID, redis.IntValue(created.ID)))
+}
+
+func TestCLI_NoWaitPrintsTheTask(t *testing.T) {
+	fake := newFake(t)
+	subID := fake.AddSubscription(subscriptions.Subscription{Name: redis.String("example")})
+
+	r := runCLI(t, fake, "", "subscriptions", "update", "--id", strconv.Itoa(subID), "--name", "renamed", "--no-wait", "--output", "json")
+	require.Equal(t, 0, r.code, r.stderr)
+
+	var task tasks.Task
+	require.NoError(t, json.Unmarshal([]byte(r.stdout), &task))
+	assert.Equal(t, tasks.StatusReceived, redis.StringValue(task.Status))
+	assert.Equal(t, "example", redis.StringValue(fake.Subscription(subID).Name))
+
+	r = runCLI(t, fake, "", "tasks", "wait", "--id", redis.StringValue(task.ID))
+	require.Equal(t, 0, r.code, r.stderr)
+	assert.Contains(t, r.stdout, tasks.StatusProcessingCompleted)
+	assert.Equal(t, "renamed", redis.StringValue(fake.Subscription(subID).Name))
+}
+
+func TestCLI_CIDRAllowlist(t *testing.T) {
+	fake := newFake(t)
+	sub := strconv.Itoa(fake.AddSubscription(subscriptions.Subscription{Name: redis.String("example")}))
+
+	r := runCLI(t, fake, "", "cidr", "update", "--subscription", sub, "--cidr", "10.0.0.0/24", "--cidr", "10.1.0.0/24", "--output", "json")
+	require.Equal(t, 0, r.code, r.stderr)
+
+	var cidr subscriptions.CIDRAllowlist
+	require.NoError(t, json.Unmarshal([]byte(r.stdout), &cidr))
+	assert.Equal(t, []*string{redis.String("10.0.0.0/24"), redis.String("10.1.0.0/24")}, cidr.CIDRIPs)
+}
+
+func TestCLI_Account(t *testing.T) {
+	fake := newFake(t)
+
+	r := runCLI(t, fake, "", "account", "regions", "--output", "yaml")
+	require.Equal(t, 0, r.code, r.stderr)
+	assert.Contains(t, r.stdout, "- name: us-east-1\n  provider: AWS\n")
+}
+
+func TestCLI_Errors(t *testing.T) {
+	fake := newFake(t)
+
+	r := runCLI(t, fake, "", "subscriptions", "get", "--id", "100")
+	assert.Equal(t, 1, r.code)
+	assert.Contains(t, r.stderr, "subscription 100 not found")
+
+	r = runCLI(t, fake, "", "subscriptions", "get")
+	assert.Equal(t, 1, r.code)
+	assert.Contains(t, r.stderr, "the --id flag is required")
+
+	r = runCLI(t, fake, "", "subscriptions", "list", "--output", "xml")
+	assert.Equal(t, 1, r.code)
+	assert.Contains(t, r.stderr, `unknown output format "xml"`)
+
+	r = runCLI(t, fake, "", "subscriptions", "rename")
+	assert.Equal(t, 2, r.code)
+	assert.Contains(t, r.stderr, "Usage: rediscloud subscriptions <command> [flags]")
+
+	r = runCLI(t, fake, "", "databases", "create", "--subscription", "1", "--file", "-")
+	assert.Equal(t, 1, r.code)
+	assert.Contains(t, r.stderr, "failed to parse request")
+}
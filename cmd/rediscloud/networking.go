@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/RedisLabs/rediscloud-go-api/redis"
+	"github.com/RedisLabs/rediscloud-go-api/service/subscriptions"
+)
+
+var peeringColumns = []string{"vpcPeeringId", "status", "awsAccountId", "vpcUid", "vpcCidr", "projectUid", "networkName"}
+
+var cidrColumns = []string{"cidr_ips", "security_group_ids"}
+
+var peeringCommands = map[string]command{
+	"list":   {"List the VPC peerings of a subscription", (*app).peeringList},
+	"create": {"Create a VPC peering from a JSON or YAML file", (*app).peeringCreate},
+	"delete": {"Delete a VPC peering", (*app).peeringDelete},
+}
+
+var cidrCommands = map[string]command{
+	"get":    {"Get the CIDR allowlist of a subscription", (*app).cidrGet},
+	"update": {"Replace the CIDR allowlist of a subscription", (*app).cidrUpdate},
+}
+
+func (a *app) peeringList(ctx context.Context, name string, args []string) error {
+	fs, g := a.newFlags(name, false)
+	subscription := fs.Int("subscription", 0, "identifier of the subscription")
+	if err := parse(fs, g, args, "subscription"); err != nil {
+		return err
+	}
+
+	client, err := a.client(g)
+	if err != nil {
+		return err
+	}
+
+	list, err := client.Subscription.ListVPCPeering(ctx, *subscription)
+	if err != nil {
+		return err
+	}
+	return a.print(g, list, peeringColumns...)
+}
+
+func (a *app) peeringCreate(ctx context.Context, name string, args []string) error {
+	fs, g := a.newFlags(name, true)
+	subscription := fs.Int("subscription", 0, "identifier of the subscription")
+	file := fs.String("file", "", "file containing the VPC peering to create, or - for stdin")
+	if err := parse(fs, g, args, "subscription", "file"); err != nil {
+		return err
+	}
+
+	var create subscriptions.CreateVPCPeering
+	if err := a.readRequest(*file, &create); err != nil {
+		return err
+	}
+
+	client, err := a.client(g)
+	if err != nil {
+		return err
+	}
+
+	task, err := client.Subscription.CreateVPCPeeringAsync(ctx, *subscription, create)
+	if err != nil {
+		return err
+	}
+	return a.completeWithID(ctx, g, client, task, func(id int) error {
+		list, err := client.Subscription.ListVPCPeering(ctx, *subscription)
+		if err != nil {
+			return err
+		}
+		for _, peering := range list {
+			if redis.IntValue(peering.ID) == id {
+				return a.print(g, peering, peeringColumns...)
+			}
+		}
+		return fmt.Errorf("VPC peering %d was created but could not be found in subscription %d", id, *subscription)
+	})
+}
+
+func (a *app) peeringDelete(ctx context.Context, name string, args []string) error {
+	fs, g := a.newFlags(name, true)
+	subscription := fs.Int("subscription", 0, "identifier of the subscription")
+	id := fs.Int("id", 0, "identifier of the VPC peering")
+	if err := parse(fs, g, args, "subscription", "id"); err != nil {
+		return err
+	}
+
+	client, err := a.client(g)
+	if err != nil {
+		return err
+	}
+
+	task, err := client.Subscription.DeleteVPCPeeringAsync(ctx, *subscription, *id)
+	if err != nil {
+		return err
+	}
+	return a.complete(ctx, g, client, task, nil)
+}
+
+func (a *app) cidrGet(ctx context.Context, name string, args []string) error {
+	fs, g := a.newFlags(name, false)
+	subscription := fs.Int("subscription", 0, "identifier of the subscription")
+	if err := parse(fs, g, args, "subscription"); err != nil {
+		return err
+	}
+
+	client, err := a.client(g)
+	if err != nil {
+		return err
+	}
+
+	cidr, err := client.Subscription.GetCIDRAllowlist(ctx, *subscription)
+	if err != nil {
+		return err
+	}
+	return a.print(g, cidr, cidrColumns...)
+}
+
+func (a *app) cidrUpdate(ctx context.Context, name string, args []string) error {
+	fs, g := a.newFlags(name, true)
+	subscription := fs.Int("subscription", 0, "identifier of the subscription")
+	var cidrs, securityGroups stringList
+	fs.Var(&cidrs, "cidr", "CIDR block to allow - can be repeated")
+	fs.Var(&securityGroups, "security-group", "identifier of a security group to allow - can be repeated")
+	if err := parse(fs, g, args, "subscription"); err != nil {
+		return err
+	}
+
+	client, err := a.client(g)
+	if err != nil {
+		return err
+	}
+
+	task, err := client.Subscription.UpdateCIDRAllowlistAsync(ctx, *subscription, subscriptions.UpdateCIDRAllowlist{
+		CIDRIPs:          cidrs.pointers(),
+		SecurityGroupIDs: securityGroups.pointers(),
+	})
+	if err != nil {
+		return err
+	}
+	return a.complete(ctx, g, client, task, func() error {
+		cidr, err := client.Subscription.GetCIDRAllowlist(ctx, *subscription)
+		if err != nil {
+			return err
+		}
+		return a.print(g, cidr, cidrColumns...)
+	})
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/RedisLabs/rediscloud-go-api/service/tasks"
+	"gopkg.in/yaml.v3"
+)
+
+var formats = map[string]func(w io.Writer, value interface{}, columns []string) error{
+	"json":  writeJSON,
+	"yaml":  writeYAML,
+	"table": writeTable,
+}
+
+// Columns shown in a table for a task.
+var taskColumns = []string{"taskId", "commandType", "status", "description"}
+
+// print writes the value in the chosen output format, with `columns` being the fields shown in a table - all of the
+// top level fields that aren't objects or lists are shown if no columns are given.
+func (a *app) print(g *globalFlags, value interface{}, columns ...string) error {
+	return formats[g.output](a.stdout, value, columns)
+}
+
+// printTask writes a task that wasn't waited on.
+func (a *app) printTask(g *globalFlags, task *tasks.Task) error {
+	return a.print(g, task, taskColumns...)
+}
+
+func writeJSON(w io.Writer, value interface{}, _ []string) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+func writeYAML(w io.Writer, value interface{}, _ []string) error {
+	generic, err := toGeneric(value)
+	if err != nil {
+		return err
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(generic); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+func writeTable(w io.Writer, value interface{}, columns []string) error {
+	generic, err := toGeneric(value)
+	if err != nil {
+		return err
+	}
+
+	var rows []map[string]interface{}
+	switch v := generic.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if row, ok := item.(map[string]interface{}); ok {
+				rows = append(rows, row)
+			}
+		}
+	case map[string]interface{}:
+		rows = append(rows, v)
+	case nil:
+		return nil
+	default:
+		_, err := fmt.Fprintln(w, cell(v))
+		return err
+	}
+
+	if len(columns) == 0 {
+		columns = scalarColumns(rows)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	var headers []string
+	for _, column := range columns {
+		headers = append(headers, header(column))
+	}
+	_, _ = fmt.Fprintln(tw, strings.Join(headers, "\t"))
+
+	for _, row := range rows {
+		var cells []string
+		for _, column := range columns {
+			cells = append(cells, cell(row[column]))
+		}
+		_, _ = fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+func scalarColumns(rows []map[string]interface{}) []string {
+	seen := map[string]bool{}
+	var columns []string
+	for _, row := range rows {
+		for key, value := range row {
+			switch value.(type) {
+			case map[string]interface{}, []interface{}:
+				continue
+			}
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// header turns a field name such as `paymentMethodId` into a column header such as `PAYMENT METHOD ID`.
+func header(column string) string {
+	var b strings.Builder
+	for i, r := range column {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			b.WriteRune(' ')
+		}
+		b.WriteRune(r)
+	}
+	return strings.ToUpper(b.String())
+}
+
+func cell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(v)
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// toGeneric converts the value into the maps and lists that it would be encoded as in JSON, so that the field names
+// used in every output format are the ones used by the API. Whole numbers are kept as integers, rather than as floats
+// which YAML would write in exponent form.
+func toGeneric(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return convertNumbers(generic), nil
+}
+
+func convertNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = convertNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = convertNumbers(item)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	}
+	return value
+}
+
+// readRequest reads the body of a request from a JSON or YAML file, using the same field names as the API. A path of
+// `-` reads from stdin.
+func (a *app) readRequest(path string, request interface{}) error {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = ioutil.ReadAll(a.stdin)
+	} else {
+		data, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read request: %w", err)
+	}
+
+	// YAML is a superset of JSON, so both can be parsed as YAML and then converted to JSON to use the JSON field names
+	var generic interface{}
+	if err := yaml.Unmarshal(data, &generic); err != nil {
+		return fmt.Errorf("failed to parse request in %s: %w", path, err)
+	}
+	if generic == nil {
+		return fmt.Errorf("failed to parse request in %s: it is empty", path)
+	}
+
+	data, err = json.Marshal(generic)
+	if err != nil {
+		return fmt.Errorf("failed to parse request in %s: %w", path, err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(request); err != nil {
+		return fmt.Errorf("failed to parse request in %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+
+	"github.com/RedisLabs/rediscloud-go-api/service/subscriptions"
+)
+
+var subscriptionColumns = []string{"id", "name", "status", "paymentMethodId", "memoryStorage", "numberOfDatabases"}
+
+var subscriptionCommands = map[string]command{
+	"list":   {"List all subscriptions", (*app).subscriptionsList},
+	"get":    {"Get a subscription", (*app).subscriptionsGet},
+	"create": {"Create a subscription from a JSON or YAML file", (*app).subscriptionsCreate},
+	"update": {"Update the name or payment method of a subscription", (*app).subscriptionsUpdate},
+	"delete": {"Delete a subscription", (*app).subscriptionsDelete},
+}
+
+func (a *app) subscriptionsList(ctx context.Context, name string, args []string) error {
+	fs, g := a.newFlags(name, false)
+	if err := parse(fs, g, args); err != nil {
+		return err
+	}
+
+	client, err := a.client(g)
+	if err != nil {
+		return err
+	}
+
+	list, err := client.Subscription.List(ctx)
+	if err != nil {
+		return err
+	}
+	return a.print(g, list, subscriptionColumns...)
+}
+
+func (a *app) subscriptionsGet(ctx context.Context, name string, args []string) error {
+	fs, g := a.newFlags(name, false)
+	id := fs.Int("id", 0, "identifier of the subscription")
+	if err := parse(fs, g, args, "id"); err != nil {
+		return err
+	}
+
+	client, err := a.client(g)
+	if err != nil {
+		return err
+	}
+
+	sub, err := client.Subscription.Get(ctx, *id)
+	if err != nil {
+		return err
+	}
+	return a.print(g, sub, subscriptionColumns...)
+}
+
+func (a *app) subscriptionsCreate(ctx context.Context, name string, args []string) error {
+	fs, g := a.newFlags(name, true)
+	file := fs.String("file", "", "file containing the subscription to create, or - for stdin")
+	if err := parse(fs, g, args, "file"); err != nil {
+		return err
+	}
+
+	var create subscriptions.CreateSubscription
+	if err := a.readRequest(*file, &create); err != nil {
+		return err
+	}
+
+	client, err := a.client(g)
+	if err != nil {
+		return err
+	}
+
+	task, err := client.Subscription.CreateAsync(ctx, create)
+	if err != nil {
+		return err
+	}
+	return a.completeWithID(ctx, g, client, task, func(id int) error {
+		sub, err := client.Subscription.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		return a.print(g, sub, subscriptionColumns...)
+	})
+}
+
+func (a *app) subscriptionsUpdate(ctx context.Context, name string, args []string) error {
+	fs, g := a.newFlags(name, true)
+	id := fs.Int("id", 0, "identifier of the subscription")
+	var subName optionalString
+	var paymentMethod optionalInt
+	fs.Var(&subName, "name", "new name of the subscription")
+	fs.Var(&paymentMethod, "payment-method-id", "identifier of the new payment method")
+	if err := parse(fs, g, args, "id"); err != nil {
+		return err
+	}
+
+	client, err := a.client(g)
+	if err != nil {
+		return err
+	}
+
+	task, err := client.Subscription.UpdateAsync(ctx, *id, subscriptions.UpdateSubscription{
+		Name:            subName.value,
+		PaymentMethodID: paymentMethod.value,
+	})
+	if err != nil {
+		return err
+	}
+	return a.complete(ctx, g, client, task, func() error {
+		sub, err := client.Subscription.Get(ctx, *id)
+		if err != nil {
+			return err
+		}
+		return a.print(g, sub, subscriptionColumns...)
+	})
+}
+
+func (a *app) subscriptionsDelete(ctx context.Context, name string, args []string) error {
+	fs, g := a.newFlags(name, true)
+	id := fs.Int("id", 0, "identifier of the subscription")
+	if err := parse(fs, g, args, "id"); err != nil {
+		return err
+	}
+
+	client, err := a.client(g)
+	if err != nil {
+		return err
+	}
+
+	task, err := client.Subscription.DeleteAsync(ctx, *id)
+	if err != nil {
+		return err
+	}
+	return a.complete(ctx, g, client, task, nil)
+}
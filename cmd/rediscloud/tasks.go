@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+
+	rediscloud_api "github.com/RedisLabs/rediscloud-go-api"
+	"github.com/RedisLabs/rediscloud-go-api/redis"
+	"github.com/RedisLabs/rediscloud-go-api/service/tasks"
+)
+
+var taskCommands = map[string]command{
+	"list": {"List the recent tasks", (*app).tasksList},
+	"get":  {"Get a task", (*app).tasksGet},
+	"wait": {"Wait for a task to complete", (*app).tasksWait},
+}
+
+// complete either prints the task, if it shouldn't be waited on, or waits for the task to complete and then calls
+// `then` - if it isn't nil - to print the result.
+func (a *app) complete(ctx context.Context, g *globalFlags, client *rediscloud_api.Client, task *tasks.Task, then func() error) error {
+	if !g.shouldWait() {
+		return a.printTask(g, task)
+	}
+
+	if err := client.Task.Wait(ctx, redis.StringValue(task.ID)); err != nil {
+		return err
+	}
+
+	if then == nil {
+		return nil
+	}
+	return then()
+}
+
+// completeWithID is the same as complete, but passes the identifier of the resource created by the task to `then`.
+func (a *app) completeWithID(ctx context.Context, g *globalFlags, client *rediscloud_api.Client, task *tasks.Task, then func(id int) error) error {
+	if !g.shouldWait() {
+		return a.printTask(g, task)
+	}
+
+	id, err := client.Task.WaitForResourceId(ctx, redis.StringValue(task.ID))
+	if err != nil {
+		return err
+	}
+	return then(id)
+}
+
+func (a *app) tasksList(ctx context.Context, name string, args []string) error {
+	fs, g := a.newFlags(name, false)
+	if err := parse(fs, g, args); err != nil {
+		return err
+	}
+
+	client, err := a.client(g)
+	if err != nil {
+		return err
+	}
+
+	list, err := client.Task.List(ctx)
+	if err != nil {
+		return err
+	}
+	return a.print(g, list, taskColumns...)
+}
+
+func (a *app) tasksGet(ctx context.Context, name string, args []string) error {
+	fs, g := a.newFlags(name, false)
+	id := fs.String("id", "", "identifier of the task")
+	if err := parse(fs, g, args, "id"); err != nil {
+		return err
+	}
+
+	client, err := a.client(g)
+	if err != nil {
+		return err
+	}
+
+	task, err := client.Task.Get(ctx, *id)
+	if err != nil {
+		return err
+	}
+	return a.print(g, task, taskColumns...)
+}
+
+func (a *app) tasksWait(ctx context.Context, name string, args []string) error {
+	fs, g := a.newFlags(name, false)
+	id := fs.String("id", "", "identifier of the task")
+	if err := parse(fs, g, args, "id"); err != nil {
+		return err
+	}
+
+	client, err := a.client(g)
+	if err != nil {
+		return err
+	}
+
+	if err := client.Task.Wait(ctx, *id); err != nil {
+		return err
+	}
+
+	task, err := client.Task.Get(ctx, *id)
+	if err != nil {
+		return err
+	}
+	return a.print(g, task, taskColumns...)
+}
@@ -0,0 +1,53 @@
+package rediscloud_api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/RedisLabs/rediscloud-go-api/internal"
+	"github.com/RedisLabs/rediscloud-go-api/tracing"
+)
+
+// CorrelationIDHeader is the header that every request is sent with a correlation ID in. Every request made by a call,
+// such as creating a database and polling its task, shares the same ID. The ID is also added to the events logged for
+// the request and to the errors it returns, so that a failed call can be pointed at when contacting support.
+const CorrelationIDHeader = internal.CorrelationIDHeader
+
+// WithCorrelationID returns a context that sends every request made by a call with `id`, instead of generating a new
+// ID for each call.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return tracing.ContextWithCorrelationID(ctx, id)
+}
+
+// WithResponseMetadata returns a context that fills `metadata` with the details of each response received by a call
+// made with it. A call that makes more than one request, such as waiting for a task, leaves the details of the last
+// response - use the `...Async` variant of the call to get the response to the request that started the task.
+//
+// The metadata isn't safe to share between calls made concurrently.
+func WithResponseMetadata(ctx context.Context, metadata *Metadata) context.Context {
+	return internal.ContextWithResponseObserver(ctx, func(m internal.ResponseMetadata) {
+		*metadata = Metadata{
+			StatusCode:    m.StatusCode,
+			Header:        m.Header,
+			RequestID:     m.RequestID,
+			CorrelationID: m.CorrelationID,
+			Duration:      m.Duration,
+			Attempts:      m.Attempts,
+		}
+	})
+}
+
+// Metadata describes a response received from the API.
+type Metadata struct {
+	StatusCode int
+	Header     http.Header
+	// RequestID is the identifier that the API returned for the request in the `X-Request-Id` header, if any.
+	RequestID string
+	// CorrelationID is the ID the request was sent with in the `CorrelationIDHeader`.
+	CorrelationID string
+	// Duration is the time from sending the request to receiving the headers of the response.
+	Duration time.Duration
+	// Attempts is the number of times the request was sent, including any retries.
+	Attempts int
+}
@@ -0,0 +1,72 @@
+package rediscloud_api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithResponseMetadata(t *testing.T) {
+	var correlationID string
+	handler := testServer("key", "secret", getRequest(t, "/subscriptions/42/databases/4291", `{"databaseId":4291}`))
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		correlationID = r.Header.Get(CorrelationIDHeader)
+		w.Header().Set("X-Request-Id", "request")
+		handler(w, r)
+	}))
+
+	subject, err := clientFromTestServer(s, "key", "secret")
+	require.NoError(t, err)
+
+	var metadata Metadata
+	ctx := WithResponseMetadata(WithCorrelationID(context.TODO(), "correlation"), &metadata)
+	_, err = subject.Database.Get(ctx, 42, 4291)
+	require.NoError(t, err)
+
+	assert.Equal(t, "correlation", correlationID)
+	assert.Equal(t, http.StatusOK, metadata.StatusCode)
+	assert.Equal(t, "request", metadata.RequestID)
+	assert.Equal(t, "correlation", metadata.CorrelationID)
+	assert.Equal(t, "request", metadata.Header.Get("X-Request-Id"))
+	assert.Equal(t, 1, metadata.Attempts)
+	assert.True(t, metadata.Duration > 0)
+}
+
+func TestCorrelationID_SharedByEveryRequestOfACall(t *testing.T) {
+	task := `{
+  "taskId": "task",
+  "commandType": "cloudAccountDeleteRequest",
+  "status": "%s",
+  "timestamp": "2020-10-28T09:58:16.798Z",
+  "response": {}
+}`
+	handler := testServer("key", "secret",
+		deleteRequest(t, "/cloud-accounts/1", fmt.Sprintf(task, "received")),
+		getRequest(t, "/tasks/task", fmt.Sprintf(task, "processing-in-progress")),
+		getRequest(t, "/tasks/task", fmt.Sprintf(task, "processing-completed")),
+		getRequest(t, "/cloud-accounts/1", `{"id": 1}`))
+	var received []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get(CorrelationIDHeader))
+		handler(w, r)
+	}))
+
+	subject, err := NewClient(BaseURL(s.URL), Auth("key", "secret"), Transporter(s.Client().Transport),
+		TaskPolling(TaskPollingPolicy{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond}))
+	require.NoError(t, err)
+
+	require.NoError(t, subject.CloudAccount.Delete(context.TODO(), 1))
+	_, err = subject.CloudAccount.Get(context.TODO(), 1)
+	require.NoError(t, err)
+
+	require.Len(t, received, 4)
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, received[0])
+	assert.Equal(t, []string{received[0], received[0], received[0]}, received[:3])
+	assert.NotEqual(t, received[0], received[3])
+}
@@ -1,14 +1,22 @@
 package rediscloud_api
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/RedisLabs/rediscloud-go-api/apierrors"
+	"github.com/RedisLabs/rediscloud-go-api/logging"
+	"github.com/RedisLabs/rediscloud-go-api/metrics"
 	"github.com/RedisLabs/rediscloud-go-api/redis"
 	"github.com/RedisLabs/rediscloud-go-api/service/databases"
+	"github.com/RedisLabs/rediscloud-go-api/service/tasks"
+	"github.com/RedisLabs/rediscloud-go-api/tracing"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -496,3 +504,442 @@ func TestDatabase_Import(t *testing.T) {
 	})
 	require.NoError(t, err)
 }
+
+func TestDatabase_DeleteAsync(t *testing.T) {
+	s := httptest.NewServer(testServer("key", "secret", deleteRequest(t, "/subscriptions/42/databases/4291", `{
+  "taskId": "task",
+  "commandType": "databaseDeleteRequest",
+  "status": "received",
+  "description": "Task request received and is being queued for processing.",
+  "timestamp": "2020-11-02T09:05:34.3Z",
+  "_links": {
+    "task": {
+      "href": "https://example.org",
+      "title": "getTaskStatusUpdates",
+      "type": "GET"
+    }
+  }
+}`)))
+
+	subject, err := clientFromTestServer(s, "key", "secret")
+	require.NoError(t, err)
+
+	actual, err := subject.Database.DeleteAsync(context.TODO(), 42, 4291)
+	require.NoError(t, err)
+
+	assert.Equal(t, &tasks.Task{
+		ID:          redis.String("task"),
+		CommandType: redis.String("databaseDeleteRequest"),
+		Status:      redis.String(tasks.StatusReceived),
+		Description: redis.String("Task request received and is being queued for processing."),
+		Timestamp:   redis.Time(time.Date(2020, 11, 2, 9, 5, 34, 300000000, time.UTC)),
+	}, actual)
+}
+
+func TestDatabase_Get_wraps404Error(t *testing.T) {
+	s := httptest.NewServer(testServer("key", "secret", getRequestWithStatus(t, "/subscriptions/42/databases/4291", 404, "")))
+
+	subject, err := clientFromTestServer(s, "key", "secret")
+	require.NoError(t, err)
+
+	actual, err := subject.Database.Get(context.TODO(), 42, 4291)
+
+	assert.Nil(t, actual)
+	var notFound *databases.NotFound
+	require.True(t, errors.As(err, &notFound))
+	assert.Equal(t, 42, notFound.SubscriptionID)
+	assert.Equal(t, 4291, notFound.DatabaseID)
+	assert.True(t, errors.Is(err, apierrors.ErrNotFound))
+
+	var httpErr *apierrors.HTTPError
+	require.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, http.StatusNotFound, httpErr.StatusCode)
+	assert.Equal(t, apierrors.ResourceDatabase, httpErr.ResourceKind)
+	assert.Equal(t, "42/4291", httpErr.ResourceID)
+}
+
+func TestDatabase_Create_wraps404Error(t *testing.T) {
+	s := httptest.NewServer(testServer("key", "secret", endpointRequest{
+		method:      http.MethodPost,
+		path:        "/subscriptions/42/databases",
+		requestBody: redis.String(`{"name":"example"}`),
+		status:      http.StatusNotFound,
+		t:           t,
+	}))
+
+	subject, err := clientFromTestServer(s, "key", "secret")
+	require.NoError(t, err)
+
+	_, err = subject.Database.Create(context.TODO(), 42, databases.CreateDatabase{Name: redis.String("example")})
+
+	var notFound *databases.NotFound
+	require.True(t, errors.As(err, &notFound))
+	assert.Equal(t, 42, notFound.SubscriptionID)
+	assert.Equal(t, 0, notFound.DatabaseID)
+	assert.Equal(t, "subscription 42 not found", notFound.Error())
+}
+
+func TestDatabase_Delete_wraps404Error(t *testing.T) {
+	s := httptest.NewServer(testServer("key", "secret", endpointRequest{
+		method: http.MethodDelete,
+		path:   "/subscriptions/42/databases/4291",
+		status: http.StatusNotFound,
+		t:      t,
+	}))
+
+	subject, err := clientFromTestServer(s, "key", "secret")
+	require.NoError(t, err)
+
+	err = subject.Database.Delete(context.TODO(), 42, 4291)
+
+	var notFound *databases.NotFound
+	require.True(t, errors.As(err, &notFound))
+	assert.Equal(t, 42, notFound.SubscriptionID)
+	assert.Equal(t, 4291, notFound.DatabaseID)
+}
+
+func TestDatabase_Update_retriesWhenSubscriptionBusy(t *testing.T) {
+	busy := `{
+  "type": "SUBSCRIPTION_NOT_ACTIVE",
+  "status": "400 BAD_REQUEST",
+  "description": "Cannot preform any actions for subscription that is not in an active state"
+}`
+	task := `{
+  "taskId": "task",
+  "commandType": "databaseUpdateRequest",
+  "status": "received",
+  "description": "Task request received and is being queued for processing.",
+  "timestamp": "2020-11-02T09:05:34.3Z"
+}`
+	s := httptest.NewServer(testServer("key", "secret",
+		putRequestWithStatus(t, "/subscriptions/42/databases/18", `{"name": "example"}`, 400, busy),
+		getRequest(t, "/subscriptions/42", `{"id": 42, "status": "pending"}`),
+		getRequest(t, "/subscriptions/42", `{"id": 42, "status": "active"}`),
+		putRequest(t, "/subscriptions/42/databases/18", `{"name": "example"}`, task),
+		getRequest(t, "/tasks/task", `{
+  "taskId": "task",
+  "commandType": "databaseUpdateRequest",
+  "status": "processing-completed",
+  "timestamp": "2020-10-28T09:58:16.798Z",
+  "response": {
+    "resourceId": 18
+  }
+}`)))
+
+	subject, err := NewClient(BaseURL(s.URL), Auth("key", "secret"), Transporter(s.Client().Transport),
+		TaskPolling(TaskPollingPolicy{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond}),
+		RetryWhenSubscriptionBusy(time.Minute))
+	require.NoError(t, err)
+
+	err = subject.Database.Update(context.TODO(), 42, 18, databases.UpdateDatabase{
+		Name: redis.String("example"),
+	})
+	require.NoError(t, err)
+}
+
+func TestDatabase_Update_logsStructuredEvents(t *testing.T) {
+	busy := `{
+  "type": "SUBSCRIPTION_NOT_ACTIVE",
+  "status": "400 BAD_REQUEST",
+  "description": "Cannot preform any actions for subscription that is not in an active state"
+}`
+	s := httptest.NewServer(testServer("key", "secret",
+		putRequestWithStatus(t, "/subscriptions/42/databases/18", `{"name": "example"}`, 400, busy),
+		getRequest(t, "/subscriptions/42", `{"id": 42, "status": "active"}`),
+		putRequest(t, "/subscriptions/42/databases/18", `{"name": "example"}`, `{"taskId": "task", "status": "received"}`),
+		getRequest(t, "/tasks/task", `{"taskId": "task", "status": "processing-in-progress"}`),
+		getRequest(t, "/tasks/task", `{"taskId": "task", "status": "processing-completed", "response": {}}`)))
+
+	type event struct {
+		level   logging.Level
+		message string
+		fields  map[string]interface{}
+	}
+	var events []event
+	logger := logging.LoggerFunc(func(_ context.Context, level logging.Level, message string, keyvals ...interface{}) {
+		fields := map[string]interface{}{}
+		for i := 0; i < len(keyvals); i += 2 {
+			fields[keyvals[i].(string)] = keyvals[i+1]
+		}
+		events = append(events, event{level: level, message: message, fields: fields})
+	})
+
+	subject, err := NewClient(BaseURL(s.URL), Auth("key", "secret"), Transporter(s.Client().Transport),
+		TaskPolling(TaskPollingPolicy{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond}),
+		RetryWhenSubscriptionBusy(time.Minute), StructuredLogger(logger))
+	require.NoError(t, err)
+
+	err = subject.Database.Update(context.TODO(), 42, 18, databases.UpdateDatabase{
+		Name: redis.String("example"),
+	})
+	require.NoError(t, err)
+
+	require.Len(t, events, 3)
+
+	assert.Equal(t, logging.LevelWarn, events[0].level)
+	assert.Equal(t, "Subscription is busy, waiting for it to become active before retrying", events[0].message)
+	assert.Equal(t, "update database", events[0].fields[logging.KeyOperation])
+	assert.Equal(t, 42, events[0].fields[logging.KeySubscriptionID])
+	assert.Equal(t, 18, events[0].fields[logging.KeyDatabaseID])
+	assert.Contains(t, fmt.Sprint(events[0].fields[logging.KeyError]), "SUBSCRIPTION_NOT_ACTIVE")
+
+	assert.Equal(t, event{
+		level:   logging.LevelInfo,
+		message: "Waiting for the database to finish being updated",
+		fields: map[string]interface{}{
+			logging.KeyOperation:      "update database",
+			logging.KeySubscriptionID: 42,
+			logging.KeyDatabaseID:     18,
+			logging.KeyTaskID:         "task",
+		},
+	}, events[1])
+
+	assert.Equal(t, event{
+		level:   logging.LevelDebug,
+		message: "Task not processed yet",
+		fields: map[string]interface{}{
+			logging.KeyOperation:      "update database",
+			logging.KeySubscriptionID: 42,
+			logging.KeyDatabaseID:     18,
+			logging.KeyTaskID:         "task",
+			logging.KeyStatus:         "processing-in-progress",
+		},
+	}, events[2])
+}
+
+func TestDatabase_Update_recordsMetrics(t *testing.T) {
+	s := httptest.NewServer(testServer("key", "secret",
+		putRequest(t, "/subscriptions/42/databases/18", `{"name": "example"}`, `{"taskId": "task", "status": "received"}`),
+		getRequest(t, "/tasks/task", `{"taskId": "task", "commandType": "databaseUpdateRequest", "status": "processing-in-progress"}`),
+		getRequest(t, "/tasks/task", `{"taskId": "task", "commandType": "databaseUpdateRequest", "status": "processing-completed", "response": {}}`)))
+
+	recorder := metrics.NewPrometheus()
+	subject, err := NewClient(BaseURL(s.URL), Auth("key", "secret"), Transporter(s.Client().Transport),
+		TaskPolling(TaskPollingPolicy{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond}), Metrics(recorder))
+	require.NoError(t, err)
+
+	err = subject.Database.Update(context.TODO(), 42, 18, databases.UpdateDatabase{
+		Name: redis.String("example"),
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	_, err = recorder.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), `rediscloud_requests_total{code="200",method="PUT",route="/subscriptions/{id}/databases/{id}"} 1`)
+	assert.Contains(t, buf.String(), `rediscloud_requests_total{code="200",method="GET",route="/tasks/{id}"} 2`)
+	assert.Contains(t, buf.String(), `rediscloud_task_wait_duration_seconds_count{command_type="databaseUpdateRequest",status="processing-completed"} 1`)
+}
+
+func TestDatabase_Update_tracesOperation(t *testing.T) {
+	var traceParents []string
+	polls := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceParents = append(traceParents, r.Header.Get("traceparent"))
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/subscriptions/42/databases/18":
+			_, _ = w.Write([]byte(`{"taskId": "task", "status": "received"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/tasks/task":
+			polls++
+			if polls < 2 {
+				_, _ = w.Write([]byte(`{"taskId": "task", "commandType": "databaseUpdateRequest", "status": "processing-in-progress"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"taskId": "task", "commandType": "databaseUpdateRequest", "status": "processing-completed", "response": {}}`))
+		default:
+			w.WriteHeader(http.StatusNotImplemented)
+		}
+	}))
+
+	tracer := &recordingTracer{}
+	subject, err := NewClient(BaseURL(s.URL), Auth("key", "secret"), Transporter(s.Client().Transport),
+		TaskPolling(TaskPollingPolicy{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond}), Tracer(tracer))
+	require.NoError(t, err)
+
+	err = subject.Database.Update(context.TODO(), 42, 18, databases.UpdateDatabase{
+		Name: redis.String("example"),
+	})
+	require.NoError(t, err)
+
+	var tree []string
+	for _, span := range tracer.spans {
+		tree = append(tree, span.parent+" > "+span.name)
+	}
+	assert.Equal(t, []string{
+		" > update database 18 for subscription 42",
+		"update database 18 for subscription 42 > PUT /subscriptions/{id}/databases/{id}",
+		"update database 18 for subscription 42 > wait for task task",
+		"wait for task task > poll task task",
+		"poll task task > GET /tasks/{id}",
+		"wait for task task > poll task task",
+		"poll task task > GET /tasks/{id}",
+	}, tree)
+
+	operation := tracer.spans[0]
+	assert.Equal(t, 42, operation.attributes[logging.KeySubscriptionID])
+	assert.Equal(t, 18, operation.attributes[logging.KeyDatabaseID])
+	assert.True(t, operation.ended)
+
+	put := tracer.spans[1]
+	assert.Equal(t, http.StatusOK, put.attributes[tracing.KeyHTTPStatusCode])
+
+	wait := tracer.spans[2]
+	assert.Equal(t, "task", wait.attributes[logging.KeyTaskID])
+	assert.Equal(t, "databaseUpdateRequest", wait.attributes[tracing.KeyCommandType])
+	assert.Equal(t, "processing-completed", wait.attributes[logging.KeyStatus])
+	assert.Equal(t, "processing-in-progress", tracer.spans[3].attributes[logging.KeyStatus])
+
+	assert.Equal(t, []string{
+		put.context.TraceParent(),
+		tracer.spans[4].context.TraceParent(),
+		tracer.spans[6].context.TraceParent(),
+	}, traceParents)
+}
+
+func TestDatabase_ReadsAreTraced(t *testing.T) {
+	s := httptest.NewServer(testServer("key", "secret",
+		getRequest(t, "/subscriptions/42/databases/18", `{"databaseId": 18, "name": "example"}`),
+		getRequestWithQuery(t, "/subscriptions/42/databases", map[string][]string{"limit": {"100"}, "offset": {"0"}}, `{
+  "subscription": [
+    {
+      "subscriptionId": 42,
+      "databases": [{"databaseId": 18, "name": "example"}]
+    }
+  ]
+}`),
+		getRequestWithQueryAndStatus(t, "/subscriptions/42/databases", map[string][]string{"limit": {"100"}, "offset": {"100"}}, 404, "")))
+
+	tracer := &recordingTracer{}
+	subject, err := NewClient(BaseURL(s.URL), Auth("key", "secret"), Transporter(s.Client().Transport), Tracer(tracer))
+	require.NoError(t, err)
+
+	_, err = subject.Database.Get(context.TODO(), 42, 18)
+	require.NoError(t, err)
+
+	list := subject.Database.List(context.TODO(), 42)
+	for list.Next() {
+	}
+	require.NoError(t, list.Err())
+
+	var tree []string
+	for _, span := range tracer.spans {
+		tree = append(tree, span.parent+" > "+span.name)
+	}
+	assert.Equal(t, []string{
+		" > get database 18 for subscription 42",
+		"get database 18 for subscription 42 > GET /subscriptions/{id}/databases/{id}",
+		" > list databases for subscription 42",
+		"list databases for subscription 42 > GET /subscriptions/{id}/databases",
+		" > list databases for subscription 42",
+		"list databases for subscription 42 > GET /subscriptions/{id}/databases",
+	}, tree)
+
+	assert.Equal(t, 42, tracer.spans[0].attributes[logging.KeySubscriptionID])
+	assert.Equal(t, 18, tracer.spans[0].attributes[logging.KeyDatabaseID])
+	for _, span := range tracer.spans {
+		assert.True(t, span.ended)
+	}
+	// Running out of databases is how the list ends, rather than it failing
+	assert.NoError(t, tracer.spans[4].err)
+}
+
+type recordingTracer struct {
+	spans []*recordedSpan
+}
+
+type recordedSpan struct {
+	name       string
+	parent     string
+	attributes map[string]interface{}
+	context    tracing.SpanContext
+	ended      bool
+	err        error
+}
+
+type recordedSpanKey struct{}
+
+func (r *recordingTracer) Start(ctx context.Context, name string, keyvals ...interface{}) (context.Context, tracing.Span) {
+	span := &recordedSpan{name: name, attributes: map[string]interface{}{}}
+	span.SetAttributes(keyvals...)
+	span.context.TraceID[0] = 1
+	span.context.SpanID[7] = byte(len(r.spans) + 1)
+	span.context.Sampled = true
+	if parent, ok := ctx.Value(recordedSpanKey{}).(*recordedSpan); ok {
+		span.parent = parent.name
+	}
+	r.spans = append(r.spans, span)
+	return context.WithValue(ctx, recordedSpanKey{}, span), span
+}
+
+func (s *recordedSpan) SetAttributes(keyvals ...interface{}) {
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		s.attributes[keyvals[i].(string)] = keyvals[i+1]
+	}
+}
+
+func (s *recordedSpan) SpanContext() tracing.SpanContext {
+	return s.context
+}
+
+func (s *recordedSpan) End(err error) {
+	s.ended = true
+	s.err = err
+}
+
+func TestDatabase_Update_doesNotRetryWhenSubscriptionBusyByDefault(t *testing.T) {
+	s := httptest.NewServer(testServer("key", "secret",
+		putRequestWithStatus(t, "/subscriptions/42/databases/18", `{"name": "example"}`, 409, "")))
+
+	subject, err := clientFromTestServer(s, "key", "secret")
+	require.NoError(t, err)
+
+	err = subject.Database.Update(context.TODO(), 42, 18, databases.UpdateDatabase{
+		Name: redis.String("example"),
+	})
+	assert.True(t, errors.Is(err, apierrors.ErrConflict))
+}
+
+func TestDatabase_Create_validatesRequestWhenEnabled(t *testing.T) {
+	calls := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	subject, err := NewClient(BaseURL(s.URL), Auth("key", "secret"), Transporter(s.Client().Transport), ValidateRequests(true))
+	require.NoError(t, err)
+
+	_, err = subject.Database.Create(context.TODO(), 42, databases.CreateDatabase{
+		Name:               redis.String("cache"),
+		MemoryLimitInGB:    redis.Float64(1),
+		DataEvictionPolicy: redis.String("allkeys-lur"),
+	})
+
+	var validation *apierrors.ValidationError
+	require.True(t, errors.As(err, &validation), "got %v", err)
+	assert.Equal(t, "dataEvictionPolicy", validation.Errors[0].Path)
+	assert.True(t, errors.Is(err, apierrors.ErrInvalid))
+	assert.Equal(t, 0, calls)
+}
+
+func TestDatabase_UpdateAsync_nextChangeWaitsForItsTaskWhenSerialized(t *testing.T) {
+	task := func(id string, status string) string {
+		return fmt.Sprintf(`{"taskId":"%s","commandType":"databaseUpdateRequest","status":"%s","response":{}}`, id, status)
+	}
+	s := httptest.NewServer(testServer("key", "secret",
+		putRequest(t, "/subscriptions/42/databases/18", `{"name":"example"}`, task("update", "received")),
+		getRequest(t, "/tasks/update", task("update", "processing-completed")),
+		postRequestWithNoRequest(t, "/subscriptions/42/databases/18/backup", task("backup", "received")),
+		getRequest(t, "/tasks/backup", task("backup", "processing-completed"))))
+
+	subject, err := NewClient(BaseURL(s.URL), Auth("key", "secret"), Transporter(s.Client().Transport),
+		SerializeSubscriptionChanges(true),
+		TaskPolling(TaskPollingPolicy{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond}))
+	require.NoError(t, err)
+
+	_, err = subject.Database.UpdateAsync(context.TODO(), 42, 18, databases.UpdateDatabase{Name: redis.String("example")})
+	require.NoError(t, err)
+
+	err = subject.Database.Backup(context.TODO(), 42, 18)
+	require.NoError(t, err)
+}
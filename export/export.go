@@ -0,0 +1,282 @@
+// Package export takes a point-in-time snapshot of everything in an account - the account's metadata, cloud accounts,
+// subscriptions and their databases, CIDR allowlists and VPC peerings - which can be written as JSON or YAML for
+// audits or disaster recovery:
+//
+//	snapshot, err := export.Export(ctx, client)
+//	...
+//	err = snapshot.WriteYAML(os.Stdout)
+//
+// Secrets, such as the passwords of databases, are redacted unless `IncludeSecrets` is used.
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	rediscloud_api "github.com/RedisLabs/rediscloud-go-api"
+	"github.com/RedisLabs/rediscloud-go-api/redis"
+	"github.com/RedisLabs/rediscloud-go-api/service/account"
+	"github.com/RedisLabs/rediscloud-go-api/service/cloud_accounts"
+	"github.com/RedisLabs/rediscloud-go-api/service/databases"
+	"github.com/RedisLabs/rediscloud-go-api/service/subscriptions"
+	"gopkg.in/yaml.v3"
+)
+
+// Version of the snapshot format, which will change if the format changes in a way that isn't backwards compatible.
+const Version = 1
+
+// Redacted replaces the value of any secret in a snapshot.
+const Redacted = "REDACTED"
+
+// Snapshot is everything in an account at the time it was exported.
+type Snapshot struct {
+	Version       int                            `json:"version"`
+	CreatedAt     time.Time                      `json:"createdAt"`
+	Account       Account                        `json:"account"`
+	CloudAccounts []*cloud_accounts.CloudAccount `json:"cloudAccounts"`
+	Subscriptions []*Subscription                `json:"subscriptions"`
+}
+
+// Account is the metadata of the account.
+type Account struct {
+	PaymentMethods  []*account.PaymentMethod   `json:"paymentMethods"`
+	Regions         []*account.Region          `json:"regions"`
+	DataPersistence []*account.DataPersistence `json:"dataPersistence"`
+	DatabaseModules []*account.DatabaseModule  `json:"databaseModules"`
+}
+
+// Subscription is a subscription, including its cloud details, along with everything within it.
+type Subscription struct {
+	subscriptions.Subscription
+	Databases     []*databases.Database        `json:"databases"`
+	CIDRAllowlist *subscriptions.CIDRAllowlist `json:"cidrAllowlist,omitempty"`
+	Peerings      []*subscriptions.VPCPeering  `json:"peerings"`
+}
+
+type options struct {
+	concurrency    int
+	includeSecrets bool
+}
+
+type Option func(*options)
+
+// Concurrency sets the maximum number of subscriptions that are exported at the same time - will default to 4.
+func Concurrency(concurrency int) Option {
+	return func(o *options) {
+		o.concurrency = concurrency
+	}
+}
+
+// IncludeSecrets keeps secrets, such as the passwords of databases, in the snapshot - will default to false.
+func IncludeSecrets(include bool) Option {
+	return func(o *options) {
+		o.includeSecrets = include
+	}
+}
+
+// Export fetches everything in the account. Subscriptions are fetched concurrently, and the export stops at the first
+// error.
+func Export(ctx context.Context, client *rediscloud_api.Client, opts ...Option) (*Snapshot, error) {
+	o := options{concurrency: 4}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.concurrency < 1 {
+		o.concurrency = 1
+	}
+
+	snapshot := &Snapshot{Version: Version, CreatedAt: time.Now().UTC()}
+
+	var err error
+	if snapshot.Account, err = exportAccount(ctx, client); err != nil {
+		return nil, err
+	}
+	if snapshot.CloudAccounts, err = client.CloudAccount.List(ctx); err != nil {
+		return nil, fmt.Errorf("failed to export cloud accounts: %w", err)
+	}
+
+	subs, err := client.Subscription.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export subscriptions: %w", err)
+	}
+	sort.Slice(subs, func(i, j int) bool {
+		return redis.IntValue(subs[i].ID) < redis.IntValue(subs[j].ID)
+	})
+
+	if snapshot.Subscriptions, err = exportSubscriptions(ctx, client, subs, o.concurrency); err != nil {
+		return nil, err
+	}
+
+	if !o.includeSecrets {
+		redact(snapshot)
+	}
+	return snapshot, nil
+}
+
+func exportAccount(ctx context.Context, client *rediscloud_api.Client) (Account, error) {
+	var a Account
+	var err error
+	if a.PaymentMethods, err = client.Account.ListPaymentMethods(ctx); err != nil {
+		return a, fmt.Errorf("failed to export payment methods: %w", err)
+	}
+	if a.Regions, err = client.Account.ListRegions(ctx); err != nil {
+		return a, fmt.Errorf("failed to export regions: %w", err)
+	}
+	if a.DataPersistence, err = client.Account.ListDataPersistence(ctx); err != nil {
+		return a, fmt.Errorf("failed to export data persistence options: %w", err)
+	}
+	if a.DatabaseModules, err = client.Account.ListDatabaseModules(ctx); err != nil {
+		return a, fmt.Errorf("failed to export database modules: %w", err)
+	}
+	return a, nil
+}
+
+// exportSubscriptions exports each of the subscriptions, with at most `concurrency` being exported at once. The
+// subscriptions are returned in the same order they were given.
+func exportSubscriptions(ctx context.Context, client *rediscloud_api.Client, subs []*subscriptions.Subscription, concurrency int) ([]*Subscription, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	exported := make([]*Subscription, len(subs))
+	semaphore := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+
+	for i, sub := range subs {
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, sub *subscriptions.Subscription) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			s, err := exportSubscription(ctx, client, sub)
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			exported[i] = s
+		}(i, sub)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return exported, nil
+}
+
+func exportSubscription(ctx context.Context, client *rediscloud_api.Client, sub *subscriptions.Subscription) (*Subscription, error) {
+	id := redis.IntValue(sub.ID)
+	s := &Subscription{Subscription: *sub, Databases: []*databases.Database{}}
+
+	list := client.Database.List(ctx, id)
+	for list.Next() {
+		s.Databases = append(s.Databases, list.Value())
+	}
+	if err := list.Err(); err != nil {
+		return nil, fmt.Errorf("failed to export databases of subscription %d: %w", id, err)
+	}
+
+	var err error
+	if s.CIDRAllowlist, err = client.Subscription.GetCIDRAllowlist(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to export CIDR allowlist of subscription %d: %w", id, err)
+	}
+	if s.Peerings, err = client.Subscription.ListVPCPeering(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to export VPC peerings of subscription %d: %w", id, err)
+	}
+	return s, nil
+}
+
+func redact(snapshot *Snapshot) {
+	for _, sub := range snapshot.Subscriptions {
+		for _, db := range sub.Databases {
+			if db.Security != nil && db.Security.Password != nil {
+				security := *db.Security
+				security.Password = redis.String(Redacted)
+				db.Security = &security
+			}
+		}
+	}
+}
+
+// WriteJSON writes the snapshot as indented JSON.
+func (s *Snapshot) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// WriteYAML writes the snapshot as YAML, using the same field names as the JSON.
+func (s *Snapshot) WriteYAML(w io.Writer) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	// Decoding the JSON as YAML keeps the JSON field names, and as it is YAML's job to decide what type each value
+	// is, large identifiers aren't turned into floats
+	var node yaml.Node
+	if err := yaml.NewDecoder(bytes.NewReader(data)).Decode(&node); err != nil {
+		return err
+	}
+	setBlockStyle(&node)
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// setBlockStyle clears the flow and quoting styles that the JSON was parsed with, so the YAML is written in the usual
+// block style. Strings that would otherwise be read back as another type are still quoted.
+func setBlockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		setBlockStyle(child)
+	}
+}
+
+// ReadSnapshot reads a snapshot that was written as either JSON or YAML.
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	var generic interface{}
+	if err := yaml.NewDecoder(r).Decode(&generic); err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	data, err := json.Marshal(generic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	if snapshot.Version != Version {
+		return nil, fmt.Errorf("snapshot has unsupported version %d", snapshot.Version)
+	}
+	return &snapshot, nil
+}
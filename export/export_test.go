@@ -0,0 +1,150 @@
+package export_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	rediscloud_api "github.com/RedisLabs/rediscloud-go-api"
+	"github.com/RedisLabs/rediscloud-go-api/export"
+	"github.com/RedisLabs/rediscloud-go-api/faults"
+	"github.com/RedisLabs/rediscloud-go-api/redis"
+	"github.com/RedisLabs/rediscloud-go-api/rediscloudtest"
+	"github.com/RedisLabs/rediscloud-go-api/service/cloud_accounts"
+	"github.com/RedisLabs/rediscloud-go-api/service/databases"
+	"github.com/RedisLabs/rediscloud-go-api/service/subscriptions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExport_IncludesEverythingAndRedactsSecrets(t *testing.T) {
+	fake := rediscloudtest.NewServer()
+	defer fake.Close()
+
+	fake.AddCloudAccount(cloud_accounts.CloudAccount{Name: redis.String("aws"), Provider: redis.String("AWS")})
+	first := fake.AddSubscription(subscriptions.Subscription{
+		Name: redis.String("first"),
+		CloudDetails: []*subscriptions.CloudDetail{
+			{Provider: redis.String("AWS"), Regions: []*subscriptions.Region{{Region: redis.String("us-east-1")}}},
+		},
+	})
+	second := fake.AddSubscription(subscriptions.Subscription{Name: redis.String("second")})
+	for i := 0; i < 3; i++ {
+		_, err := fake.AddDatabase(first, databases.Database{
+			Name:     redis.String("db"),
+			Security: &databases.Security{Password: redis.String("hunter2")},
+		})
+		require.NoError(t, err)
+	}
+
+	snapshot, err := export.Export(context.Background(), newClient(t, fake, nil), export.Concurrency(2))
+	require.NoError(t, err)
+
+	assert.Equal(t, export.Version, snapshot.Version)
+	assert.NotEmpty(t, snapshot.Account.PaymentMethods)
+	assert.NotEmpty(t, snapshot.Account.Regions)
+	require.Len(t, snapshot.CloudAccounts, 1)
+	assert.Equal(t, "aws", redis.StringValue(snapshot.CloudAccounts[0].Name))
+
+	require.Len(t, snapshot.Subscriptions, 2)
+	assert.Equal(t, first, redis.IntValue(snapshot.Subscriptions[0].ID))
+	assert.Equal(t, second, redis.IntValue(snapshot.Subscriptions[1].ID))
+	assert.Equal(t, "us-east-1", redis.StringValue(snapshot.Subscriptions[0].CloudDetails[0].Regions[0].Region))
+	assert.NotNil(t, snapshot.Subscriptions[0].CIDRAllowlist)
+	assert.Empty(t, snapshot.Subscriptions[1].Databases)
+
+	require.Len(t, snapshot.Subscriptions[0].Databases, 3)
+	for _, db := range snapshot.Subscriptions[0].Databases {
+		assert.Equal(t, export.Redacted, redis.StringValue(db.Security.Password))
+	}
+
+	// The live database is left untouched
+	id := redis.IntValue(snapshot.Subscriptions[0].Databases[0].ID)
+	assert.Equal(t, "hunter2", redis.StringValue(fake.Database(first, id).Security.Password))
+}
+
+func TestExport_IncludeSecrets(t *testing.T) {
+	fake := rediscloudtest.NewServer()
+	defer fake.Close()
+
+	sub := fake.AddSubscription(subscriptions.Subscription{Name: redis.String("example")})
+	_, err := fake.AddDatabase(sub, databases.Database{
+		Name:     redis.String("db"),
+		Security: &databases.Security{Password: redis.String("hunter2")},
+	})
+	require.NoError(t, err)
+
+	snapshot, err := export.Export(context.Background(), newClient(t, fake, nil), export.IncludeSecrets(true))
+	require.NoError(t, err)
+
+	assert.Equal(t, "hunter2", redis.StringValue(snapshot.Subscriptions[0].Databases[0].Security.Password))
+}
+
+func TestExport_StopsAtTheFirstError(t *testing.T) {
+	fake := rediscloudtest.NewServer()
+	defer fake.Close()
+
+	for i := 0; i < 5; i++ {
+		fake.AddSubscription(subscriptions.Subscription{Name: redis.String("example")})
+	}
+
+	transport := faults.New(nil, faults.Rule{
+		Method:      http.MethodGet,
+		Path:        "/subscriptions/*/peerings",
+		Fault:       faults.Status(http.StatusForbidden),
+		Probability: 1,
+	})
+
+	_, err := export.Export(context.Background(), newClient(t, fake, transport))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to export VPC peerings of subscription")
+}
+
+func TestSnapshot_RoundTripsThroughJSONAndYAML(t *testing.T) {
+	fake := rediscloudtest.NewServer()
+	defer fake.Close()
+
+	sub := fake.AddSubscription(subscriptions.Subscription{Name: redis.String("example")})
+	_, err := fake.AddDatabase(sub, databases.Database{Name: redis.String("db"), MemoryLimitInGB: redis.Float64(2)})
+	require.NoError(t, err)
+
+	snapshot, err := export.Export(context.Background(), newClient(t, fake, nil))
+	require.NoError(t, err)
+
+	var yaml bytes.Buffer
+	require.NoError(t, snapshot.WriteYAML(&yaml))
+	assert.Contains(t, yaml.String(), "version: 1\n")
+	assert.Contains(t, yaml.String(), "memoryLimitInGb: 2\n")
+	assert.NotContains(t, yaml.String(), `"version"`)
+
+	var json bytes.Buffer
+	require.NoError(t, snapshot.WriteJSON(&json))
+
+	for _, encoded := range []string{yaml.String(), json.String()} {
+		actual, err := export.ReadSnapshot(strings.NewReader(encoded))
+		require.NoError(t, err)
+		assert.True(t, snapshot.CreatedAt.Equal(actual.CreatedAt))
+		actual.CreatedAt = snapshot.CreatedAt
+		assert.Equal(t, snapshot, actual)
+	}
+
+	_, err = export.ReadSnapshot(strings.NewReader(`{"version": 2}`))
+	assert.EqualError(t, err, "snapshot has unsupported version 2")
+}
+
+func newClient(t *testing.T, fake *rediscloudtest.Server, transport http.RoundTripper) *rediscloud_api.Client {
+	options := []rediscloud_api.Option{
+		rediscloud_api.BaseURL(fake.URL),
+		rediscloud_api.Auth("key", "secret"),
+		rediscloud_api.TaskPolling(rediscloud_api.TaskPollingPolicy{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond}),
+	}
+	if transport != nil {
+		options = append(options, rediscloud_api.Transporter(transport))
+	}
+	client, err := rediscloud_api.NewClient(options...)
+	require.NoError(t, err)
+	return client
+}
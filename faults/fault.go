@@ -0,0 +1,182 @@
+package faults
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fault handles a request in place of the next RoundTripper, which it may still use to make the request and then
+// change the response.
+type Fault func(request *http.Request, next http.RoundTripper) (*http.Response, error)
+
+// Status responds with the status code and an error document, without making the request.
+func Status(statusCode int) Fault {
+	return func(request *http.Request, _ http.RoundTripper) (*http.Response, error) {
+		closeBody(request)
+		return newResponse(request, statusCode, nil, errorDocument(statusCode, "Fault injected for testing"))
+	}
+}
+
+// RateLimited responds with a 429 status, asking for the request to be retried after `retryAfter` - no `Retry-After`
+// header is sent if it is zero.
+func RateLimited(retryAfter time.Duration) Fault {
+	return func(request *http.Request, _ http.RoundTripper) (*http.Response, error) {
+		closeBody(request)
+		header := http.Header{}
+		if retryAfter > 0 {
+			seconds := int((retryAfter + time.Second - 1) / time.Second)
+			header.Set("Retry-After", strconv.Itoa(seconds))
+		}
+		return newResponse(request, http.StatusTooManyRequests, header, errorDocument(http.StatusTooManyRequests, "Too many requests"))
+	}
+}
+
+// Timeout fails the request with an error reporting that it timed out, without making the request.
+func Timeout() Fault {
+	return func(request *http.Request, _ http.RoundTripper) (*http.Response, error) {
+		closeBody(request)
+		return nil, &timeoutError{method: request.Method, path: request.URL.Path}
+	}
+}
+
+// Delay waits before making the request, returning early with an error if the request's context is done first - this
+// can be combined with a timeout on the HTTP client or the context to cause a real timeout.
+func Delay(delay time.Duration) Fault {
+	return func(request *http.Request, next http.RoundTripper) (*http.Response, error) {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+			return next.RoundTrip(request)
+		case <-request.Context().Done():
+			closeBody(request)
+			return nil, request.Context().Err()
+		}
+	}
+}
+
+// TruncatedJSON makes the request and then cuts the body of the response in half, so that it can't be decoded.
+func TruncatedJSON() Fault {
+	return func(request *http.Request, next http.RoundTripper) (*http.Response, error) {
+		response, body, err := roundTrip(request, next)
+		if err != nil {
+			return nil, err
+		}
+		setBody(response, body[:len(body)/2])
+		return response, nil
+	}
+}
+
+// TaskFailure makes the request and, if the response is a task, changes the task to have failed with the description.
+// This is intended for requests to `/tasks/{id}`, so that waiting on any task will fail.
+func TaskFailure(description string) Fault {
+	return func(request *http.Request, next http.RoundTripper) (*http.Response, error) {
+		response, body, err := roundTrip(request, next)
+		if err != nil {
+			return nil, err
+		}
+
+		var task map[string]interface{}
+		if response.StatusCode != http.StatusOK || json.Unmarshal(body, &task) != nil || task["taskId"] == nil {
+			setBody(response, body)
+			return response, nil
+		}
+
+		task["status"] = "processing-error"
+		task["description"] = "Task request failed during processing. See error information for failure details."
+		taskResponse, _ := task["response"].(map[string]interface{})
+		if taskResponse == nil {
+			taskResponse = map[string]interface{}{}
+		}
+		delete(taskResponse, "resource")
+		taskResponse["error"] = errorDocument(http.StatusBadRequest, description)
+		task["response"] = taskResponse
+
+		body, err = json.Marshal(task)
+		if err != nil {
+			return nil, err
+		}
+		setBody(response, body)
+		return response, nil
+	}
+}
+
+type timeoutError struct {
+	method string
+	path   string
+}
+
+func (e *timeoutError) Error() string {
+	return fmt.Sprintf("%s %s: injected timeout", e.method, e.path)
+}
+
+// Timeout and Temporary implement `net.Error`.
+func (e *timeoutError) Timeout() bool   { return true }
+func (e *timeoutError) Temporary() bool { return true }
+
+func errorDocument(statusCode int, description string) map[string]string {
+	return map[string]string{
+		"type":        "INJECTED_FAULT",
+		"status":      fmt.Sprintf("%d %s", statusCode, strings.ReplaceAll(strings.ToUpper(http.StatusText(statusCode)), " ", "_")),
+		"description": description,
+	}
+}
+
+func newResponse(request *http.Request, statusCode int, header http.Header, document interface{}) (*http.Response, error) {
+	body, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("Content-Type", "application/json")
+
+	response := &http.Response{
+		Status:     fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		StatusCode: statusCode,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     header,
+		Request:    request,
+	}
+	setBody(response, body)
+	return response, nil
+}
+
+func roundTrip(request *http.Request, next http.RoundTripper) (*http.Response, []byte, error) {
+	response, err := next.RoundTrip(request)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	body, err := ioutil.ReadAll(response.Body)
+	_ = response.Body.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+	return response, body, nil
+}
+
+// closeBody closes the body of a request that won't be passed on to the next RoundTripper, as a RoundTripper must
+// always close the request body.
+func closeBody(request *http.Request) {
+	if request.Body != nil {
+		_ = request.Body.Close()
+	}
+}
+
+func setBody(response *http.Response, body []byte) {
+	response.Body = ioutil.NopCloser(bytes.NewReader(body))
+	response.ContentLength = int64(len(body))
+	response.Header.Del("Content-Length")
+}
@@ -0,0 +1,135 @@
+// Package faults provides a RoundTripper that injects faults - such as 429s, 5xx responses, timeouts, truncated
+// responses and failed tasks - into the requests made to the Redis Cloud API, so that the handling of these faults can
+// be tested on demand:
+//
+//	transport := faults.New(http.DefaultTransport,
+//		faults.Rule{Method: http.MethodGet, Path: "/subscriptions/*", Sequence: []faults.Fault{faults.RateLimited(0), nil}},
+//		faults.Rule{Path: "/tasks/*", Probability: 0.1, Fault: faults.Status(http.StatusServiceUnavailable)},
+//	)
+//	client, err := rediscloud_api.NewClient(rediscloud_api.Transporter(transport))
+//
+// Transports can be composed by passing one as the next RoundTripper of another.
+package faults
+
+import (
+	"math/rand"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rule describes which requests a fault is injected into.
+type Rule struct {
+	// Method of the requests to match - will default to matching any method.
+	Method string
+	// Path is a pattern, using the syntax of `path.Match`, which is matched against the end of the request's path, so
+	// that `/tasks/*` matches `/v1/tasks/{id}` regardless of the base URL - will default to matching any path.
+	Path string
+	// Fault is injected into matching requests, according to the probability.
+	Fault Fault
+	// Probability that Fault is injected into a matching request, between 0 and 1 - use 1 to inject it into every
+	// matching request. The zero value never injects it.
+	Probability float64
+	// Sequence of faults injected into consecutive matching requests, with a nil fault letting the request through
+	// unchanged. Once the sequence is exhausted, all further matching requests are let through. Fault and
+	// Probability are ignored when a sequence is set.
+	Sequence []Fault
+}
+
+func (r Rule) matches(request *http.Request) bool {
+	if r.Method != "" && !strings.EqualFold(r.Method, request.Method) {
+		return false
+	}
+	if r.Path == "" {
+		return true
+	}
+
+	pattern := strings.Split(strings.Trim(r.Path, "/"), "/")
+	segments := strings.Split(strings.Trim(request.URL.Path, "/"), "/")
+	if len(segments) < len(pattern) {
+		return false
+	}
+
+	matched, err := path.Match(strings.Join(pattern, "/"), strings.Join(segments[len(segments)-len(pattern):], "/"))
+	return err == nil && matched
+}
+
+// Transport is a RoundTripper that injects faults according to its rules, passing every other request on to the next
+// RoundTripper. Only the first rule that matches a request is used. It is safe to use from multiple goroutines.
+type Transport struct {
+	next  http.RoundTripper
+	rules []Rule
+
+	mu        sync.Mutex
+	rand      *rand.Rand
+	positions []int
+	injected  int
+}
+
+// New creates a Transport that passes any request without a fault on to `next` - will default to the Go default.
+func New(next http.RoundTripper, rules ...Rule) *Transport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Transport{
+		next:      next,
+		rules:     rules,
+		rand:      rand.New(rand.NewSource(time.Now().UnixNano())),
+		positions: make([]int, len(rules)),
+	}
+}
+
+// Seed sets the seed used to decide whether faults with a probability are injected, so that the faults injected are
+// repeatable.
+func (t *Transport) Seed(seed int64) *Transport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.rand = rand.New(rand.NewSource(seed))
+	return t
+}
+
+// Injected returns the number of faults that have been injected so far.
+func (t *Transport) Injected() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.injected
+}
+
+func (t *Transport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if fault := t.faultFor(request); fault != nil {
+		return fault(request, t.next)
+	}
+	return t.next.RoundTrip(request)
+}
+
+func (t *Transport) faultFor(request *http.Request) Fault {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i, rule := range t.rules {
+		if !rule.matches(request) {
+			continue
+		}
+
+		var fault Fault
+		if rule.Sequence != nil {
+			if t.positions[i] < len(rule.Sequence) {
+				fault = rule.Sequence[t.positions[i]]
+				t.positions[i]++
+			}
+		} else if t.rand.Float64() < rule.Probability {
+			fault = rule.Fault
+		}
+
+		if fault != nil {
+			t.injected++
+		}
+		return fault
+	}
+
+	return nil
+}
@@ -0,0 +1,192 @@
+package faults_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	rediscloud_api "github.com/RedisLabs/rediscloud-go-api"
+	"github.com/RedisLabs/rediscloud-go-api/apierrors"
+	"github.com/RedisLabs/rediscloud-go-api/faults"
+	"github.com/RedisLabs/rediscloud-go-api/redis"
+	"github.com/RedisLabs/rediscloud-go-api/rediscloudtest"
+	"github.com/RedisLabs/rediscloud-go-api/service/databases"
+	"github.com/RedisLabs/rediscloud-go-api/service/subscriptions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFaults_SequenceIsRetried(t *testing.T) {
+	fake, subID := newFake(t)
+	transport := faults.New(nil, faults.Rule{
+		Method:   http.MethodGet,
+		Path:     "/subscriptions/*",
+		Sequence: []faults.Fault{faults.RateLimited(0), faults.Status(http.StatusServiceUnavailable)},
+	})
+
+	client := newClient(t, fake, transport, rediscloud_api.Retry(rediscloud_api.RetryPolicy{InitialDelay: time.Millisecond}))
+
+	sub, err := client.Subscription.Get(context.Background(), subID)
+	require.NoError(t, err)
+	assert.Equal(t, "example", redis.StringValue(sub.Name))
+	assert.Equal(t, 2, transport.Injected())
+
+	_, err = client.Subscription.Get(context.Background(), subID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, transport.Injected())
+}
+
+func TestFaults_Status(t *testing.T) {
+	fake, subID := newFake(t)
+	client := newClient(t, fake, faults.New(nil, faults.Rule{Probability: 1, Fault: faults.Status(http.StatusBadGateway)}))
+
+	_, err := client.Subscription.Get(context.Background(), subID)
+	var httpErr *apierrors.HTTPError
+	require.True(t, errors.As(err, &httpErr), "got %v", err)
+	assert.Equal(t, http.StatusBadGateway, httpErr.StatusCode)
+	assert.Equal(t, "INJECTED_FAULT", httpErr.ErrorCode)
+}
+
+func TestFaults_Timeout(t *testing.T) {
+	fake, subID := newFake(t)
+	client := newClient(t, fake, faults.New(nil, faults.Rule{Probability: 1, Fault: faults.Timeout()}))
+
+	_, err := client.Subscription.Get(context.Background(), subID)
+	var netErr net.Error
+	require.True(t, errors.As(err, &netErr), "got %v", err)
+	assert.True(t, netErr.Timeout())
+}
+
+func TestFaults_DelayIsCancelledWithTheContext(t *testing.T) {
+	fake, subID := newFake(t)
+	client := newClient(t, fake, faults.New(nil, faults.Rule{Probability: 1, Fault: faults.Delay(time.Minute)}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := client.Subscription.Get(ctx, subID)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "got %v", err)
+}
+
+func TestFaults_TruncatedJSON(t *testing.T) {
+	fake, subID := newFake(t)
+	client := newClient(t, fake, faults.New(nil, faults.Rule{Path: "/subscriptions/*", Probability: 1, Fault: faults.TruncatedJSON()}))
+
+	_, err := client.Subscription.Get(context.Background(), subID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to decode response")
+}
+
+func TestFaults_TaskFailure(t *testing.T) {
+	fake, subID := newFake(t)
+	transport := faults.New(nil, faults.Rule{Method: http.MethodGet, Path: "/tasks/*", Probability: 1, Fault: faults.TaskFailure("Injected failure")})
+	client := newClient(t, fake, transport)
+
+	_, err := client.Database.Create(context.Background(), subID, databases.CreateDatabase{
+		Name:            redis.String("example"),
+		MemoryLimitInGB: redis.Float64(1),
+	})
+	assert.True(t, errors.Is(err, apierrors.ErrTaskFailed), "got %v", err)
+
+	var taskErr *apierrors.TaskError
+	require.True(t, errors.As(err, &taskErr))
+	var apiErr *apierrors.Error
+	require.True(t, errors.As(taskErr, &apiErr))
+	assert.Equal(t, "Injected failure", redis.StringValue(apiErr.Description))
+}
+
+func TestFaults_Probability(t *testing.T) {
+	fake, subID := newFake(t)
+	transport := faults.New(nil,
+		faults.Rule{Method: http.MethodDelete, Probability: 1, Fault: faults.Status(http.StatusInternalServerError)},
+		faults.Rule{Path: "/subscriptions/*", Probability: 0.5, Fault: faults.Status(http.StatusInternalServerError)},
+	).Seed(1)
+	client := newClient(t, fake, transport)
+
+	failures := 0
+	for i := 0; i < 100; i++ {
+		if _, err := client.Subscription.Get(context.Background(), subID); err != nil {
+			failures++
+		}
+	}
+
+	assert.Equal(t, failures, transport.Injected())
+	assert.Greater(t, failures, 25)
+	assert.Less(t, failures, 75)
+}
+
+func TestFaults_ZeroProbabilityNeverInjects(t *testing.T) {
+	fake, subID := newFake(t)
+	transport := faults.New(nil, faults.Rule{Fault: faults.Status(http.StatusInternalServerError)})
+	client := newClient(t, fake, transport)
+
+	for i := 0; i < 10; i++ {
+		_, err := client.Subscription.Get(context.Background(), subID)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 0, transport.Injected())
+}
+
+func TestFaults_UnmatchedRequestsAreLetThrough(t *testing.T) {
+	fake, _ := newFake(t)
+	transport := faults.New(nil,
+		faults.Rule{Method: http.MethodPost, Probability: 1, Fault: faults.Timeout()},
+		faults.Rule{Path: "/tasks/*", Probability: 1, Fault: faults.Timeout()},
+		faults.Rule{Path: "/v2/subscriptions", Probability: 1, Fault: faults.Timeout()},
+	)
+	client := newClient(t, fake, transport)
+
+	_, err := client.Subscription.List(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, transport.Injected())
+}
+
+func newFake(t *testing.T) (*rediscloudtest.Server, int) {
+	fake := rediscloudtest.NewServer()
+	t.Cleanup(fake.Close)
+	return fake, fake.AddSubscription(subscriptions.Subscription{Name: redis.String("example")})
+}
+
+func newClient(t *testing.T, fake *rediscloudtest.Server, transport http.RoundTripper, options ...rediscloud_api.Option) *rediscloud_api.Client {
+	options = append(options,
+		rediscloud_api.BaseURL(fake.URL),
+		rediscloud_api.Auth("key", "secret"),
+		rediscloud_api.Transporter(transport),
+		rediscloud_api.TaskPolling(rediscloud_api.TaskPollingPolicy{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond}),
+	)
+	client, err := rediscloud_api.NewClient(options...)
+	require.NoError(t, err)
+	return client
+}
+
+func TestFaults_CloseTheBodyOfRequestsNotMade(t *testing.T) {
+	for name, fault := range map[string]faults.Fault{
+		"status":       faults.Status(http.StatusBadGateway),
+		"rate limited": faults.RateLimited(time.Second),
+		"timeout":      faults.Timeout(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			body := &closeRecorder{Reader: strings.NewReader(`{}`)}
+			request, err := http.NewRequest(http.MethodPost, "https://example.org/subscriptions", body)
+			require.NoError(t, err)
+
+			_, _ = fault(request, nil)
+			assert.True(t, body.closed)
+		})
+	}
+}
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
@@ -6,4 +6,5 @@ require (
 	github.com/avast/retry-go v3.0.0+incompatible
 	github.com/stretchr/testify v1.6.1
 	golang.org/x/tools v0.0.0-20201028111035-eafbe7b904eb
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c
 )
@@ -0,0 +1,104 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/RedisLabs/rediscloud-go-api/logging"
+	"github.com/RedisLabs/rediscloud-go-api/redis"
+	"github.com/avast/retry-go"
+)
+
+// BusyRetry retries requests that were rejected because the subscription was still busy with a previous change, once
+// the subscription has become active again. A nil BusyRetry will not retry any requests.
+type BusyRetry struct {
+	client  *HttpClient
+	logger  logging.Logger
+	timeout time.Duration
+	polling PollingPolicy
+}
+
+// NewBusyRetry creates a BusyRetry that will wait for up to `timeout` in total for the subscription to become active,
+// polling the subscription according to `polling`.
+func NewBusyRetry(client *HttpClient, logger logging.Logger, timeout time.Duration, polling PollingPolicy) *BusyRetry {
+	return &BusyRetry{
+		client:  client,
+		logger:  logger,
+		timeout: timeout,
+		polling: defaultPollingPolicy.merge(polling),
+	}
+}
+
+// Do makes the request, and if the request was rejected because the subscription was busy, will wait for the
+// subscription to become active and make the request again until either it isn't rejected or the timeout is reached.
+func (b *BusyRetry) Do(ctx context.Context, subscription int, request func() error) error {
+	err := request()
+	if b == nil || !isSubscriptionBusy(err) {
+		return err
+	}
+
+	waitCtx := ctx
+	if b.timeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, b.timeout)
+		defer cancel()
+	}
+
+	for isSubscriptionBusy(err) {
+		b.logger.Log(ctx, logging.LevelWarn, "Subscription is busy, waiting for it to become active before retrying", logging.KeySubscriptionID, subscription, logging.KeyError, err)
+
+		if waitErr := b.waitForActive(waitCtx, subscription); waitErr != nil {
+			if ctx.Err() == nil && waitCtx.Err() != nil {
+				return fmt.Errorf("timed out after %s waiting for subscription %d to become active: %w", b.timeout, subscription, err)
+			}
+			return waitErr
+		}
+
+		err = request()
+	}
+
+	return err
+}
+
+func (b *BusyRetry) waitForActive(ctx context.Context, subscription int) error {
+	policy := pollingPolicyFromContext(ctx, b.polling)
+
+	return retry.Do(func() error {
+		var response struct {
+			Status *string `json:"status,omitempty"`
+		}
+		err := b.client.Get(ctx, fmt.Sprintf("retrieve subscription %d", subscription), fmt.Sprintf("/subscriptions/%d", subscription), &response)
+		if err != nil {
+			return retry.Unrecoverable(err)
+		}
+
+		status := redis.StringValue(response.Status)
+		if status != subscriptionStatusActive {
+			return fmt.Errorf("subscription %d is not active yet: %s", subscription, status)
+		}
+		return nil
+	},
+		retry.Attempts(math.MaxUint16), retry.MaxDelay(policy.MaxDelay),
+		retry.DelayType(retry.CombineDelay(policy.delay, retry.RandomDelay)),
+		retry.LastErrorOnly(true), retry.Context(ctx))
+}
+
+// Same value as `subscriptions.SubscriptionStatusActive`, which can't be imported here.
+const subscriptionStatusActive = "active"
+
+// Error codes returned by the API when a request can't be processed until a previous change has finished.
+var busyErrorCodes = map[string]bool{
+	"SUBSCRIPTION_NOT_ACTIVE": true,
+}
+
+func isSubscriptionBusy(err error) bool {
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		return false
+	}
+	return httpErr.StatusCode == http.StatusConflict || busyErrorCodes[httpErr.ErrorCode]
+}
@@ -0,0 +1,63 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/RedisLabs/rediscloud-go-api/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBusyRetry_Do_timesOut(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"pending"}`))
+	}))
+
+	client, err := NewHttpClient(s.Client(), s.URL)
+	require.NoError(t, err)
+
+	subject := NewBusyRetry(client, logging.Discard, 50*time.Millisecond, PollingPolicy{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond})
+
+	calls := 0
+	err = subject.Do(context.TODO(), 1, func() error {
+		calls++
+		return &HTTPError{StatusCode: http.StatusConflict}
+	})
+
+	var httpErr *HTTPError
+	require.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, http.StatusConflict, httpErr.StatusCode)
+	assert.Contains(t, err.Error(), "timed out after 50ms waiting for subscription 1 to become active")
+	assert.Equal(t, 1, calls)
+}
+
+func TestBusyRetry_Do_nilDoesNotRetry(t *testing.T) {
+	var subject *BusyRetry
+
+	calls := 0
+	err := subject.Do(context.TODO(), 1, func() error {
+		calls++
+		return &HTTPError{StatusCode: http.StatusConflict}
+	})
+
+	require.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestBusyRetry_Do_doesNotRetryOtherErrors(t *testing.T) {
+	subject := NewBusyRetry(nil, logging.Discard, time.Minute, PollingPolicy{})
+
+	calls := 0
+	err := subject.Do(context.TODO(), 1, func() error {
+		calls++
+		return &HTTPError{StatusCode: http.StatusBadRequest, ErrorCode: "DATABASE_NAME_INVALID"}
+	})
+
+	require.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
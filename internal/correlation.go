@@ -0,0 +1,41 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+const (
+	// CorrelationIDHeader is sent with every request, so that the request can be identified when contacting support.
+	CorrelationIDHeader = "X-Correlation-Id"
+	// RequestIDHeader is the header the API returns its own identifier of a request in.
+	RequestIDHeader = "X-Request-Id"
+)
+
+// ResponseMetadata describes a response received from the API.
+type ResponseMetadata struct {
+	StatusCode    int
+	Header        http.Header
+	RequestID     string
+	CorrelationID string
+	Duration      time.Duration
+	Attempts      int
+}
+
+// ResponseObserver is called with every response received from the API.
+type ResponseObserver func(metadata ResponseMetadata)
+
+type responseObserverKey struct{}
+
+// ContextWithResponseObserver returns a context that will notify `observer` of every response received for a request
+// made using it.
+func ContextWithResponseObserver(ctx context.Context, observer ResponseObserver) context.Context {
+	return context.WithValue(ctx, responseObserverKey{}, observer)
+}
+
+func observeResponse(ctx context.Context, metadata ResponseMetadata) {
+	if observer, ok := ctx.Value(responseObserverKey{}).(ResponseObserver); ok {
+		observer(metadata)
+	}
+}
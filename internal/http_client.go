@@ -9,19 +9,75 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"time"
+
+	"github.com/RedisLabs/rediscloud-go-api/apierrors"
+	"github.com/RedisLabs/rediscloud-go-api/logging"
+	"github.com/RedisLabs/rediscloud-go-api/metrics"
+	"github.com/RedisLabs/rediscloud-go-api/tracing"
 )
 
 type HttpClient struct {
-	client  *http.Client
-	baseUrl *url.URL
+	client   *http.Client
+	baseUrl  *url.URL
+	retry    *RetryPolicy
+	limiter  *RateLimiter
+	validate bool
+	metrics  metrics.Recorder
+	tracer   tracing.Tracer
+}
+
+type HttpClientOption func(*HttpClient)
+
+// WithRetryPolicy enables retrying requests that fail with a 429 or 5xx status. Any unset values in the policy are
+// replaced with the defaults.
+func WithRetryPolicy(policy RetryPolicy) HttpClientOption {
+	return func(client *HttpClient) {
+		p := policy.withDefaults()
+		client.retry = &p
+	}
+}
+
+// WithRateLimiter makes every request, including each retry, wait for the limiter before being sent.
+func WithRateLimiter(limiter *RateLimiter) HttpClientOption {
+	return func(client *HttpClient) {
+		client.limiter = limiter
+	}
+}
+
+// WithValidation checks every request body that implements `Validatable` before it is sent, failing the request
+// without sending it if the body isn't valid.
+func WithValidation() HttpClientOption {
+	return func(client *HttpClient) {
+		client.validate = true
+	}
 }
 
-func NewHttpClient(client *http.Client, baseUrl string) (*HttpClient, error) {
+// WithMetrics reports every attempt at a request to the recorder.
+func WithMetrics(recorder metrics.Recorder) HttpClientOption {
+	return func(client *HttpClient) {
+		client.metrics = recorder
+	}
+}
+
+// WithTracer records a span for every attempt at a request, and propagates it to the API in the `traceparent` header.
+func WithTracer(tracer tracing.Tracer) HttpClientOption {
+	return func(client *HttpClient) {
+		client.tracer = tracer
+	}
+}
+
+func NewHttpClient(client *http.Client, baseUrl string, options ...HttpClientOption) (*HttpClient, error) {
 	parsed, err := url.Parse(baseUrl)
 	if err != nil {
 		return nil, err
 	}
-	return &HttpClient{client: client, baseUrl: parsed}, nil
+
+	c := &HttpClient{client: client, baseUrl: parsed, tracer: tracing.Noop}
+	for _, option := range options {
+		option(c)
+	}
+	return c, nil
 }
 
 func (c *HttpClient) Get(ctx context.Context, name, path string, responseBody interface{}) error {
@@ -54,52 +110,130 @@ func (c *HttpClient) connection(ctx context.Context, method, name, path string,
 	}
 
 	u := parsed.String()
+	route := metrics.Route(path)
+
+	correlation := tracing.CorrelationID(ctx)
+	ctx = logging.ContextWithFields(ctx, logging.KeyCorrelationID, correlation)
+
+	if v, ok := requestBody.(Validatable); ok && c.validate {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("failed to %s: %w", name, err)
+		}
+	}
 
-	var body io.Reader
+	var encoded []byte
 	if requestBody != nil {
 		buf := bytes.NewBuffer(nil)
 		if err := json.NewEncoder(buf).Encode(requestBody); err != nil {
 			return fmt.Errorf("failed to encode request for %s: %w", name, err)
 		}
-		body = buf
+		encoded = buf.Bytes()
 	}
 
-	request, err := http.NewRequestWithContext(ctx, method, u, body)
-	if err != nil {
-		return fmt.Errorf("failed to create request to %s: %w", name, err)
-	}
+	for attempt := 1; ; attempt++ {
+		// The body has to be recreated for every attempt as the previous one will have been consumed
+		var body io.Reader
+		if encoded != nil {
+			body = bytes.NewReader(encoded)
+		}
 
-	response, err := c.client.Do(request)
-	if err != nil {
-		return fmt.Errorf("failed to %s: %w", name, err)
-	}
+		if err := c.limiter.Wait(ctx); err != nil {
+			return fmt.Errorf("failed to %s: %w", name, err)
+		}
+
+		spanCtx, span := c.tracer.Start(ctx, method+" "+route,
+			tracing.KeyHTTPMethod, method, tracing.KeyHTTPRoute, route, tracing.KeyHTTPAttempt, attempt,
+			logging.KeyCorrelationID, correlation)
 
-	defer response.Body.Close()
+		request, err := http.NewRequestWithContext(spanCtx, method, u, body)
+		if err != nil {
+			span.End(err)
+			return fmt.Errorf("failed to create request to %s: %w", name, err)
+		}
+		request.Header.Set(CorrelationIDHeader, correlation)
+		if sc := span.SpanContext(); sc.IsValid() {
+			request.Header.Set(tracing.TraceParentHeader, sc.TraceParent())
+		}
 
-	if response.StatusCode > 299 {
-		body, _ := ioutil.ReadAll(response.Body)
-		return &HTTPError{
-			Name:       name,
-			StatusCode: response.StatusCode,
-			Body:       body,
+		start := time.Now()
+		response, err := c.client.Do(request)
+		if err != nil {
+			c.record(span, method, route, 0, start, attempt, err)
+			return fmt.Errorf("failed to %s (correlation ID %s): %w", name, correlation, err)
 		}
+
+		observeResponse(ctx, ResponseMetadata{
+			StatusCode:    response.StatusCode,
+			Header:        response.Header,
+			RequestID:     response.Header.Get(RequestIDHeader),
+			CorrelationID: correlation,
+			Duration:      time.Since(start),
+			Attempts:      attempt,
+		})
+
+		if response.StatusCode > 299 {
+			body, _ := ioutil.ReadAll(response.Body)
+			_ = response.Body.Close()
+			c.record(span, method, route, response.StatusCode, start, attempt, nil)
+
+			if c.retry.shouldRetry(method, attempt, response.StatusCode) {
+				if delay, ok := c.retry.delay(attempt, response.Header); ok {
+					if err := sleep(ctx, delay); err != nil {
+						return fmt.Errorf("failed to %s: %w", name, err)
+					}
+					continue
+				}
+			}
+
+			httpErr := apierrors.NewHTTPError(name, response.StatusCode, body, attempt)
+			httpErr.CorrelationID = correlation
+			httpErr.RequestID = response.Header.Get(RequestIDHeader)
+			return httpErr
+		}
+
+		defer response.Body.Close()
+
+		if err := json.NewDecoder(response.Body).Decode(&responseBody); err != nil {
+			c.record(span, method, route, response.StatusCode, start, attempt, err)
+			return fmt.Errorf("failed to decode response to %s (correlation ID %s): %w", name, correlation, err)
+		}
+
+		c.record(span, method, route, response.StatusCode, start, attempt, nil)
+		return nil
 	}
+}
 
-	if err := json.NewDecoder(response.Body).Decode(&responseBody); err != nil {
-		return fmt.Errorf("failed to decode response to %s: %w", name, err)
+// record finishes the span of an attempt at a request, and reports the attempt to any metrics recorder.
+func (c *HttpClient) record(span tracing.Span, method, route string, statusCode int, start time.Time, attempt int, err error) {
+	if statusCode != 0 {
+		span.SetAttributes(tracing.KeyHTTPStatusCode, statusCode)
 	}
+	span.End(err)
 
-	return nil
+	if c.metrics == nil {
+		return
+	}
+	c.metrics.RequestCompleted(metrics.Request{
+		Method:     method,
+		Route:      route,
+		StatusCode: statusCode,
+		Duration:   time.Since(start),
+		Attempt:    attempt,
+		Err:        err,
+	})
 }
 
-type HTTPError struct {
-	Name       string
-	StatusCode int
-	Body       []byte
-}
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
 
-func (h *HTTPError) Error() string {
-	return fmt.Sprintf("failed to %s: %d - %s", h.Name, h.StatusCode, h.Body)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
 }
 
-var _ error = &HTTPError{}
+// HTTPError is returned when the API responds with an unsuccessful status code.
+type HTTPError = apierrors.HTTPError
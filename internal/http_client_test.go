@@ -2,10 +2,17 @@ package internal
 
 import (
 	"context"
+	"errors"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/RedisLabs/rediscloud-go-api/apierrors"
+	"github.com/RedisLabs/rediscloud-go-api/metrics"
+	"github.com/RedisLabs/rediscloud-go-api/tracing"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -20,3 +27,253 @@ func TestHttpClient_Get_failsFor4xx(t *testing.T) {
 	err = subject.Get(context.TODO(), "testing", "/", nil)
 	require.Error(t, err)
 }
+
+func TestHttpClient_Get_retriesServerErrors(t *testing.T) {
+	calls := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"value":"ok"}`))
+	}))
+
+	subject, err := NewHttpClient(s.Client(), s.URL, WithRetryPolicy(RetryPolicy{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond}))
+	require.NoError(t, err)
+
+	var actual map[string]string
+	err = subject.Get(context.TODO(), "testing", "/", &actual)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"value": "ok"}, actual)
+	assert.Equal(t, 3, calls)
+}
+
+func TestHttpClient_Get_reportsAttemptsWhenRetriesExhausted(t *testing.T) {
+	calls := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+
+	subject, err := NewHttpClient(s.Client(), s.URL, WithRetryPolicy(RetryPolicy{MaxAttempts: 2, InitialDelay: time.Millisecond}))
+	require.NoError(t, err)
+
+	err = subject.Get(context.TODO(), "testing", "/", nil)
+
+	var actual *HTTPError
+	require.True(t, errors.As(err, &actual))
+	assert.Equal(t, http.StatusTooManyRequests, actual.StatusCode)
+	assert.Equal(t, 2, actual.Attempts)
+	assert.Equal(t, 2, calls)
+}
+
+func TestHttpClient_Get_doesNotRetryClientErrors(t *testing.T) {
+	calls := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+
+	subject, err := NewHttpClient(s.Client(), s.URL, WithRetryPolicy(RetryPolicy{InitialDelay: time.Millisecond}))
+	require.NoError(t, err)
+
+	err = subject.Get(context.TODO(), "testing", "/", nil)
+	require.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestHttpClient_Post_onlyRetriedWhenEnabled(t *testing.T) {
+	calls := 0
+	var bodies []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if calls%2 == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+
+	subject, err := NewHttpClient(s.Client(), s.URL, WithRetryPolicy(RetryPolicy{InitialDelay: time.Millisecond}))
+	require.NoError(t, err)
+
+	err = subject.Post(context.TODO(), "testing", "/", map[string]string{"key": "value"}, nil)
+	require.Error(t, err)
+	assert.Equal(t, 1, calls)
+
+	calls = 0
+	bodies = nil
+	subject, err = NewHttpClient(s.Client(), s.URL, WithRetryPolicy(RetryPolicy{InitialDelay: time.Millisecond, RetryPost: true}))
+	require.NoError(t, err)
+
+	err = subject.Post(context.TODO(), "testing", "/", map[string]string{"key": "value"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, []string{"{\"key\":\"value\"}\n", "{\"key\":\"value\"}\n"}, bodies)
+}
+
+type validatedBody struct {
+	Value string `json:"value"`
+}
+
+func (b validatedBody) Validate() error {
+	var v Validator
+	v.OneOf("value", &b.Value, []string{"good"})
+	return v.Err()
+}
+
+func TestHttpClient_Post_validatesBodiesWhenEnabled(t *testing.T) {
+	calls := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		_, _ = w.Write([]byte(`{}`))
+	}))
+
+	subject, err := NewHttpClient(s.Client(), s.URL)
+	require.NoError(t, err)
+
+	err = subject.Post(context.TODO(), "testing", "/", validatedBody{Value: "bad"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+
+	subject, err = NewHttpClient(s.Client(), s.URL, WithValidation())
+	require.NoError(t, err)
+
+	err = subject.Post(context.TODO(), "testing", "/", validatedBody{Value: "bad"}, nil)
+	assert.EqualError(t, err, `failed to testing: invalid request: value must be one of ["good"], not "bad"`)
+	assert.True(t, errors.Is(err, apierrors.ErrInvalid))
+	assert.Equal(t, 1, calls)
+
+	err = subject.Post(context.TODO(), "testing", "/", validatedBody{Value: "good"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestHttpClient_Get_recordsEveryAttempt(t *testing.T) {
+	calls := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+
+	recorder := &recordingMetrics{}
+	subject, err := NewHttpClient(s.Client(), s.URL, WithMetrics(recorder), WithRetryPolicy(RetryPolicy{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond}))
+	require.NoError(t, err)
+
+	err = subject.Get(context.TODO(), "testing", "/subscriptions/12/databases/3", nil)
+	require.NoError(t, err)
+
+	require.Len(t, recorder.requests, 2)
+	for i, request := range recorder.requests {
+		assert.Equal(t, http.MethodGet, request.Method)
+		assert.Equal(t, "/subscriptions/{id}/databases/{id}", request.Route)
+		assert.Equal(t, i+1, request.Attempt)
+		assert.NoError(t, request.Err)
+		assert.True(t, request.Duration > 0)
+	}
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.requests[0].StatusCode)
+	assert.Equal(t, http.StatusOK, recorder.requests[1].StatusCode)
+}
+
+func TestHttpClient_Get_sendsCorrelationID(t *testing.T) {
+	var received []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get(CorrelationIDHeader))
+		_, _ = w.Write([]byte(`{}`))
+	}))
+
+	subject, err := NewHttpClient(s.Client(), s.URL)
+	require.NoError(t, err)
+
+	require.NoError(t, subject.Get(context.TODO(), "testing", "/", nil))
+	require.NoError(t, subject.Get(context.TODO(), "testing", "/", nil))
+	require.NoError(t, subject.Get(tracing.ContextWithCorrelationID(context.TODO(), "correlation"), "testing", "/", nil))
+
+	require.Len(t, received, 3)
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, received[0])
+	assert.NotEqual(t, received[0], received[1])
+	assert.Equal(t, "correlation", received[2])
+}
+
+func TestHttpClient_Get_reportsResponseMetadata(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(RequestIDHeader, "request")
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	subject, err := NewHttpClient(s.Client(), s.URL)
+	require.NoError(t, err)
+
+	var observed []ResponseMetadata
+	ctx := ContextWithResponseObserver(tracing.ContextWithCorrelationID(context.TODO(), "correlation"), func(m ResponseMetadata) {
+		observed = append(observed, m)
+	})
+	err = subject.Get(ctx, "testing", "/", nil)
+
+	var actual *HTTPError
+	require.True(t, errors.As(err, &actual))
+	assert.Equal(t, "correlation", actual.CorrelationID)
+	assert.Equal(t, "request", actual.RequestID)
+
+	require.Len(t, observed, 1)
+	assert.Equal(t, http.StatusNotFound, observed[0].StatusCode)
+	assert.Equal(t, "request", observed[0].RequestID)
+	assert.Equal(t, "correlation", observed[0].CorrelationID)
+	assert.Equal(t, "request", observed[0].Header.Get(RequestIDHeader))
+	assert.Equal(t, 1, observed[0].Attempts)
+	assert.True(t, observed[0].Duration > 0)
+}
+
+type recordingMetrics struct {
+	requests []metrics.Request
+	tasks    []metrics.Task
+}
+
+func (r *recordingMetrics) RequestCompleted(request metrics.Request) {
+	r.requests = append(r.requests, request)
+}
+
+func (r *recordingMetrics) TaskCompleted(task metrics.Task) {
+	r.tasks = append(r.tasks, task)
+}
+
+func TestRetryPolicy_delayHonoursRetryAfter(t *testing.T) {
+	subject := RetryPolicy{}.withDefaults()
+
+	actual, ok := subject.delay(1, http.Header{"Retry-After": {"7"}})
+	assert.True(t, ok)
+	assert.Equal(t, 7*time.Second, actual)
+
+	for attempt := 1; attempt < 10; attempt++ {
+		actual, ok := subject.delay(attempt, http.Header{})
+		assert.True(t, ok)
+		assert.True(t, actual <= subject.MaxDelay, "attempt %d waited %s", attempt, actual)
+		assert.True(t, actual >= subject.InitialDelay/2, "attempt %d waited %s", attempt, actual)
+	}
+}
+
+func TestHttpClient_Get_doesNotWaitForLongRetryAfter(t *testing.T) {
+	calls := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Retry-After", "86400")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	subject, err := NewHttpClient(s.Client(), s.URL, WithRetryPolicy(RetryPolicy{MaxRetryAfter: time.Minute}))
+	require.NoError(t, err)
+
+	err = subject.Get(context.TODO(), "testing", "/", nil)
+
+	var actual *HTTPError
+	require.True(t, errors.As(err, &actual))
+	assert.Equal(t, http.StatusServiceUnavailable, actual.StatusCode)
+	assert.Equal(t, 1, calls)
+}
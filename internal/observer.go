@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"context"
+	"time"
+
+	"github.com/RedisLabs/rediscloud-go-api/redis"
+)
+
+// TaskStatusChange describes a task, that is being waited on, being seen with a different status to the last poll.
+type TaskStatusChange struct {
+	TaskID         string
+	CommandType    string
+	PreviousStatus string
+	Status         string
+	Description    string
+	Timestamp      time.Time
+}
+
+// TaskObserver is called with every status change of the tasks being waited on.
+type TaskObserver func(change TaskStatusChange)
+
+// WithTaskObserver registers an observer for every task waited on by the API.
+func WithTaskObserver(observer TaskObserver) APIOption {
+	return func(a *api) {
+		a.observers = append(a.observers, observer)
+	}
+}
+
+type taskObserverKey struct{}
+
+// ContextWithTaskObserver returns a context that will additionally notify `observer` of the status changes of any
+// task waited on using it. Any observers already in the context are notified as well.
+func ContextWithTaskObserver(ctx context.Context, observer TaskObserver) context.Context {
+	existing, _ := ctx.Value(taskObserverKey{}).([]TaskObserver)
+	observers := append(append([]TaskObserver{}, existing...), observer)
+	return context.WithValue(ctx, taskObserverKey{}, observers)
+}
+
+type statusTracker struct {
+	previous  string
+	observers []TaskObserver
+}
+
+func newStatusTracker(ctx context.Context, observers []TaskObserver) *statusTracker {
+	all := observers
+	if fromContext, ok := ctx.Value(taskObserverKey{}).([]TaskObserver); ok {
+		all = append(append([]TaskObserver{}, observers...), fromContext...)
+	}
+	return &statusTracker{observers: all}
+}
+
+// observe notifies the observers if the status of the task is different to the last time it was seen.
+func (s *statusTracker) observe(task *task) {
+	status := redis.StringValue(task.Status)
+	if status == s.previous || len(s.observers) == 0 {
+		s.previous = status
+		return
+	}
+
+	timestamp := time.Now()
+	if task.Timestamp != nil {
+		timestamp = *task.Timestamp
+	}
+
+	change := TaskStatusChange{
+		TaskID:         redis.StringValue(task.ID),
+		CommandType:    redis.StringValue(task.CommandType),
+		PreviousStatus: s.previous,
+		Status:         status,
+		Description:    redis.StringValue(task.Description),
+		Timestamp:      timestamp,
+	}
+	s.previous = status
+
+	for _, observer := range s.observers {
+		observer(change)
+	}
+}
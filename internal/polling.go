@@ -0,0 +1,94 @@
+package internal
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/avast/retry-go"
+)
+
+// PollingPolicy controls how often a task is polled while waiting for it to complete.
+type PollingPolicy struct {
+	// InitialDelay is the delay between the first and second poll.
+	InitialDelay time.Duration
+	// MaxDelay caps the delay between two polls.
+	MaxDelay time.Duration
+	// Multiplier is applied to the delay after every poll, with a value below 1 being treated as 1 so that the delay
+	// never shrinks.
+	Multiplier float64
+	// Timeout is the total time to wait for the task before giving up, with zero meaning no timeout.
+	Timeout time.Duration
+	// Max404Errors is the number of 404 responses to tolerate before giving up, with a negative value meaning that
+	// the first 404 is returned.
+	Max404Errors int
+}
+
+// Number of 404 errors to swallow before returning an error while waiting for a task to finish.
+//
+// There's a short window between the API returning a task ID and the task being known by the
+// Task service, so by ignoring _a number_ of 404 errors we give the task service enough time to
+// learn about the task but also handle the situation where there really is no task.
+const defaultMax404Errors = 5
+
+var defaultPollingPolicy = PollingPolicy{
+	InitialDelay: 1 * time.Second,
+	MaxDelay:     30 * time.Second,
+	Multiplier:   2,
+	Max404Errors: defaultMax404Errors,
+}
+
+// merge returns a copy of the policy with any non-zero values from `override` replacing its own, clamping a
+// multiplier below 1 to 1.
+func (p PollingPolicy) merge(override PollingPolicy) PollingPolicy {
+	if override.InitialDelay > 0 {
+		p.InitialDelay = override.InitialDelay
+	}
+	if override.MaxDelay > 0 {
+		p.MaxDelay = override.MaxDelay
+	}
+	if override.Multiplier > 0 {
+		p.Multiplier = math.Max(override.Multiplier, 1)
+	}
+	if override.Timeout > 0 {
+		p.Timeout = override.Timeout
+	}
+	if override.Max404Errors != 0 {
+		p.Max404Errors = override.Max404Errors
+	}
+	return p
+}
+
+func (p PollingPolicy) notFoundTolerance() int {
+	if p.Max404Errors < 0 {
+		return 0
+	}
+	return p.Max404Errors
+}
+
+// delay grows the delay between polls by the multiplier, rather than the fixed doubling of `retry.BackOffDelay`.
+func (p PollingPolicy) delay(n uint, _ error, _ *retry.Config) time.Duration {
+	d := float64(p.InitialDelay)
+	for i := uint(0); i < n && d < float64(p.MaxDelay); i++ {
+		d *= p.Multiplier
+	}
+	if d > float64(p.MaxDelay) {
+		return p.MaxDelay
+	}
+	return time.Duration(d)
+}
+
+type pollingPolicyKey struct{}
+
+// ContextWithPollingPolicy returns a context that overrides the polling policy for any task waited on using it.
+// Only the non-zero values of `policy` are applied.
+func ContextWithPollingPolicy(ctx context.Context, policy PollingPolicy) context.Context {
+	return context.WithValue(ctx, pollingPolicyKey{}, policy)
+}
+
+func pollingPolicyFromContext(ctx context.Context, fallback PollingPolicy) PollingPolicy {
+	if override, ok := ctx.Value(pollingPolicyKey{}).(PollingPolicy); ok {
+		return fallback.merge(override)
+	}
+	return fallback
+}
@@ -0,0 +1,135 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/RedisLabs/rediscloud-go-api/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPollingPolicy_delay(t *testing.T) {
+	subject := PollingPolicy{InitialDelay: 2 * time.Second, MaxDelay: 20 * time.Second, Multiplier: 3}
+
+	assert.Equal(t, 2*time.Second, subject.delay(0, nil, nil))
+	assert.Equal(t, 6*time.Second, subject.delay(1, nil, nil))
+	assert.Equal(t, 18*time.Second, subject.delay(2, nil, nil))
+	assert.Equal(t, 20*time.Second, subject.delay(3, nil, nil))
+	assert.Equal(t, 20*time.Second, subject.delay(1000, nil, nil))
+}
+
+func TestPollingPolicy_merge(t *testing.T) {
+	actual := defaultPollingPolicy.merge(PollingPolicy{MaxDelay: time.Minute, Max404Errors: -1})
+
+	assert.Equal(t, PollingPolicy{
+		InitialDelay: 1 * time.Second,
+		MaxDelay:     time.Minute,
+		Multiplier:   2,
+		Max404Errors: -1,
+	}, actual)
+	assert.Equal(t, 0, actual.notFoundTolerance())
+}
+
+func TestPollingPolicy_merge_clampsMultiplier(t *testing.T) {
+	actual := defaultPollingPolicy.merge(PollingPolicy{Multiplier: 0.5})
+	assert.Equal(t, 1.0, actual.Multiplier)
+	assert.Equal(t, time.Second, actual.delay(10, nil, nil))
+
+	actual = defaultPollingPolicy.merge(PollingPolicy{Multiplier: 1.5})
+	assert.Equal(t, 1.5, actual.Multiplier)
+}
+
+func TestAPI_Wait_timesOut(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"taskId":"task","status":"processing-in-progress"}`))
+	}))
+
+	client, err := NewHttpClient(s.Client(), s.URL)
+	require.NoError(t, err)
+
+	subject := NewAPI(client, logging.Discard, WithPollingPolicy(PollingPolicy{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond}))
+
+	ctx := ContextWithPollingPolicy(context.TODO(), PollingPolicy{Timeout: 50 * time.Millisecond})
+	err = subject.Wait(ctx, "task")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out after 50ms waiting for task task")
+}
+
+func TestAPI_Wait_logsEachPollAtDebug(t *testing.T) {
+	polls := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		if polls < 3 {
+			_, _ = w.Write([]byte(`{"taskId":"task","status":"processing-in-progress"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"taskId":"task","status":"processing-completed","response":{}}`))
+	}))
+
+	client, err := NewHttpClient(s.Client(), s.URL)
+	require.NoError(t, err)
+
+	var events [][]interface{}
+	logger := logging.LoggerFunc(func(_ context.Context, level logging.Level, message string, keyvals ...interface{}) {
+		assert.Equal(t, logging.LevelDebug, level)
+		events = append(events, append([]interface{}{message}, keyvals...))
+	})
+
+	subject := NewAPI(client, logger, WithPollingPolicy(PollingPolicy{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond}))
+
+	require.NoError(t, subject.Wait(context.TODO(), "task"))
+	assert.Equal(t, [][]interface{}{
+		{"Task not processed yet", logging.KeyTaskID, "task", logging.KeyStatus, "processing-in-progress"},
+		{"Task not processed yet", logging.KeyTaskID, "task", logging.KeyStatus, "processing-in-progress"},
+	}, events)
+}
+
+func TestAPI_Wait_recordsTaskMetrics(t *testing.T) {
+	polls := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		if polls < 2 {
+			_, _ = w.Write([]byte(`{"taskId":"task","commandType":"databaseUpdateRequest","status":"received"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"taskId":"task","commandType":"databaseUpdateRequest","status":"processing-error","response":{"error":{"type":"INVALID","description":"Invalid"}}}`))
+	}))
+
+	client, err := NewHttpClient(s.Client(), s.URL)
+	require.NoError(t, err)
+
+	recorder := &recordingMetrics{}
+	subject := NewAPI(client, logging.Discard, WithTaskMetrics(recorder), WithPollingPolicy(PollingPolicy{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond}))
+
+	err = subject.Wait(context.TODO(), "task")
+	require.Error(t, err)
+
+	require.Len(t, recorder.tasks, 1)
+	task := recorder.tasks[0]
+	assert.Equal(t, "databaseUpdateRequest", task.CommandType)
+	assert.Equal(t, "processing-error", task.Status)
+	assert.Equal(t, 2, task.Polls)
+	assert.Equal(t, err, task.Err)
+	assert.True(t, task.Duration > 0)
+}
+
+func TestAPI_Wait_givesUpOnFirst404WhenConfigured(t *testing.T) {
+	calls := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	client, err := NewHttpClient(s.Client(), s.URL)
+	require.NoError(t, err)
+
+	subject := NewAPI(client, logging.Discard, WithPollingPolicy(PollingPolicy{InitialDelay: time.Millisecond, Max404Errors: -1}))
+
+	err = subject.Wait(context.TODO(), "task")
+	require.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,151 @@
+package internal
+
+import (
+	"context"
+	"sync"
+)
+
+// SubscriptionQueue serialises the changes made to each subscription, so that only one change to a subscription (or
+// its databases) is in progress at a time, while changes to different subscriptions can still happen concurrently.
+// A nil SubscriptionQueue doesn't serialise anything.
+type SubscriptionQueue struct {
+	mu    sync.Mutex
+	locks map[int]*queueEntry
+	// The last task started by an asynchronous change to each subscription, which hasn't been waited for yet.
+	pending map[int]string
+	waiter  TaskWaiter
+}
+
+// TaskWaiter waits for a task to finish being processed.
+type TaskWaiter interface {
+	Wait(ctx context.Context, id string) error
+}
+
+type queueEntry struct {
+	// Buffered with a capacity of one, so sending acquires the lock and receiving releases it. Blocked senders are
+	// woken in the order they arrived.
+	held chan struct{}
+	// Number of callers either holding or waiting for the lock.
+	depth int
+}
+
+// NewSubscriptionQueue creates a queue that uses `waiter` to wait for the tasks started by asynchronous changes.
+func NewSubscriptionQueue(waiter TaskWaiter) *SubscriptionQueue {
+	return &SubscriptionQueue{locks: map[int]*queueEntry{}, pending: map[int]string{}, waiter: waiter}
+}
+
+type heldSubscriptionsKey struct{}
+
+type nestedLockKey struct {
+	subscription int
+}
+
+// Lock waits until no other change is in progress for the subscription, including the task of any asynchronous change
+// recorded by `Started`. The returned context records that the lock is held, so any nested calls to Lock using it for
+// the same subscription return immediately, and the returned function must be called to release the lock.
+func (q *SubscriptionQueue) Lock(ctx context.Context, subscription int) (context.Context, func(), error) {
+	if q == nil {
+		return ctx, func() {}, nil
+	}
+
+	held, _ := ctx.Value(heldSubscriptionsKey{}).(map[int]bool)
+	if held[subscription] {
+		return context.WithValue(ctx, nestedLockKey{subscription}, true), func() {}, nil
+	}
+
+	q.mu.Lock()
+	entry, ok := q.locks[subscription]
+	if !ok {
+		entry = &queueEntry{held: make(chan struct{}, 1)}
+		q.locks[subscription] = entry
+	}
+	entry.depth++
+	q.mu.Unlock()
+
+	select {
+	case entry.held <- struct{}{}:
+	case <-ctx.Done():
+		q.release(subscription, entry)
+		return ctx, func() {}, ctx.Err()
+	}
+
+	var once sync.Once
+	unlock := func() {
+		once.Do(func() {
+			<-entry.held
+			q.release(subscription, entry)
+		})
+	}
+
+	if err := q.waitForPending(ctx, subscription); err != nil {
+		unlock()
+		return ctx, func() {}, err
+	}
+
+	nowHeld := map[int]bool{subscription: true}
+	for id := range held {
+		nowHeld[id] = true
+	}
+
+	return context.WithValue(ctx, heldSubscriptionsKey{}, nowHeld), unlock, nil
+}
+
+// Started records that a change made while holding the lock started `task` without waiting for it, so that the next
+// change to the subscription waits for the task first. A task started within a nested Lock isn't recorded, as the
+// outer call will wait for it.
+func (q *SubscriptionQueue) Started(ctx context.Context, subscription int, task string) {
+	if q == nil || ctx.Value(nestedLockKey{subscription}) != nil {
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending[subscription] = task
+}
+
+// waitForPending waits for the task recorded by `Started`, if there is one. Whether the task succeeded doesn't matter,
+// only that it's no longer being processed.
+func (q *SubscriptionQueue) waitForPending(ctx context.Context, subscription int) error {
+	q.mu.Lock()
+	task, ok := q.pending[subscription]
+	q.mu.Unlock()
+	if !ok || q.waiter == nil {
+		return nil
+	}
+
+	if err := q.waiter.Wait(ctx, task); err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.pending[subscription] == task {
+		delete(q.pending, subscription)
+	}
+	return nil
+}
+
+// Depth returns the number of changes to the subscription that are either in progress or waiting.
+func (q *SubscriptionQueue) Depth(subscription int) int {
+	if q == nil {
+		return 0
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if entry, ok := q.locks[subscription]; ok {
+		return entry.depth
+	}
+	return 0
+}
+
+func (q *SubscriptionQueue) release(subscription int, entry *queueEntry) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entry.depth--
+	if entry.depth == 0 {
+		delete(q.locks, subscription)
+	}
+}
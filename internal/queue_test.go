@@ -0,0 +1,127 @@
+package internal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscriptionQueue_serialisesChangesToTheSameSubscription(t *testing.T) {
+	subject := NewSubscriptionQueue(nil)
+
+	_, unlock, err := subject.Lock(context.TODO(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, subject.Depth(1))
+
+	acquired := make(chan struct{})
+	go func() {
+		_, unlockSecond, err := subject.Lock(context.TODO(), 1)
+		assert.NoError(t, err)
+		close(acquired)
+		unlockSecond()
+	}()
+
+	assert.Eventually(t, func() bool { return subject.Depth(1) == 2 }, time.Second, time.Millisecond)
+	select {
+	case <-acquired:
+		t.Fatal("second change started before the first finished")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	unlock()
+	<-acquired
+	assert.Eventually(t, func() bool { return subject.Depth(1) == 0 }, time.Second, time.Millisecond)
+}
+
+func TestSubscriptionQueue_allowsConcurrentChangesToDifferentSubscriptions(t *testing.T) {
+	subject := NewSubscriptionQueue(nil)
+
+	_, unlockFirst, err := subject.Lock(context.TODO(), 1)
+	require.NoError(t, err)
+	defer unlockFirst()
+
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+	defer cancel()
+
+	_, unlockSecond, err := subject.Lock(ctx, 2)
+	require.NoError(t, err)
+	unlockSecond()
+}
+
+func TestSubscriptionQueue_nestedLocksWithTheSameContextDoNotBlock(t *testing.T) {
+	subject := NewSubscriptionQueue(nil)
+
+	ctx, unlock, err := subject.Lock(context.TODO(), 1)
+	require.NoError(t, err)
+	defer unlock()
+
+	_, unlockNested, err := subject.Lock(ctx, 1)
+	require.NoError(t, err)
+	unlockNested()
+
+	assert.Equal(t, 1, subject.Depth(1))
+}
+
+func TestSubscriptionQueue_stopsWaitingWhenContextCancelled(t *testing.T) {
+	subject := NewSubscriptionQueue(nil)
+
+	_, unlock, err := subject.Lock(context.TODO(), 1)
+	require.NoError(t, err)
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+
+	_, _, err = subject.Lock(ctx, 1)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, 1, subject.Depth(1))
+}
+
+func TestSubscriptionQueue_nilDoesNothing(t *testing.T) {
+	var subject *SubscriptionQueue
+
+	_, unlock, err := subject.Lock(context.TODO(), 1)
+	require.NoError(t, err)
+	unlock()
+	assert.Equal(t, 0, subject.Depth(1))
+}
+
+func TestSubscriptionQueue_waitsForTasksStartedByAsynchronousChanges(t *testing.T) {
+	waiter := &recordingWaiter{}
+	subject := NewSubscriptionQueue(waiter)
+
+	ctx, unlock, err := subject.Lock(context.TODO(), 1)
+	require.NoError(t, err)
+	nested, unlockNested, err := subject.Lock(ctx, 1)
+	require.NoError(t, err)
+	subject.Started(nested, 1, "nested")
+	unlockNested()
+	unlock()
+
+	ctx, unlock, err = subject.Lock(context.TODO(), 1)
+	require.NoError(t, err)
+	subject.Started(ctx, 1, "async")
+	unlock()
+	assert.Empty(t, waiter.waited)
+
+	_, unlock, err = subject.Lock(context.TODO(), 1)
+	require.NoError(t, err)
+	unlock()
+	_, unlock, err = subject.Lock(context.TODO(), 1)
+	require.NoError(t, err)
+	unlock()
+
+	assert.Equal(t, []string{"async"}, waiter.waited)
+}
+
+type recordingWaiter struct {
+	waited []string
+}
+
+func (r *recordingWaiter) Wait(_ context.Context, id string) error {
+	r.waited = append(r.waited, id)
+	return nil
+}
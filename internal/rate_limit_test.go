@@ -0,0 +1,37 @@
+package internal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter_allowsBurstThenWaits(t *testing.T) {
+	now := time.Unix(0, 0)
+	subject := NewRateLimiter(2, 3)
+	subject.last = now
+	subject.now = func() time.Time { return now }
+
+	assert.Equal(t, time.Duration(0), subject.reserve())
+	assert.Equal(t, time.Duration(0), subject.reserve())
+	assert.Equal(t, time.Duration(0), subject.reserve())
+	assert.Equal(t, 500*time.Millisecond, subject.reserve())
+	assert.Equal(t, 1*time.Second, subject.reserve())
+
+	now = now.Add(10 * time.Second)
+	assert.Equal(t, time.Duration(0), subject.reserve())
+}
+
+func TestRateLimiter_Wait_stopsWhenContextCancelled(t *testing.T) {
+	subject := NewRateLimiter(0.001, 1)
+	require.NoError(t, subject.Wait(context.TODO()))
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+
+	err := subject.Wait(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy describes how requests that fail with a 429 or 5xx status should be retried.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts made for a single request, including the first.
+	MaxAttempts int
+	// InitialDelay is the delay before the first retry, which is then doubled for each subsequent retry.
+	InitialDelay time.Duration
+	// MaxDelay caps the delay between two attempts, unless the API has asked for a longer delay via `Retry-After`.
+	MaxDelay time.Duration
+	// MaxRetryAfter is the longest `Retry-After` that will be waited for - the request isn't retried if the API asks
+	// for a longer delay.
+	MaxRetryAfter time.Duration
+	// RetryPost allows POST requests to be retried - these are not idempotent so are not retried by default.
+	RetryPost bool
+}
+
+const (
+	defaultRetryMaxAttempts  = 3
+	defaultRetryInitialDelay = 1 * time.Second
+	defaultRetryMaxDelay     = 30 * time.Second
+	defaultRetryMaxAfter     = 5 * time.Minute
+)
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = defaultRetryMaxAttempts
+	}
+	if p.InitialDelay <= 0 {
+		p.InitialDelay = defaultRetryInitialDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = defaultRetryMaxDelay
+	}
+	if p.MaxDelay < p.InitialDelay {
+		p.MaxDelay = p.InitialDelay
+	}
+	if p.MaxRetryAfter <= 0 {
+		p.MaxRetryAfter = defaultRetryMaxAfter
+	}
+	return p
+}
+
+// shouldRetry reports whether another attempt should be made after `attempt` attempts have failed with `statusCode`.
+func (p *RetryPolicy) shouldRetry(method string, attempt int, statusCode int) bool {
+	if p == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+
+	if statusCode != http.StatusTooManyRequests && statusCode < 500 {
+		return false
+	}
+
+	if method == http.MethodPost {
+		return p.RetryPost
+	}
+
+	return idempotentMethods[method]
+}
+
+// delay returns how long to wait before the next attempt, preferring the API's `Retry-After` header over the
+// exponential backoff when it is present. It returns false if the API asked for a delay longer than `MaxRetryAfter`,
+// in which case the request shouldn't be retried.
+func (p *RetryPolicy) delay(attempt int, header http.Header) (time.Duration, bool) {
+	if after, ok := retryAfter(header); ok {
+		return after, after <= p.MaxRetryAfter
+	}
+
+	backoff := p.InitialDelay
+	for i := 1; i < attempt && backoff < p.MaxDelay; i++ {
+		backoff *= 2
+	}
+	if backoff > p.MaxDelay {
+		backoff = p.MaxDelay
+	}
+
+	// Equal jitter - wait for at least half of the backoff so that retries still back off under contention
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1)), true
+}
+
+func retryAfter(header http.Header) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		d := time.Until(at)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+
+	return 0, false
+}
+
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+}
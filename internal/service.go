@@ -8,28 +8,59 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/RedisLabs/rediscloud-go-api/apierrors"
+	"github.com/RedisLabs/rediscloud-go-api/logging"
+	"github.com/RedisLabs/rediscloud-go-api/metrics"
 	"github.com/RedisLabs/rediscloud-go-api/redis"
+	"github.com/RedisLabs/rediscloud-go-api/tracing"
 	"github.com/avast/retry-go"
 )
 
-type Log interface {
-	Println(v ...interface{})
+type api struct {
+	client    *HttpClient
+	logger    logging.Logger
+	polling   PollingPolicy
+	observers []TaskObserver
+	metrics   metrics.Recorder
+	tracer    tracing.Tracer
 }
 
-type api struct {
-	client *HttpClient
-	logger Log
+type APIOption func(*api)
+
+// WithPollingPolicy changes how tasks are polled - any zero values in the policy will keep their defaults.
+func WithPollingPolicy(policy PollingPolicy) APIOption {
+	return func(a *api) {
+		a.polling = a.polling.merge(policy)
+	}
+}
+
+// WithTaskMetrics reports every task that is waited on to the recorder, once the wait is over.
+func WithTaskMetrics(recorder metrics.Recorder) APIOption {
+	return func(a *api) {
+		a.metrics = recorder
+	}
+}
+
+// WithTaskTracer records a span for every task that is waited on, with a child span for every poll of the task.
+func WithTaskTracer(tracer tracing.Tracer) APIOption {
+	return func(a *api) {
+		a.tracer = tracer
+	}
 }
 
-func NewAPI(client *HttpClient, logger Log) *api {
-	return &api{client: client, logger: logger}
+func NewAPI(client *HttpClient, logger logging.Logger, options ...APIOption) *api {
+	a := &api{client: client, logger: logger, polling: defaultPollingPolicy, tracer: tracing.Noop}
+	for _, option := range options {
+		option(a)
+	}
+	return a
 }
 
 // WaitForResourceId will poll the task, waiting for the task to finish processing, where it will then return.
 // An error will be returned if the task couldn't be retrieved or the task was not processed successfully.
 //
-// The task will be continuously polled until the task either fails or succeeds - cancellation can be achieved
-// by cancelling the context.
+// The task will be continuously polled until the task either fails or succeeds, or the polling policy's timeout is
+// reached - cancellation can be achieved by cancelling the context.
 func (a *api) WaitForResourceId(ctx context.Context, id string) (int, error) {
 	task, err := a.waitForTaskToComplete(ctx, id)
 	if err != nil {
@@ -42,8 +73,8 @@ func (a *api) WaitForResourceId(ctx context.Context, id string) (int, error) {
 // Wait will poll the task, waiting for the task to finish processing, where it will then return.
 // An error will be returned if the task couldn't be retrieved or the task was not processed successfully.
 //
-// The task will be continuously polled until the task either fails or succeeds - cancellation can be achieved
-// by cancelling the context.
+// The task will be continuously polled until the task either fails or succeeds, or the polling policy's timeout is
+// reached - cancellation can be achieved by cancelling the context.
 func (a *api) Wait(ctx context.Context, id string) error {
 	_, err := a.waitForTaskToComplete(ctx, id)
 	if err != nil {
@@ -56,8 +87,8 @@ func (a *api) Wait(ctx context.Context, id string) error {
 // WaitForResource will poll the task, waiting for the task to finish processing, where it will then marshal the
 // returned resource into the value pointed to be `resource`.
 //
-// The task will be continuously polled until the task either fails or succeeds - cancellation can be achieved
-// by cancelling the context.
+// The task will be continuously polled until the task either fails or succeeds, or the polling policy's timeout is
+// reached - cancellation can be achieved by cancelling the context.
 func (a *api) WaitForResource(ctx context.Context, id string, resource interface{}) error {
 	task, err := a.waitForTaskToComplete(ctx, id)
 	if err != nil {
@@ -72,72 +103,137 @@ func (a *api) WaitForResource(ctx context.Context, id string, resource interface
 	return nil
 }
 
-func (a *api) waitForTaskToComplete(ctx context.Context, id string) (*task, error) {
+func (a *api) waitForTaskToComplete(ctx context.Context, id string) (_ *task, err error) {
+	policy := pollingPolicyFromContext(ctx, a.polling)
+
+	ctx, span := a.tracer.Start(ctx, "wait for task "+id, logging.KeyTaskID, id)
+
+	var commandType, status string
+	polls := 0
+	start := time.Now()
+	defer func() {
+		span.SetAttributes(tracing.KeyCommandType, commandType, logging.KeyStatus, status)
+		span.End(err)
+		a.record(commandType, status, start, polls, err)
+	}()
+
+	waitCtx := ctx
+	if policy.Timeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, policy.Timeout)
+		defer cancel()
+	}
+
+	tracker := newStatusTracker(ctx, a.observers)
+
 	var task *task
 	notFoundCount := 0
-	err := retry.Do(func() error {
+	err = retry.Do(func() error {
 		var err error
-		task, err = a.get(ctx, id)
+		polls++
+		pollCtx, poll := a.tracer.Start(waitCtx, "poll task "+id, logging.KeyTaskID, id)
+		task, err = a.get(pollCtx, id)
+		if task != nil {
+			tracker.observe(task)
+			commandType = redis.StringValue(task.CommandType)
+			status = redis.StringValue(task.Status)
+			poll.SetAttributes(logging.KeyStatus, status)
+		}
+		poll.End(err)
 		if err != nil {
 			if status, ok := err.(*HTTPError); ok && status.StatusCode == 404 {
 				return &taskNotFoundError{err}
 			}
+			if apiErr, ok := err.(*Error); ok {
+				return retry.Unrecoverable(newTaskError(ctx, id, task, apiErr))
+			}
 			return retry.Unrecoverable(err)
 		}
 
-		status := redis.StringValue(task.Status)
 		if status == processedState {
 			return nil
 		}
 
 		if _, ok := processingStates[status]; !ok {
-			return retry.Unrecoverable(fmt.Errorf("task %s failed %s - %s", id, status, redis.StringValue(task.Description)))
+			return retry.Unrecoverable(newTaskError(ctx, id, task, nil))
 		}
 
 		return fmt.Errorf("task %s not processed yet: %s", id, status)
 	},
-		retry.Attempts(math.MaxUint16), retry.Delay(1*time.Second), retry.MaxDelay(30*time.Second),
+		retry.Attempts(math.MaxUint16), retry.MaxDelay(policy.MaxDelay),
+		retry.DelayType(retry.CombineDelay(policy.delay, retry.RandomDelay)),
 		retry.RetryIf(func(err error) bool {
 			if !retry.IsRecoverable(err) {
 				return false
 			}
 			if _, ok := err.(*taskNotFoundError); ok {
 				notFoundCount++
-				if notFoundCount > max404Errors {
+				if notFoundCount > policy.notFoundTolerance() {
 					return false
 				}
 			}
 			return true
 		}),
-		retry.LastErrorOnly(true), retry.Context(ctx), retry.OnRetry(func(_ uint, err error) {
-			a.logger.Println(err)
+		retry.LastErrorOnly(true), retry.Context(waitCtx), retry.OnRetry(func(_ uint, err error) {
+			if _, ok := err.(*taskNotFoundError); ok {
+				a.logger.Log(ctx, logging.LevelDebug, "Task not found yet", logging.KeyTaskID, id, logging.KeyError, err)
+				return
+			}
+			a.logger.Log(ctx, logging.LevelDebug, "Task not processed yet", logging.KeyTaskID, id, logging.KeyStatus, status)
 		}))
 	if err != nil {
+		if ctx.Err() == nil && waitCtx.Err() != nil {
+			return nil, fmt.Errorf("timed out after %s waiting for task %s: %w", policy.Timeout, id, err)
+		}
 		return nil, err
 	}
 
 	return task, nil
 }
 
+func (a *api) record(commandType, status string, start time.Time, polls int, err error) {
+	if a.metrics == nil {
+		return
+	}
+	a.metrics.TaskCompleted(metrics.Task{
+		CommandType: commandType,
+		Status:      status,
+		Duration:    time.Since(start),
+		Polls:       polls,
+		Err:         err,
+	})
+}
+
+// get retrieves the current state of the task. This goes through the same HttpClient as every other request, so
+// polling draws from the same rate limit as the rest of the API calls rather than starving them.
+//
+// If the task reports an error then both the task and its error are returned.
 func (a *api) get(ctx context.Context, id string) (*task, error) {
 	var task task
 	if err := a.client.Get(ctx, fmt.Sprintf("retrieve task %s", id), "/tasks/"+url.PathEscape(id), &task); err != nil {
-		return nil, err
+		return nil, apierrors.WithResource(err, apierrors.ResourceTask, id)
 	}
 
 	if task.Response != nil && task.Response.Error != nil {
-		return nil, task.Response.Error
+		return &task, task.Response.Error
 	}
 
 	return &task, nil
 }
 
-// Number of 404 errors to swallow before returning an error while waiting for a task to finish.
-//
-// There's a short window between the API returning a task ID and the task being known by the
-// Task service, so by ignoring _a number_ of 404 errors we give the task service enough time to
-// learn about the task but also handle the situation where there really is no task.
-const max404Errors = 5
+func newTaskError(ctx context.Context, id string, task *task, err *Error) error {
+	taskErr := &apierrors.TaskError{
+		TaskID:        id,
+		CommandType:   redis.StringValue(task.CommandType),
+		Status:        redis.StringValue(task.Status),
+		Description:   redis.StringValue(task.Description),
+		CorrelationID: tracing.CorrelationID(ctx),
+	}
+	if err != nil {
+		taskErr.Err = err
+	}
+	return taskErr
+}
 
 var processingStates = map[string]bool{
 	"initialized":            true,
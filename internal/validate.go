@@ -0,0 +1,112 @@
+package internal
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+
+	"github.com/RedisLabs/rediscloud-go-api/apierrors"
+)
+
+// Validator collects the fields of a request that aren't valid, so they can all be reported at once.
+type Validator struct {
+	errors []apierrors.FieldError
+}
+
+// Fail records that the field at path isn't valid.
+func (v *Validator) Fail(path string, format string, args ...interface{}) {
+	v.errors = append(v.errors, apierrors.FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// Err returns a `*apierrors.ValidationError` with every failure, or nil if there were none.
+func (v *Validator) Err() error {
+	if len(v.errors) == 0 {
+		return nil
+	}
+	return &apierrors.ValidationError{Errors: v.errors}
+}
+
+// Required checks that the pointer is set, and that a string isn't empty.
+func (v *Validator) Required(path string, value interface{}) bool {
+	missing := false
+	switch p := value.(type) {
+	case *string:
+		missing = p == nil || *p == ""
+	case *int:
+		missing = p == nil
+	case *float64:
+		missing = p == nil
+	case *bool:
+		missing = p == nil
+	}
+	if missing {
+		v.Fail(path, "is required")
+	}
+	return !missing
+}
+
+// OneOf checks that the string, if set, is one of the allowed values.
+func (v *Validator) OneOf(path string, value *string, allowed []string) {
+	if value == nil {
+		return
+	}
+	for _, a := range allowed {
+		if *value == a {
+			return
+		}
+	}
+	v.Fail(path, "must be one of %q, not %q", allowed, *value)
+}
+
+// Positive checks that the number, if set, is greater than zero.
+func (v *Validator) Positive(path string, value interface{}) {
+	switch p := value.(type) {
+	case *int:
+		if p != nil && *p <= 0 {
+			v.Fail(path, "must be greater than 0, not %d", *p)
+		}
+	case *float64:
+		if p != nil && *p <= 0 {
+			v.Fail(path, "must be greater than 0, not %v", *p)
+		}
+	}
+}
+
+// CIDR checks that the string, if set, is an IP range such as `10.0.0.0/24`.
+func (v *Validator) CIDR(path string, value *string) {
+	if value == nil {
+		return
+	}
+	if _, _, err := net.ParseCIDR(*value); err != nil {
+		v.Fail(path, "must be a CIDR range such as 10.0.0.0/24, not %q", *value)
+	}
+}
+
+// CIDRs checks that each of the strings is an IP range.
+func (v *Validator) CIDRs(path string, values []*string) {
+	for i, value := range values {
+		if v.Required(Index(path, i), value) {
+			v.CIDR(Index(path, i), value)
+		}
+	}
+}
+
+// URI checks that the string, if set, is an absolute URI with a host, such as `redis://example.com:6379`.
+func (v *Validator) URI(path string, value *string) {
+	if value == nil {
+		return
+	}
+	if u, err := url.Parse(*value); err != nil || u.Scheme == "" || u.Host == "" {
+		v.Fail(path, "must be an absolute URI")
+	}
+}
+
+// Index returns the path of an item in a list, such as `regions[0]`.
+func Index(path string, i int) string {
+	return fmt.Sprintf("%s[%d]", path, i)
+}
+
+// Validatable is implemented by requests that can check themselves before being sent.
+type Validatable interface {
+	Validate() error
+}
@@ -0,0 +1,200 @@
+// Package logging defines the leveled, key/value logger that the client reports what it is doing to.
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is the severity of a logged event.
+type Level int
+
+const (
+	// LevelDebug is used for the requests and responses logged by `LogRequests` and for every poll of a task or busy
+	// subscription.
+	LevelDebug Level = iota
+	// LevelInfo is used for the lifecycle of an operation, such as starting to wait for its task.
+	LevelInfo
+	// LevelWarn is used when an operation has to be retried.
+	LevelWarn
+	// LevelError is used when something went wrong that can't be returned as an error.
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	}
+	return fmt.Sprintf("LEVEL(%d)", int(l))
+}
+
+// The keys of the values that the client attaches to its events.
+const (
+	KeyOperation      = "operation"
+	KeySubscriptionID = "subscriptionId"
+	KeyDatabaseID     = "databaseId"
+	KeyCloudAccountID = "cloudAccountId"
+	KeyPeeringID      = "peeringId"
+	KeyTaskID         = "taskId"
+	KeyCorrelationID  = "correlationId"
+	KeyStatus         = "status"
+	KeyError          = "error"
+)
+
+// Logger receives the events logged by the client. `keyvals` holds alternating keys and values, with the keys always
+// being strings.
+type Logger interface {
+	Log(ctx context.Context, level Level, message string, keyvals ...interface{})
+}
+
+// LoggerFunc allows a function to be used as a Logger.
+type LoggerFunc func(ctx context.Context, level Level, message string, keyvals ...interface{})
+
+func (f LoggerFunc) Log(ctx context.Context, level Level, message string, keyvals ...interface{}) {
+	f(ctx, level, message, keyvals...)
+}
+
+// Discard is a Logger that drops every event.
+var Discard Logger = LoggerFunc(func(context.Context, Level, string, ...interface{}) {})
+
+// Printf is the unstructured logger that the client used before Logger, which is implemented by `*log.Logger`.
+type Printf interface {
+	Printf(format string, v ...interface{})
+}
+
+// FromPrintf adapts an unstructured logger to a Logger, writing each event as its level, message and then its values,
+// e.g. `INFO: Waiting for task to finish operation="update database" subscriptionId=12 taskId=abc`.
+func FromPrintf(log Printf) Logger {
+	return LoggerFunc(func(_ context.Context, level Level, message string, keyvals ...interface{}) {
+		var sb strings.Builder
+		sb.WriteString(level.String())
+		sb.WriteString(": ")
+		sb.WriteString(message)
+		for i := 0; i < len(keyvals); i += 2 {
+			sb.WriteString(" ")
+			sb.WriteString(fmt.Sprint(keyvals[i]))
+			sb.WriteString("=")
+			sb.WriteString(formatValue(value(keyvals, i+1)))
+		}
+		log.Printf("%s", sb.String())
+	})
+}
+
+func formatValue(v interface{}) string {
+	if err, ok := v.(error); ok {
+		v = err.Error()
+	}
+	s := fmt.Sprint(v)
+	if strings.ContainsAny(s, " \t\n\"=") || s == "" {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
+
+// NewJSON returns a Logger that writes each event to `w` as a single line of JSON, with the `time`, `level` and
+// `message` followed by the event's values.
+func NewJSON(w io.Writer) Logger {
+	var mu sync.Mutex
+	return LoggerFunc(func(_ context.Context, level Level, message string, keyvals ...interface{}) {
+		event := map[string]interface{}{
+			"time":    time.Now().UTC().Format(time.RFC3339Nano),
+			"level":   strings.ToLower(level.String()),
+			"message": message,
+		}
+		for i := 0; i < len(keyvals); i += 2 {
+			v := value(keyvals, i+1)
+			if err, ok := v.(error); ok {
+				v = err.Error()
+			}
+			event[fmt.Sprint(keyvals[i])] = v
+		}
+
+		data, err := json.Marshal(event)
+		if err != nil {
+			data, _ = json.Marshal(map[string]interface{}{"level": "error", "message": "failed to encode log event", KeyError: err.Error()})
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		_, _ = w.Write(append(data, '\n'))
+	})
+}
+
+// MinLevel returns a Logger that only passes the events of at least `level` on to `logger`, e.g. to drop the
+// debug-level polling of tasks.
+func MinLevel(logger Logger, level Level) Logger {
+	return LoggerFunc(func(ctx context.Context, l Level, message string, keyvals ...interface{}) {
+		if l >= level {
+			logger.Log(ctx, l, message, keyvals...)
+		}
+	})
+}
+
+type fieldsKey struct{}
+
+// ContextWithFields returns a context that adds the keys and values to every event logged for a call made with it.
+// A key that is already in the context keeps its value, so that the outermost call names the operation.
+func ContextWithFields(ctx context.Context, keyvals ...interface{}) context.Context {
+	return context.WithValue(ctx, fieldsKey{}, merge(Fields(ctx), keyvals, false))
+}
+
+// Fields returns the keys and values added to the context by ContextWithFields.
+func Fields(ctx context.Context) []interface{} {
+	fields, _ := ctx.Value(fieldsKey{}).([]interface{})
+	return fields
+}
+
+// WithContextFields returns a Logger that adds the fields from the event's context ahead of the event's own values.
+func WithContextFields(logger Logger) Logger {
+	return LoggerFunc(func(ctx context.Context, level Level, message string, keyvals ...interface{}) {
+		logger.Log(ctx, level, message, merge(Fields(ctx), keyvals, true)...)
+	})
+}
+
+// merge returns the keys and values of `base` followed by those of `extra`. A key in both keeps its position from
+// `base`, and takes its value from `extra` only if `replace` is set.
+func merge(base []interface{}, extra []interface{}, replace bool) []interface{} {
+	if len(base) == 0 && len(extra)%2 == 0 {
+		return extra
+	}
+
+	merged := make([]interface{}, 0, len(base)+len(extra)+1)
+	for n, keyvals := range [][]interface{}{base, extra} {
+		for i := 0; i < len(keyvals); i += 2 {
+			found := false
+			for j := 0; j < len(merged); j += 2 {
+				if fmt.Sprint(merged[j]) == fmt.Sprint(keyvals[i]) {
+					if n == 0 || replace {
+						merged[j+1] = value(keyvals, i+1)
+					}
+					found = true
+					break
+				}
+			}
+			if !found {
+				merged = append(merged, keyvals[i], value(keyvals, i+1))
+			}
+		}
+	}
+	return merged
+}
+
+// value returns the value at `i`, allowing for an odd number of keys and values.
+func value(keyvals []interface{}, i int) interface{} {
+	if i < len(keyvals) {
+		return keyvals[i]
+	}
+	return nil
+}
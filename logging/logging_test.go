@@ -0,0 +1,90 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type printfLogger struct {
+	lines []string
+}
+
+func (p *printfLogger) Printf(format string, v ...interface{}) {
+	p.lines = append(p.lines, fmt.Sprintf(format, v...))
+}
+
+func TestFromPrintf(t *testing.T) {
+	log := &printfLogger{}
+	subject := FromPrintf(log)
+
+	subject.Log(context.TODO(), LevelInfo, "Waiting for task to finish", KeyOperation, "update database", KeySubscriptionID, 12, KeyTaskID, "abc")
+	subject.Log(context.TODO(), LevelWarn, "Retrying", KeyError, errors.New("subscription is busy"), "empty", "")
+	subject.Log(context.TODO(), LevelDebug, "Odd", "key")
+	subject.Log(context.TODO(), LevelDebug, "100%")
+
+	assert.Equal(t, []string{
+		`INFO: Waiting for task to finish operation="update database" subscriptionId=12 taskId=abc`,
+		`WARN: Retrying error="subscription is busy" empty=""`,
+		`DEBUG: Odd key=<nil>`,
+		`DEBUG: 100%`,
+	}, log.lines)
+}
+
+func TestNewJSON(t *testing.T) {
+	var buf bytes.Buffer
+	subject := NewJSON(&buf)
+
+	subject.Log(context.TODO(), LevelError, "Failed", KeyDatabaseID, 3, KeyError, errors.New("boom"))
+
+	var event map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &event))
+	assert.NotEmpty(t, event["time"])
+	delete(event, "time")
+	assert.Equal(t, map[string]interface{}{
+		"level":       "error",
+		"message":     "Failed",
+		KeyDatabaseID: 3.0,
+		KeyError:      "boom",
+	}, event)
+}
+
+func TestMinLevel(t *testing.T) {
+	var messages []string
+	subject := MinLevel(LoggerFunc(func(_ context.Context, _ Level, message string, _ ...interface{}) {
+		messages = append(messages, message)
+	}), LevelInfo)
+
+	subject.Log(context.TODO(), LevelDebug, "debug")
+	subject.Log(context.TODO(), LevelInfo, "info")
+	subject.Log(context.TODO(), LevelError, "error")
+
+	assert.Equal(t, []string{"info", "error"}, messages)
+}
+
+func TestWithContextFields(t *testing.T) {
+	var keyvals []interface{}
+	subject := WithContextFields(LoggerFunc(func(_ context.Context, _ Level, _ string, kv ...interface{}) {
+		keyvals = kv
+	}))
+
+	ctx := ContextWithFields(context.TODO(), KeyOperation, "patch database", KeySubscriptionID, 12)
+	ctx = ContextWithFields(ctx, KeyOperation, "update database", KeyDatabaseID, 3)
+	subject.Log(ctx, LevelInfo, "Waiting", KeyTaskID, "abc", KeySubscriptionID, 13)
+
+	assert.Equal(t, []interface{}{
+		KeyOperation, "patch database",
+		KeySubscriptionID, 13,
+		KeyDatabaseID, 3,
+		KeyTaskID, "abc",
+	}, keyvals)
+
+	subject.Log(context.TODO(), LevelInfo, "No fields")
+	assert.Empty(t, keyvals)
+}
@@ -0,0 +1,69 @@
+// Package metrics defines the hooks that the client reports its requests and task waits to, along with a Prometheus
+// exporter for them.
+package metrics
+
+import (
+	"strings"
+	"time"
+)
+
+// Recorder is notified of every request made to the API, including each retry and every poll of a task, and of
+// every task that the client waited on. It is called from the goroutine making the request, so must be safe for
+// concurrent use.
+type Recorder interface {
+	RequestCompleted(request Request)
+	TaskCompleted(task Task)
+}
+
+// Request describes a single attempt at a request to the API.
+type Request struct {
+	Method string
+	// Route is the path of the request with the identifiers replaced by `{id}`, e.g. `/subscriptions/{id}/databases`.
+	Route string
+	// StatusCode is zero if no response was received.
+	StatusCode int
+	Duration   time.Duration
+	// Attempt starts at 1 and is increased for every retry of the same request.
+	Attempt int
+	// Err is set if no response was received, or the response couldn't be decoded, but not for unsuccessful status
+	// codes.
+	Err error
+}
+
+// Task describes the client waiting for a task to finish.
+type Task struct {
+	CommandType string
+	// Status is the last status the task was seen with, e.g. `processing-completed`, which is empty if the task was
+	// never retrieved.
+	Status   string
+	Duration time.Duration
+	// Polls is the number of times the task was retrieved.
+	Polls int
+	// Err is set if the task didn't complete successfully, including the wait timing out.
+	Err error
+}
+
+// Route replaces the identifiers in the path of a request with `{id}`, so that requests for different resources of
+// the same type are grouped together, e.g. `/subscriptions/12/databases/3` becomes `/subscriptions/{id}/databases/{id}`.
+// The numeric segments and the segment following `tasks` are identifiers.
+func Route(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if segment == "" {
+			continue
+		}
+		if isNumeric(segment) || (i > 0 && segments[i-1] == "tasks") {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func isNumeric(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoute(t *testing.T) {
+	for path, expected := range map[string]string{
+		"/subscriptions":                          "/subscriptions",
+		"/subscriptions/12":                       "/subscriptions/{id}",
+		"/subscriptions/12/databases":             "/subscriptions/{id}/databases",
+		"/subscriptions/12/databases/3/backup":    "/subscriptions/{id}/databases/{id}/backup",
+		"/subscriptions/12/peerings/7":            "/subscriptions/{id}/peerings/{id}",
+		"/tasks":                                  "/tasks",
+		"/tasks/e02b40d6-dde4-4f5b-8b6f-5a1a2b3c": "/tasks/{id}",
+		"/cloud-accounts/4":                       "/cloud-accounts/{id}",
+	} {
+		assert.Equal(t, expected, Route(path), path)
+	}
+}
+
+func TestPrometheus_WriteTo(t *testing.T) {
+	subject := NewPrometheus()
+
+	subject.RequestCompleted(Request{Method: "GET", Route: "/subscriptions/{id}", StatusCode: 200, Duration: 40 * time.Millisecond})
+	subject.RequestCompleted(Request{Method: "GET", Route: "/subscriptions/{id}", StatusCode: 200, Duration: 210 * time.Millisecond})
+	subject.RequestCompleted(Request{Method: "PUT", Route: "/subscriptions/{id}/databases/{id}", StatusCode: 409, Duration: time.Second})
+	subject.RequestCompleted(Request{Method: "GET", Route: "/tasks/{id}", Duration: 30 * time.Second, Err: errors.New("timeout")})
+	subject.TaskCompleted(Task{CommandType: "databaseUpdateRequest", Status: "processing-completed", Duration: 90 * time.Second, Polls: 4})
+
+	var buf bytes.Buffer
+	n, err := subject.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+
+	assert.Equal(t, `# HELP rediscloud_requests_total Requests made to the Redis Cloud API, by method, route and status code.
+# TYPE rediscloud_requests_total counter
+rediscloud_requests_total{code="200",method="GET",route="/subscriptions/{id}"} 2
+rediscloud_requests_total{code="409",method="PUT",route="/subscriptions/{id}/databases/{id}"} 1
+rediscloud_requests_total{code="error",method="GET",route="/tasks/{id}"} 1
+# HELP rediscloud_request_duration_seconds Duration of the requests made to the Redis Cloud API, by method and route.
+# TYPE rediscloud_request_duration_seconds histogram
+rediscloud_request_duration_seconds_bucket{method="GET",route="/subscriptions/{id}",le="0.05"} 1
+rediscloud_request_duration_seconds_bucket{method="GET",route="/subscriptions/{id}",le="0.1"} 1
+rediscloud_request_duration_seconds_bucket{method="GET",route="/subscriptions/{id}",le="0.25"} 2
+rediscloud_request_duration_seconds_bucket{method="GET",route="/subscriptions/{id}",le="0.5"} 2
+rediscloud_request_duration_seconds_bucket{method="GET",route="/subscriptions/{id}",le="1"} 2
+rediscloud_request_duration_seconds_bucket{method="GET",route="/subscriptions/{id}",le="2.5"} 2
+rediscloud_request_duration_seconds_bucket{method="GET",route="/subscriptions/{id}",le="5"} 2
+rediscloud_request_duration_seconds_bucket{method="GET",route="/subscriptions/{id}",le="10"} 2
+rediscloud_request_duration_seconds_bucket{method="GET",route="/subscriptions/{id}",le="30"} 2
+rediscloud_request_duration_seconds_bucket{method="GET",route="/subscriptions/{id}",le="+Inf"} 2
+rediscloud_request_duration_seconds_sum{method="GET",route="/subscriptions/{id}"} 0.25
+rediscloud_request_duration_seconds_count{method="GET",route="/subscriptions/{id}"} 2
+rediscloud_request_duration_seconds_bucket{method="GET",route="/tasks/{id}",le="0.05"} 0
+rediscloud_request_duration_seconds_bucket{method="GET",route="/tasks/{id}",le="0.1"} 0
+rediscloud_request_duration_seconds_bucket{method="GET",route="/tasks/{id}",le="0.25"} 0
+rediscloud_request_duration_seconds_bucket{method="GET",route="/tasks/{id}",le="0.5"} 0
+rediscloud_request_duration_seconds_bucket{method="GET",route="/tasks/{id}",le="1"} 0
+rediscloud_request_duration_seconds_bucket{method="GET",route="/tasks/{id}",le="2.5"} 0
+rediscloud_request_duration_seconds_bucket{method="GET",route="/tasks/{id}",le="5"} 0
+rediscloud_request_duration_seconds_bucket{method="GET",route="/tasks/{id}",le="10"} 0
+rediscloud_request_duration_seconds_bucket{method="GET",route="/tasks/{id}",le="30"} 1
+rediscloud_request_duration_seconds_bucket{method="GET",route="/tasks/{id}",le="+Inf"} 1
+rediscloud_request_duration_seconds_sum{method="GET",route="/tasks/{id}"} 30
+rediscloud_request_duration_seconds_count{method="GET",route="/tasks/{id}"} 1
+rediscloud_request_duration_seconds_bucket{method="PUT",route="/subscriptions/{id}/databases/{id}",le="0.05"} 0
+rediscloud_request_duration_seconds_bucket{method="PUT",route="/subscriptions/{id}/databases/{id}",le="0.1"} 0
+rediscloud_request_duration_seconds_bucket{method="PUT",route="/subscriptions/{id}/databases/{id}",le="0.25"} 0
+rediscloud_request_duration_seconds_bucket{method="PUT",route="/subscriptions/{id}/databases/{id}",le="0.5"} 0
+rediscloud_request_duration_seconds_bucket{method="PUT",route="/subscriptions/{id}/databases/{id}",le="1"} 1
+rediscloud_request_duration_seconds_bucket{method="PUT",route="/subscriptions/{id}/databases/{id}",le="2.5"} 1
+rediscloud_request_duration_seconds_bucket{method="PUT",route="/subscriptions/{id}/databases/{id}",le="5"} 1
+rediscloud_request_duration_seconds_bucket{method="PUT",route="/subscriptions/{id}/databases/{id}",le="10"} 1
+rediscloud_request_duration_seconds_bucket{method="PUT",route="/subscriptions/{id}/databases/{id}",le="30"} 1
+rediscloud_request_duration_seconds_bucket{method="PUT",route="/subscriptions/{id}/databases/{id}",le="+Inf"} 1
+rediscloud_request_duration_seconds_sum{method="PUT",route="/subscriptions/{id}/databases/{id}"} 1
+rediscloud_request_duration_seconds_count{method="PUT",route="/subscriptions/{id}/databases/{id}"} 1
+# HELP rediscloud_task_wait_duration_seconds Time spent waiting for tasks to finish, by command type and final status.
+# TYPE rediscloud_task_wait_duration_seconds histogram
+rediscloud_task_wait_duration_seconds_bucket{command_type="databaseUpdateRequest",status="processing-completed",le="1"} 0
+rediscloud_task_wait_duration_seconds_bucket{command_type="databaseUpdateRequest",status="processing-completed",le="5"} 0
+rediscloud_task_wait_duration_seconds_bucket{command_type="databaseUpdateRequest",status="processing-completed",le="15"} 0
+rediscloud_task_wait_duration_seconds_bucket{command_type="databaseUpdateRequest",status="processing-completed",le="30"} 0
+rediscloud_task_wait_duration_seconds_bucket{command_type="databaseUpdateRequest",status="processing-completed",le="60"} 0
+rediscloud_task_wait_duration_seconds_bucket{command_type="databaseUpdateRequest",status="processing-completed",le="120"} 1
+rediscloud_task_wait_duration_seconds_bucket{command_type="databaseUpdateRequest",status="processing-completed",le="300"} 1
+rediscloud_task_wait_duration_seconds_bucket{command_type="databaseUpdateRequest",status="processing-completed",le="600"} 1
+rediscloud_task_wait_duration_seconds_bucket{command_type="databaseUpdateRequest",status="processing-completed",le="1200"} 1
+rediscloud_task_wait_duration_seconds_bucket{command_type="databaseUpdateRequest",status="processing-completed",le="1800"} 1
+rediscloud_task_wait_duration_seconds_bucket{command_type="databaseUpdateRequest",status="processing-completed",le="3600"} 1
+rediscloud_task_wait_duration_seconds_bucket{command_type="databaseUpdateRequest",status="processing-completed",le="+Inf"} 1
+rediscloud_task_wait_duration_seconds_sum{command_type="databaseUpdateRequest",status="processing-completed"} 90
+rediscloud_task_wait_duration_seconds_count{command_type="databaseUpdateRequest",status="processing-completed"} 1
+`, buf.String())
+}
+
+func TestPrometheus_ServeHTTP(t *testing.T) {
+	subject := NewPrometheus()
+	subject.RequestCompleted(Request{Method: "GET", Route: `/odd"route`, StatusCode: 200})
+
+	w := httptest.NewRecorder()
+	subject.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.True(t, strings.Contains(w.Body.String(), `rediscloud_requests_total{code="200",method="GET",route="/odd\"route"} 1`), w.Body.String())
+}
@@ -0,0 +1,194 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are the upper bounds, in seconds, of the histogram buckets for the duration of requests.
+func DefaultBuckets() []float64 {
+	return []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+}
+
+// DefaultTaskBuckets are the upper bounds, in seconds, of the histogram buckets for the time spent waiting on tasks,
+// which can take anything from seconds for a database update to tens of minutes for a new subscription.
+func DefaultTaskBuckets() []float64 {
+	return []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200, 1800, 3600}
+}
+
+// Prometheus is a Recorder that keeps the metrics in memory and writes them in the Prometheus text exposition format,
+// either with WriteTo or by serving them as an http.Handler. The metrics are:
+//
+//	rediscloud_requests_total{code, method, route}               counter
+//	rediscloud_request_duration_seconds{method, route}           histogram
+//	rediscloud_task_wait_duration_seconds{command_type, status}  histogram
+//
+// where `code` is `error` for a request that received no response.
+type Prometheus struct {
+	mu       sync.Mutex
+	requests map[string]float64
+	latency  *histogram
+	tasks    *histogram
+}
+
+// NewPrometheus creates a Prometheus recorder with the default buckets.
+func NewPrometheus() *Prometheus {
+	return &Prometheus{
+		requests: map[string]float64{},
+		latency:  newHistogram(DefaultBuckets()),
+		tasks:    newHistogram(DefaultTaskBuckets()),
+	}
+}
+
+func (p *Prometheus) RequestCompleted(request Request) {
+	code := "error"
+	if request.StatusCode != 0 {
+		code = strconv.Itoa(request.StatusCode)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requests[labels("code", code, "method", request.Method, "route", request.Route)]++
+	p.latency.observe(labels("method", request.Method, "route", request.Route), request.Duration.Seconds())
+}
+
+func (p *Prometheus) TaskCompleted(task Task) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tasks.observe(labels("command_type", task.CommandType, "status", task.Status), task.Duration.Seconds())
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format.
+func (p *Prometheus) WriteTo(w io.Writer) (int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+
+	fmt.Fprintln(cw, "# HELP rediscloud_requests_total Requests made to the Redis Cloud API, by method, route and status code.")
+	fmt.Fprintln(cw, "# TYPE rediscloud_requests_total counter")
+	for _, key := range sortedKeys(p.requests) {
+		fmt.Fprintf(cw, "rediscloud_requests_total{%s} %s\n", key, formatFloat(p.requests[key]))
+	}
+
+	fmt.Fprintln(cw, "# HELP rediscloud_request_duration_seconds Duration of the requests made to the Redis Cloud API, by method and route.")
+	fmt.Fprintln(cw, "# TYPE rediscloud_request_duration_seconds histogram")
+	p.latency.write(cw, "rediscloud_request_duration_seconds")
+
+	fmt.Fprintln(cw, "# HELP rediscloud_task_wait_duration_seconds Time spent waiting for tasks to finish, by command type and final status.")
+	fmt.Fprintln(cw, "# TYPE rediscloud_task_wait_duration_seconds histogram")
+	p.tasks.write(cw, "rediscloud_task_wait_duration_seconds")
+
+	if err := cw.w.Flush(); err != nil {
+		return cw.n, err
+	}
+	return cw.n, cw.err
+}
+
+// ServeHTTP serves the metrics, so that the recorder can be registered as a `/metrics` endpoint.
+func (p *Prometheus) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = p.WriteTo(w)
+}
+
+type histogram struct {
+	bounds []float64
+	series map[string]*series
+}
+
+type series struct {
+	buckets []uint64
+	sum     float64
+	count   uint64
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{bounds: bounds, series: map[string]*series{}}
+}
+
+func (h *histogram) observe(key string, value float64) {
+	s, ok := h.series[key]
+	if !ok {
+		s = &series{buckets: make([]uint64, len(h.bounds))}
+		h.series[key] = s
+	}
+	for i, bound := range h.bounds {
+		if value <= bound {
+			s.buckets[i]++
+		}
+	}
+	s.sum += value
+	s.count++
+}
+
+func (h *histogram) write(w io.Writer, name string) {
+	keys := make([]string, 0, len(h.series))
+	for key := range h.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s := h.series[key]
+		for i, bound := range h.bounds {
+			fmt.Fprintf(w, "%s_bucket{%s,le=%q} %d\n", name, key, formatFloat(bound), s.buckets[i])
+		}
+		fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, key, s.count)
+		fmt.Fprintf(w, "%s_sum{%s} %s\n", name, key, formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count{%s} %d\n", name, key, s.count)
+	}
+}
+
+// labels formats alternating names and values as the labels of a sample, e.g. `method="GET",route="/subscriptions"`.
+// The names must be given in sorted order so that the same labels always give the same key.
+func labels(namesAndValues ...string) string {
+	pairs := make([]string, 0, len(namesAndValues)/2)
+	for i := 0; i+1 < len(namesAndValues); i += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, namesAndValues[i], escapeLabel(namesAndValues[i+1])))
+	}
+	return strings.Join(pairs, ",")
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func sortedKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
+
+var _ Recorder = &Prometheus{}
+var _ http.Handler = &Prometheus{}
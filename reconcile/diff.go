@@ -0,0 +1,134 @@
+package reconcile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/RedisLabs/rediscloud-go-api/redis"
+	"github.com/RedisLabs/rediscloud-go-api/service/databases"
+	"github.com/RedisLabs/rediscloud-go-api/service/subscriptions"
+)
+
+// Shown in place of the value of a sensitive field, such as a password.
+const sensitive = "(sensitive)"
+
+// diffDatabase compares the fields set in the desired database with the live database, using the same comparison as
+// `databases.CompareCreate`, returning an update with only the fields that need to change. Fields that the API can't
+// change return an error instead.
+func diffDatabase(desired *databases.CreateDatabase, live *databases.Database) (databases.UpdateDatabase, []Change, error) {
+	var update databases.UpdateDatabase
+	var changes []Change
+
+	for _, difference := range databases.CompareCreate(live, *desired) {
+		// A sensitive value, such as the password, can only be compared if the API returned it
+		if difference.Sensitive && difference.Live == nil {
+			continue
+		}
+
+		switch field := strings.SplitN(difference.Path, ".", 2)[0]; {
+		case field == "protocol" || field == "modules":
+			return update, nil, fmt.Errorf("%s can't be changed from %s to %s", field, databases.FormatValue(difference.Live), databases.FormatValue(difference.Desired))
+		case field == "memoryLimitInGb":
+			update.MemoryLimitInGB = desired.MemoryLimitInGB
+		case field == "supportOSSClusterApi":
+			update.SupportOSSClusterAPI = desired.SupportOSSClusterAPI
+		case field == "dataPersistence":
+			update.DataPersistence = desired.DataPersistence
+		case field == "dataEvictionPolicy":
+			update.DataEvictionPolicy = desired.DataEvictionPolicy
+		case field == "replication":
+			update.Replication = desired.Replication
+		case field == "throughputMeasurement":
+			update.ThroughputMeasurement = &databases.UpdateThroughputMeasurement{
+				By:    desired.ThroughputMeasurement.By,
+				Value: desired.ThroughputMeasurement.Value,
+			}
+		case field == "replicaOf":
+			update.ReplicaOf = desired.ReplicaOf
+		case difference.Path == "security.sourceIps":
+			update.SourceIP = desired.SourceIP
+		case difference.Path == "security.sslClientAuthentication":
+			update.ClientSSLCertificate = desired.ClientSSLCertificate
+		case difference.Path == "security.password":
+			update.Password = desired.Password
+		case field == "alerts":
+			update.Alerts = []*databases.UpdateAlert{}
+			for _, alert := range desired.Alerts {
+				update.Alerts = append(update.Alerts, &databases.UpdateAlert{Name: alert.Name, Value: alert.Value})
+			}
+		default:
+			// The database was found by its name, so there is nothing else that can differ
+			continue
+		}
+
+		change := Change{Field: difference.Path, From: databases.FormatValue(difference.Live), To: databases.FormatValue(difference.Desired)}
+		if difference.Sensitive {
+			change.From, change.To = sensitive, sensitive
+		}
+		changes = append(changes, change)
+	}
+
+	return update, changes, nil
+}
+
+func diffCIDRAllowlist(desired *subscriptions.UpdateCIDRAllowlist, live *subscriptions.CIDRAllowlist) []Change {
+	var changes []Change
+
+	want, have := redis.StringSliceValue(desired.CIDRIPs...), redis.StringSliceValue(live.CIDRIPs...)
+	if !equalSets(want, have) {
+		changes = append(changes, Change{Field: "cidrIps", From: formatList(have), To: formatList(want)})
+	}
+
+	want, have = redis.StringSliceValue(desired.SecurityGroupIDs...), redis.StringSliceValue(live.SecurityGroupIDs...)
+	if !equalSets(want, have) {
+		changes = append(changes, Change{Field: "securityGroupIds", From: formatList(have), To: formatList(want)})
+	}
+
+	return changes
+}
+
+func equalSets(a []string, b []string) bool {
+	set := map[string]int{}
+	for _, item := range a {
+		set[item]++
+	}
+	for _, item := range b {
+		set[item]--
+	}
+	for _, count := range set {
+		if count != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// format returns the value that a pointer points to, or `(none)` if it is nil.
+func format(value interface{}) string {
+	switch v := value.(type) {
+	case *string:
+		if v != nil {
+			return *v
+		}
+	case *int:
+		if v != nil {
+			return fmt.Sprint(*v)
+		}
+	case *float64:
+		if v != nil {
+			return fmt.Sprint(*v)
+		}
+	case *bool:
+		if v != nil {
+			return fmt.Sprint(*v)
+		}
+	}
+	return "(none)"
+}
+
+func formatList(values []string) string {
+	sorted := append([]string{}, values...)
+	sort.Strings(sorted)
+	return "[" + strings.Join(sorted, ", ") + "]"
+}
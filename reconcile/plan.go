@@ -0,0 +1,158 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/RedisLabs/rediscloud-go-api/service/databases"
+	"github.com/RedisLabs/rediscloud-go-api/service/subscriptions"
+)
+
+type ActionType string
+
+const (
+	Create ActionType = "create"
+	Update ActionType = "update"
+	Delete ActionType = "delete"
+)
+
+var actionSymbols = map[ActionType]string{
+	Create: "+",
+	Update: "~",
+	Delete: "-",
+}
+
+const (
+	ResourceSubscription  = "subscription"
+	ResourceDatabase      = "database"
+	ResourceCIDRAllowlist = "CIDR allowlist"
+	ResourceVPCPeering    = "VPC peering"
+)
+
+// Action is a single change in a plan.
+type Action struct {
+	Type     ActionType
+	Resource string
+	// Name of the database, or the account and VPC (or project and network) of the VPC peering, being changed.
+	Name    string
+	Changes []Change
+
+	ref   *subscriptionRef
+	apply func(ctx context.Context) error
+}
+
+// Subscription returns the name of the subscription being changed.
+func (a Action) Subscription() string {
+	return a.ref.name
+}
+
+func (a Action) String() string {
+	var b strings.Builder
+	b.WriteString(actionSymbols[a.Type] + " " + a.summary())
+	for _, change := range a.Changes {
+		b.WriteString("\n    " + change.String())
+	}
+	return b.String()
+}
+
+// summary describes the action in a single line, such as `update database "cache" in subscription "production"`.
+func (a Action) summary() string {
+	switch a.Resource {
+	case ResourceSubscription:
+		return fmt.Sprintf("%s %s %q", a.Type, a.Resource, a.ref.name)
+	case ResourceCIDRAllowlist:
+		return fmt.Sprintf("%s %s of subscription %q", a.Type, a.Resource, a.ref.name)
+	default:
+		return fmt.Sprintf("%s %s %q in subscription %q", a.Type, a.Resource, a.Name, a.ref.name)
+	}
+}
+
+// Change describes how a single field is changed by an action.
+type Change struct {
+	Field string
+	From  string
+	To    string
+}
+
+func (c Change) String() string {
+	if c.From == "" {
+		return fmt.Sprintf("%s: %s", c.Field, c.To)
+	}
+	return fmt.Sprintf("%s: %s -> %s", c.Field, c.From, c.To)
+}
+
+// Plan is the list of actions needed to reach the desired state, in the order they will be applied.
+type Plan struct {
+	Actions []Action
+}
+
+// Empty reports whether the live state already matches the desired state.
+func (p *Plan) Empty() bool {
+	return len(p.Actions) == 0
+}
+
+func (p *Plan) String() string {
+	if p.Empty() {
+		return "No changes.\n"
+	}
+
+	counts := map[ActionType]int{}
+	var b strings.Builder
+	for _, action := range p.Actions {
+		counts[action.Type]++
+		b.WriteString(action.String() + "\n")
+	}
+	b.WriteString(fmt.Sprintf("\nPlan: %d to create, %d to update, %d to delete.\n", counts[Create], counts[Update], counts[Delete]))
+	return b.String()
+}
+
+// Apply applies each of the actions in order, waiting for each to complete before starting the next. It stops at the
+// first action that fails, returning an `*ApplyError`, so no later actions - such as deleting a subscription whose
+// databases couldn't be deleted - are applied.
+func (p *Plan) Apply(ctx context.Context) error {
+	for i, action := range p.Actions {
+		if err := action.apply(ctx); err != nil {
+			return &ApplyError{Action: action, Applied: i, Remaining: len(p.Actions) - i - 1, Err: err}
+		}
+	}
+	return nil
+}
+
+// ApplyError is returned when an action in a plan fails.
+type ApplyError struct {
+	// Action that failed.
+	Action Action
+	// Applied is the number of actions that were applied before the failure.
+	Applied int
+	// Remaining is the number of actions after the failed action that weren't applied.
+	Remaining int
+	Err       error
+}
+
+func (e *ApplyError) Error() string {
+	return fmt.Sprintf("failed to %s after applying %d actions, leaving %d more unapplied: %s",
+		e.Action.summary(), e.Applied, e.Remaining, e.Err)
+}
+
+func (e *ApplyError) Unwrap() error {
+	return e.Err
+}
+
+// sortedNames returns the keys of a map of databases or peerings in order, so that plans are stable.
+func sortedNames(m interface{}) []string {
+	var names []string
+	switch v := m.(type) {
+	case map[string]*databases.Database:
+		for name := range v {
+			names = append(names, name)
+		}
+	case map[string]*subscriptions.VPCPeering:
+		for name := range v {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,492 @@
+// Package reconcile compares a document describing the desired state of the subscriptions in an account - including
+// their databases, CIDR allowlists and VPC peerings - with their live state, producing a plan of the changes needed
+// to make the live state match, which can then be reviewed and applied:
+//
+//	plan, err := reconcile.New(client).Plan(ctx, desired)
+//	...
+//	fmt.Print(plan)
+//	err = plan.Apply(ctx)
+//
+// Subscriptions and databases are identified by their names, so these must be unique. Subscriptions and databases
+// that aren't in the document are left alone, unless the `Prune` option is given to delete them.
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	rediscloud_api "github.com/RedisLabs/rediscloud-go-api"
+	"github.com/RedisLabs/rediscloud-go-api/redis"
+	"github.com/RedisLabs/rediscloud-go-api/service/databases"
+	"github.com/RedisLabs/rediscloud-go-api/service/subscriptions"
+)
+
+// State is the desired state of the subscriptions in an account.
+type State struct {
+	Subscriptions []*Subscription `json:"subscriptions"`
+}
+
+// Subscription is the desired state of a subscription, identified by its name.
+type Subscription struct {
+	Name                        string                               `json:"name"`
+	PaymentMethodID             *int                                 `json:"paymentMethodId,omitempty"`
+	MemoryStorage               *string                              `json:"memoryStorage,omitempty"`
+	PersistentStorageEncryption *bool                                `json:"persistentStorageEncryption,omitempty"`
+	CloudProviders              []*subscriptions.CreateCloudProvider `json:"cloudProviders,omitempty"`
+	// Databases in the subscription, identified by their names.
+	Databases []*databases.CreateDatabase `json:"databases,omitempty"`
+	// CIDRAllowlist of the subscription - will be left unchanged if nil.
+	CIDRAllowlist *subscriptions.UpdateCIDRAllowlist `json:"cidrAllowlist,omitempty"`
+	// Peerings of the subscription, identified by the VPC (AWS) or network (GCP) being peered with - will be left
+	// unchanged if nil. Peerings that aren't listed are only deleted with the `Prune` option.
+	Peerings []*subscriptions.CreateVPCPeering `json:"peerings,omitempty"`
+}
+
+// Reconciler plans and applies the changes needed to reach a desired state.
+type Reconciler struct {
+	client *rediscloud_api.Client
+	prune  bool
+}
+
+type Option func(*Reconciler)
+
+// Prune deletes the subscriptions, and the databases of the subscriptions in the document, that aren't in the
+// document - will default to false, leaving them alone.
+func Prune(enable bool) Option {
+	return func(r *Reconciler) {
+		r.prune = enable
+	}
+}
+
+func New(client *rediscloud_api.Client, options ...Option) *Reconciler {
+	r := &Reconciler{client: client}
+	for _, option := range options {
+		option(r)
+	}
+	return r
+}
+
+// subscriptionRef refers to a subscription that may not have been created yet, so that the actions for a new
+// subscription can use its identifier once the action creating it has been applied.
+type subscriptionRef struct {
+	name string
+	id   int
+}
+
+// Plan fetches the live state and returns the actions needed to make it match the desired state, in the order they
+// need to be applied - creates and updates first, then deletes, with the databases in a subscription deleted before
+// the subscription itself. A desired state without any subscriptions is rejected, as it's more likely to be a mistake
+// than a request to change nothing.
+func (r *Reconciler) Plan(ctx context.Context, desired State) (*Plan, error) {
+	if err := validate(desired); err != nil {
+		return nil, err
+	}
+
+	live, err := r.client.Subscription.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	liveByName := map[string]*subscriptions.Subscription{}
+	for _, sub := range live {
+		if redis.StringValue(sub.Status) == subscriptions.SubscriptionStatusDeleting {
+			continue
+		}
+		name := redis.StringValue(sub.Name)
+		if _, ok := liveByName[name]; ok {
+			return nil, fmt.Errorf("there are multiple subscriptions named %q", name)
+		}
+		liveByName[name] = sub
+	}
+
+	var p phases
+	for _, sub := range desired.Subscriptions {
+		liveSub, ok := liveByName[sub.Name]
+		delete(liveByName, sub.Name)
+
+		if !ok {
+			r.planNewSubscription(&p, sub)
+			continue
+		}
+		if err := r.planExistingSubscription(ctx, &p, sub, liveSub); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, sub := range live {
+		if !r.prune {
+			break
+		}
+		if _, ok := liveByName[redis.StringValue(sub.Name)]; !ok {
+			continue
+		}
+		if err := r.planDeletedSubscription(ctx, &p, sub); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Plan{Actions: p.ordered()}, nil
+}
+
+// phases holds the actions in groups, which are applied in the order of the fields.
+type phases struct {
+	creates        []Action
+	updates        []Action
+	networking     []Action
+	peeringDeletes []Action
+	databases      []Action
+	subscriptions  []Action
+}
+
+func (p *phases) ordered() []Action {
+	var actions []Action
+	for _, phase := range [][]Action{p.creates, p.updates, p.networking, p.peeringDeletes, p.databases, p.subscriptions} {
+		actions = append(actions, phase...)
+	}
+	return actions
+}
+
+func (r *Reconciler) planNewSubscription(p *phases, sub *Subscription) {
+	ref := &subscriptionRef{name: sub.Name}
+
+	var names []string
+	for _, db := range sub.Databases {
+		names = append(names, redis.StringValue(db.Name))
+	}
+	var changes []Change
+	if len(names) > 0 {
+		changes = append(changes, Change{Field: "databases", To: fmt.Sprintf("%q", names)})
+	}
+
+	p.creates = append(p.creates, Action{
+		Type:     Create,
+		Resource: ResourceSubscription,
+		Changes:  changes,
+		ref:      ref,
+		apply: func(ctx context.Context) error {
+			return r.createSubscription(ctx, ref, sub)
+		},
+	})
+
+	if sub.CIDRAllowlist != nil {
+		p.networking = append(p.networking, r.updateCIDRAllowlist(ref, sub.CIDRAllowlist, nil))
+	}
+	for _, peering := range sub.Peerings {
+		p.networking = append(p.networking, r.createPeering(ref, peering))
+	}
+}
+
+// createSubscription creates the subscription, using the desired databases to size it, which also creates the
+// databases. Any settings of the databases that can't be given when creating the subscription are then updated.
+func (r *Reconciler) createSubscription(ctx context.Context, ref *subscriptionRef, sub *Subscription) error {
+	create := subscriptions.CreateSubscription{
+		Name:                        redis.String(sub.Name),
+		PaymentMethodID:             sub.PaymentMethodID,
+		MemoryStorage:               sub.MemoryStorage,
+		PersistentStorageEncryption: sub.PersistentStorageEncryption,
+		CloudProviders:              sub.CloudProviders,
+	}
+	for _, db := range sub.Databases {
+		create.Databases = append(create.Databases, sizingDatabase(db))
+	}
+
+	id, err := r.client.Subscription.Create(ctx, create)
+	if err != nil {
+		return err
+	}
+	ref.id = id
+
+	live, err := r.listDatabases(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	for _, db := range sub.Databases {
+		liveDB, ok := live[redis.StringValue(db.Name)]
+		if !ok {
+			if _, err := r.client.Database.Create(ctx, id, *db); err != nil {
+				return err
+			}
+			continue
+		}
+
+		update, changes, err := diffDatabase(db, liveDB)
+		if err != nil {
+			return err
+		}
+		if len(changes) > 0 {
+			if err := r.client.Database.Update(ctx, id, redis.IntValue(liveDB.ID), update); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func sizingDatabase(db *databases.CreateDatabase) *subscriptions.CreateDatabase {
+	sizing := &subscriptions.CreateDatabase{
+		Name:                   db.Name,
+		Protocol:               db.Protocol,
+		MemoryLimitInGB:        db.MemoryLimitInGB,
+		SupportOSSClusterAPI:   db.SupportOSSClusterAPI,
+		DataPersistence:        db.DataPersistence,
+		Replication:            db.Replication,
+		Quantity:               redis.Int(1),
+		AverageItemSizeInBytes: db.AverageItemSizeInBytes,
+	}
+	if db.ThroughputMeasurement != nil {
+		sizing.ThroughputMeasurement = &subscriptions.CreateThroughput{
+			By:    db.ThroughputMeasurement.By,
+			Value: db.ThroughputMeasurement.Value,
+		}
+	}
+	for _, module := range db.Modules {
+		sizing.Modules = append(sizing.Modules, &subscriptions.CreateModules{Name: module.Name})
+	}
+	return sizing
+}
+
+func (r *Reconciler) planExistingSubscription(ctx context.Context, p *phases, sub *Subscription, live *subscriptions.Subscription) error {
+	ref := &subscriptionRef{name: sub.Name, id: redis.IntValue(live.ID)}
+
+	if sub.PaymentMethodID != nil && redis.IntValue(sub.PaymentMethodID) != redis.IntValue(live.PaymentMethodID) {
+		update := subscriptions.UpdateSubscription{PaymentMethodID: sub.PaymentMethodID}
+		p.updates = append(p.updates, Action{
+			Type:     Update,
+			Resource: ResourceSubscription,
+			Changes:  []Change{{Field: "paymentMethodId", From: format(live.PaymentMethodID), To: format(sub.PaymentMethodID)}},
+			ref:      ref,
+			apply: func(ctx context.Context) error {
+				return r.client.Subscription.Update(ctx, ref.id, update)
+			},
+		})
+	}
+
+	liveDBs, err := r.listDatabases(ctx, ref.id)
+	if err != nil {
+		return err
+	}
+
+	for _, db := range sub.Databases {
+		db := db
+		name := redis.StringValue(db.Name)
+		liveDB, ok := liveDBs[name]
+		delete(liveDBs, name)
+
+		if !ok {
+			p.updates = append(p.updates, Action{
+				Type:     Create,
+				Resource: ResourceDatabase,
+				Name:     name,
+				ref:      ref,
+				apply: func(ctx context.Context) error {
+					_, err := r.client.Database.Create(ctx, ref.id, *db)
+					return err
+				},
+			})
+			continue
+		}
+
+		update, changes, err := diffDatabase(db, liveDB)
+		if err != nil {
+			return fmt.Errorf("database %q in subscription %q: %w", name, sub.Name, err)
+		}
+		if len(changes) == 0 {
+			continue
+		}
+
+		id := redis.IntValue(liveDB.ID)
+		p.updates = append(p.updates, Action{
+			Type:     Update,
+			Resource: ResourceDatabase,
+			Name:     name,
+			Changes:  changes,
+			ref:      ref,
+			apply: func(ctx context.Context) error {
+				return r.client.Database.Update(ctx, ref.id, id, update)
+			},
+		})
+	}
+
+	if r.prune {
+		for _, name := range sortedNames(liveDBs) {
+			p.databases = append(p.databases, r.deleteDatabase(ref, name, redis.IntValue(liveDBs[name].ID)))
+		}
+	}
+
+	if sub.CIDRAllowlist != nil {
+		cidr, err := r.client.Subscription.GetCIDRAllowlist(ctx, ref.id)
+		if err != nil {
+			return err
+		}
+		if changes := diffCIDRAllowlist(sub.CIDRAllowlist, cidr); len(changes) > 0 {
+			p.networking = append(p.networking, r.updateCIDRAllowlist(ref, sub.CIDRAllowlist, changes))
+		}
+	}
+
+	if sub.Peerings != nil {
+		peerings, err := r.client.Subscription.ListVPCPeering(ctx, ref.id)
+		if err != nil {
+			return err
+		}
+
+		livePeerings := map[string]*subscriptions.VPCPeering{}
+		for _, peering := range peerings {
+			livePeerings[livePeeringKey(peering)] = peering
+		}
+
+		for _, peering := range sub.Peerings {
+			key := peeringKey(peering)
+			if _, ok := livePeerings[key]; ok {
+				delete(livePeerings, key)
+				continue
+			}
+			p.networking = append(p.networking, r.createPeering(ref, peering))
+		}
+
+		if r.prune {
+			for _, key := range sortedNames(livePeerings) {
+				p.peeringDeletes = append(p.peeringDeletes, r.deletePeering(ref, key, redis.IntValue(livePeerings[key].ID)))
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *Reconciler) planDeletedSubscription(ctx context.Context, p *phases, live *subscriptions.Subscription) error {
+	ref := &subscriptionRef{name: redis.StringValue(live.Name), id: redis.IntValue(live.ID)}
+
+	liveDBs, err := r.listDatabases(ctx, ref.id)
+	if err != nil {
+		return err
+	}
+	for _, name := range sortedNames(liveDBs) {
+		p.databases = append(p.databases, r.deleteDatabase(ref, name, redis.IntValue(liveDBs[name].ID)))
+	}
+
+	p.subscriptions = append(p.subscriptions, Action{
+		Type:     Delete,
+		Resource: ResourceSubscription,
+		ref:      ref,
+		apply: func(ctx context.Context) error {
+			return r.client.Subscription.Delete(ctx, ref.id)
+		},
+	})
+	return nil
+}
+
+func (r *Reconciler) deleteDatabase(ref *subscriptionRef, name string, id int) Action {
+	return Action{
+		Type:     Delete,
+		Resource: ResourceDatabase,
+		Name:     name,
+		ref:      ref,
+		apply: func(ctx context.Context) error {
+			return r.client.Database.Delete(ctx, ref.id, id)
+		},
+	}
+}
+
+func (r *Reconciler) updateCIDRAllowlist(ref *subscriptionRef, cidr *subscriptions.UpdateCIDRAllowlist, changes []Change) Action {
+	return Action{
+		Type:     Update,
+		Resource: ResourceCIDRAllowlist,
+		Changes:  changes,
+		ref:      ref,
+		apply: func(ctx context.Context) error {
+			return r.client.Subscription.UpdateCIDRAllowlist(ctx, ref.id, *cidr)
+		},
+	}
+}
+
+func (r *Reconciler) createPeering(ref *subscriptionRef, peering *subscriptions.CreateVPCPeering) Action {
+	return Action{
+		Type:     Create,
+		Resource: ResourceVPCPeering,
+		Name:     peeringKey(peering),
+		ref:      ref,
+		apply: func(ctx context.Context) error {
+			_, err := r.client.Subscription.CreateVPCPeering(ctx, ref.id, *peering)
+			return err
+		},
+	}
+}
+
+func (r *Reconciler) deletePeering(ref *subscriptionRef, key string, id int) Action {
+	return Action{
+		Type:     Delete,
+		Resource: ResourceVPCPeering,
+		Name:     key,
+		ref:      ref,
+		apply: func(ctx context.Context) error {
+			return r.client.Subscription.DeleteVPCPeering(ctx, ref.id, id)
+		},
+	}
+}
+
+func (r *Reconciler) listDatabases(ctx context.Context, subscription int) (map[string]*databases.Database, error) {
+	byName := map[string]*databases.Database{}
+
+	list := r.client.Database.List(ctx, subscription)
+	for list.Next() {
+		db := list.Value()
+		name := redis.StringValue(db.Name)
+		if _, ok := byName[name]; ok {
+			return nil, fmt.Errorf("there are multiple databases named %q in subscription %d", name, subscription)
+		}
+		byName[name] = db
+	}
+	if err := list.Err(); err != nil {
+		return nil, err
+	}
+
+	return byName, nil
+}
+
+// peeringKey identifies a peering by the VPC (AWS) or network (GCP) being peered with.
+func peeringKey(peering *subscriptions.CreateVPCPeering) string {
+	if peering.VPCNetworkName != nil {
+		return redis.StringValue(peering.VPCProjectUID) + "/" + redis.StringValue(peering.VPCNetworkName)
+	}
+	return redis.StringValue(peering.AWSAccountID) + "/" + redis.StringValue(peering.VPCId)
+}
+
+func livePeeringKey(peering *subscriptions.VPCPeering) string {
+	if peering.NetworkName != nil {
+		return redis.StringValue(peering.GCPProjectUID) + "/" + redis.StringValue(peering.NetworkName)
+	}
+	return redis.StringValue(peering.AWSAccountID) + "/" + redis.StringValue(peering.VPCId)
+}
+
+func validate(desired State) error {
+	if len(desired.Subscriptions) == 0 {
+		return errors.New("the desired state doesn't have any subscriptions")
+	}
+
+	names := map[string]bool{}
+	for _, sub := range desired.Subscriptions {
+		if sub.Name == "" {
+			return fmt.Errorf("every subscription must have a name")
+		}
+		if names[sub.Name] {
+			return fmt.Errorf("there are multiple subscriptions named %q", sub.Name)
+		}
+		names[sub.Name] = true
+
+		dbNames := map[string]bool{}
+		for _, db := range sub.Databases {
+			name := redis.StringValue(db.Name)
+			if name == "" {
+				return fmt.Errorf("every database in subscription %q must have a name", sub.Name)
+			}
+			if dbNames[name] {
+				return fmt.Errorf("there are multiple databases named %q in subscription %q", name, sub.Name)
+			}
+			dbNames[name] = true
+		}
+	}
+	return nil
+}
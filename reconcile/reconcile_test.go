@@ -0,0 +1,298 @@
+package reconcile_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	rediscloud_api "github.com/RedisLabs/rediscloud-go-api"
+	"github.com/RedisLabs/rediscloud-go-api/faults"
+	"github.com/RedisLabs/rediscloud-go-api/reconcile"
+	"github.com/RedisLabs/rediscloud-go-api/redis"
+	"github.com/RedisLabs/rediscloud-go-api/rediscloudtest"
+	"github.com/RedisLabs/rediscloud-go-api/service/databases"
+	"github.com/RedisLabs/rediscloud-go-api/service/subscriptions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcile_CreatesNewSubscriptionsAndConverges(t *testing.T) {
+	fake := rediscloudtest.NewServer()
+	defer fake.Close()
+	r := reconcile.New(newClient(t, fake, nil))
+	ctx := context.Background()
+
+	desired := reconcile.State{
+		Subscriptions: []*reconcile.Subscription{
+			{
+				Name:            "production",
+				PaymentMethodID: redis.Int(1),
+				CloudProviders: []*subscriptions.CreateCloudProvider{
+					{Provider: redis.String("AWS"), Regions: []*subscriptions.CreateRegion{{Region: redis.String("us-east-1")}}},
+				},
+				Databases: []*databases.CreateDatabase{
+					{Name: redis.String("cache"), MemoryLimitInGB: redis.Float64(1), DataEvictionPolicy: redis.String("allkeys-lru")},
+					{Name: redis.String("sessions"), MemoryLimitInGB: redis.Float64(2), Password: redis.String("secret")},
+				},
+				CIDRAllowlist: &subscriptions.UpdateCIDRAllowlist{CIDRIPs: redis.StringSlice("10.0.0.0/24")},
+				Peerings: []*subscriptions.CreateVPCPeering{
+					{Region: redis.String("us-east-1"), AWSAccountID: redis.String("123456789012"), VPCId: redis.String("vpc-1"), VPCCidr: redis.String("10.1.0.0/24")},
+				},
+			},
+		},
+	}
+
+	plan, err := r.Plan(ctx, desired)
+	require.NoError(t, err)
+	assert.Equal(t, `+ create subscription "production"
+    databases: ["cache" "sessions"]
+~ update CIDR allowlist of subscription "production"
++ create VPC peering "123456789012/vpc-1" in subscription "production"
+
+Plan: 2 to create, 1 to update, 0 to delete.
+`, plan.String())
+
+	require.NoError(t, plan.Apply(ctx))
+
+	plan, err = r.Plan(ctx, desired)
+	require.NoError(t, err)
+	assert.True(t, plan.Empty(), plan.String())
+	assert.Equal(t, "No changes.\n", plan.String())
+}
+
+func TestReconcile_UpdatesAndDeletesInDependencyOrder(t *testing.T) {
+	fake := rediscloudtest.NewServer()
+	defer fake.Close()
+	r := reconcile.New(newClient(t, fake, nil), reconcile.Prune(true))
+	ctx := context.Background()
+
+	keep := fake.AddSubscription(subscriptions.Subscription{Name: redis.String("keep"), PaymentMethodID: redis.Int(1)})
+	cache, err := fake.AddDatabase(keep, databases.Database{
+		Name:            redis.String("cache"),
+		Protocol:        redis.String("redis"),
+		MemoryLimitInGB: redis.Float64(1),
+		Security:        &databases.Security{Password: redis.String("old")},
+	})
+	require.NoError(t, err)
+	addDatabase(t, fake, keep, "unused", 1)
+
+	legacy := fake.AddSubscription(subscriptions.Subscription{Name: redis.String("legacy")})
+	addDatabase(t, fake, legacy, "a", 1)
+	addDatabase(t, fake, legacy, "b", 1)
+
+	desired := reconcile.State{
+		Subscriptions: []*reconcile.Subscription{
+			{
+				Name: "keep",
+				Databases: []*databases.CreateDatabase{
+					{Name: redis.String("cache"), MemoryLimitInGB: redis.Float64(2), Password: redis.String("secret")},
+					{Name: redis.String("new"), MemoryLimitInGB: redis.Float64(1)},
+				},
+			},
+		},
+	}
+
+	plan, err := r.Plan(ctx, desired)
+	require.NoError(t, err)
+	assert.Equal(t, `~ update database "cache" in subscription "keep"
+    memoryLimitInGb: 1 -> 2
+    security.password: (sensitive) -> (sensitive)
++ create database "new" in subscription "keep"
+- delete database "unused" in subscription "keep"
+- delete database "a" in subscription "legacy"
+- delete database "b" in subscription "legacy"
+- delete subscription "legacy"
+
+Plan: 1 to create, 1 to update, 4 to delete.
+`, plan.String())
+
+	require.NoError(t, plan.Apply(ctx))
+
+	assert.Equal(t, 2.0, redis.Float64Value(fake.Database(keep, cache).MemoryLimitInGB))
+	assert.Equal(t, 2, redis.IntValue(fake.Subscription(keep).NumberOfDatabases))
+	assert.Nil(t, fake.Subscription(legacy))
+
+	plan, err = r.Plan(ctx, desired)
+	require.NoError(t, err)
+	assert.True(t, plan.Empty(), plan.String())
+}
+
+func TestReconcile_OnlyDeletesWhenPruning(t *testing.T) {
+	fake := rediscloudtest.NewServer()
+	defer fake.Close()
+	r := reconcile.New(newClient(t, fake, nil))
+	ctx := context.Background()
+
+	keep := fake.AddSubscription(subscriptions.Subscription{Name: redis.String("keep")})
+	addDatabase(t, fake, keep, "unused", 1)
+	legacy := fake.AddSubscription(subscriptions.Subscription{Name: redis.String("legacy")})
+	addDatabase(t, fake, legacy, "a", 1)
+
+	plan, err := r.Plan(ctx, reconcile.State{Subscriptions: []*reconcile.Subscription{{Name: "keep"}}})
+	require.NoError(t, err)
+	assert.True(t, plan.Empty(), plan.String())
+
+	_, err = r.Plan(ctx, reconcile.State{})
+	assert.EqualError(t, err, "the desired state doesn't have any subscriptions")
+}
+
+func TestReconcile_IgnoresPasswordsThatWerentReturned(t *testing.T) {
+	fake := rediscloudtest.NewServer()
+	defer fake.Close()
+	r := reconcile.New(newClient(t, fake, nil))
+	ctx := context.Background()
+
+	sub := fake.AddSubscription(subscriptions.Subscription{Name: redis.String("example")})
+	addDatabase(t, fake, sub, "missing", 1)
+
+	plan, err := r.Plan(ctx, reconcile.State{Subscriptions: []*reconcile.Subscription{{
+		Name:      "example",
+		Databases: []*databases.CreateDatabase{{Name: redis.String("missing"), Password: redis.String("secret")}},
+	}}})
+	require.NoError(t, err)
+	assert.True(t, plan.Empty(), plan.String())
+}
+
+func TestReconcile_OnlyDeletesPeeringsWhenPruning(t *testing.T) {
+	fake := rediscloudtest.NewServer()
+	defer fake.Close()
+	client := newClient(t, fake, nil)
+	ctx := context.Background()
+
+	sub := fake.AddSubscription(subscriptions.Subscription{Name: redis.String("example")})
+	peering := func(vpc string) *subscriptions.CreateVPCPeering {
+		return &subscriptions.CreateVPCPeering{Region: redis.String("us-east-1"), AWSAccountID: redis.String("123456789012"), VPCId: redis.String(vpc), VPCCidr: redis.String("10.1.0.0/24")}
+	}
+	for _, vpc := range []string{"vpc-1", "vpc-2"} {
+		_, err := client.Subscription.CreateVPCPeering(ctx, sub, *peering(vpc))
+		require.NoError(t, err)
+	}
+	desired := reconcile.State{Subscriptions: []*reconcile.Subscription{{
+		Name:     "example",
+		Peerings: []*subscriptions.CreateVPCPeering{peering("vpc-1")},
+	}}}
+
+	plan, err := reconcile.New(client).Plan(ctx, desired)
+	require.NoError(t, err)
+	assert.True(t, plan.Empty(), plan.String())
+
+	plan, err = reconcile.New(client, reconcile.Prune(true)).Plan(ctx, desired)
+	require.NoError(t, err)
+	assert.Equal(t, `- delete VPC peering "123456789012/vpc-2" in subscription "example"
+
+Plan: 0 to create, 0 to update, 1 to delete.
+`, plan.String())
+}
+
+func TestReconcile_ApplyStopsAtTheFirstFailure(t *testing.T) {
+	fake := rediscloudtest.NewServer()
+	defer fake.Close()
+
+	transport := faults.New(nil, faults.Rule{
+		Method:      http.MethodDelete,
+		Path:        "/subscriptions/*/databases/*",
+		Fault:       faults.Status(http.StatusBadRequest),
+		Probability: 1,
+	})
+	r := reconcile.New(newClient(t, fake, transport), reconcile.Prune(true))
+	ctx := context.Background()
+
+	fake.AddSubscription(subscriptions.Subscription{Name: redis.String("keep")})
+	legacy := fake.AddSubscription(subscriptions.Subscription{Name: redis.String("legacy")})
+	db := addDatabase(t, fake, legacy, "a", 1)
+
+	plan, err := r.Plan(ctx, reconcile.State{Subscriptions: []*reconcile.Subscription{{Name: "keep"}}})
+	require.NoError(t, err)
+	require.Len(t, plan.Actions, 2)
+
+	err = plan.Apply(ctx)
+	var applyErr *reconcile.ApplyError
+	require.True(t, errors.As(err, &applyErr), "got %v", err)
+	assert.Equal(t, 0, applyErr.Applied)
+	assert.Equal(t, 1, applyErr.Remaining)
+	assert.Equal(t, reconcile.ResourceDatabase, applyErr.Action.Resource)
+	assert.Contains(t, err.Error(), `failed to delete database "a" in subscription "legacy"`)
+
+	assert.NotNil(t, fake.Subscription(legacy))
+	assert.NotNil(t, fake.Database(legacy, db))
+}
+
+func TestReconcile_RejectsChangesThatCantBeMade(t *testing.T) {
+	fake := rediscloudtest.NewServer()
+	defer fake.Close()
+	r := reconcile.New(newClient(t, fake, nil))
+	ctx := context.Background()
+
+	sub := fake.AddSubscription(subscriptions.Subscription{Name: redis.String("example")})
+	addDatabase(t, fake, sub, "cache", 1)
+
+	_, err := r.Plan(ctx, reconcile.State{Subscriptions: []*reconcile.Subscription{
+		{Name: "example", Databases: []*databases.CreateDatabase{{Name: redis.String("cache"), Protocol: redis.String("memcached")}}},
+	}})
+	assert.EqualError(t, err, `database "cache" in subscription "example": protocol can't be changed from "redis" to "memcached"`)
+
+	_, err = r.Plan(ctx, reconcile.State{Subscriptions: []*reconcile.Subscription{{Name: "example"}, {Name: "example"}}})
+	assert.EqualError(t, err, `there are multiple subscriptions named "example"`)
+}
+
+func TestReconcile_UpdatesTheFieldsThatDrifted(t *testing.T) {
+	fake := rediscloudtest.NewServer()
+	defer fake.Close()
+	r := reconcile.New(newClient(t, fake, nil))
+	ctx := context.Background()
+
+	sub := fake.AddSubscription(subscriptions.Subscription{Name: redis.String("example")})
+	_, err := fake.AddDatabase(sub, databases.Database{
+		Name:                  redis.String("cache"),
+		Protocol:              redis.String("redis"),
+		MemoryLimitInGB:       redis.Float64(1),
+		ThroughputMeasurement: &databases.Throughput{By: redis.String("operations-per-second"), Value: redis.Int(10000)},
+		Security:              &databases.Security{SourceIPs: redis.StringSlice("10.0.0.0/8", "192.168.0.0/16")},
+		Alerts:                []*databases.Alert{{Name: redis.String("dataset-size"), Value: redis.Int(80)}},
+	})
+	require.NoError(t, err)
+
+	plan, err := r.Plan(ctx, reconcile.State{Subscriptions: []*reconcile.Subscription{{
+		Name: "example",
+		Databases: []*databases.CreateDatabase{{
+			Name:                  redis.String("cache"),
+			MemoryLimitInGB:       redis.Float64(1),
+			ThroughputMeasurement: &databases.CreateThroughputMeasurement{By: redis.String("operations-per-second"), Value: redis.Int(20000)},
+			SourceIP:              redis.StringSlice("192.168.0.0/16", "10.0.0.0/8"),
+			Alerts:                []*databases.CreateAlert{{Name: redis.String("dataset-size"), Value: redis.Int(90)}},
+		}},
+	}}})
+	require.NoError(t, err)
+	assert.Equal(t, `~ update database "cache" in subscription "example"
+    alerts.dataset-size: 80 -> 90
+    throughputMeasurement.value: 10000 -> 20000
+
+Plan: 0 to create, 1 to update, 0 to delete.
+`, plan.String())
+}
+
+func addDatabase(t *testing.T, fake *rediscloudtest.Server, subscription int, name string, memory float64) int {
+	id, err := fake.AddDatabase(subscription, databases.Database{
+		Name:            redis.String(name),
+		Protocol:        redis.String("redis"),
+		MemoryLimitInGB: redis.Float64(memory),
+	})
+	require.NoError(t, err)
+	return id
+}
+
+func newClient(t *testing.T, fake *rediscloudtest.Server, transport http.RoundTripper) *rediscloud_api.Client {
+	options := []rediscloud_api.Option{
+		rediscloud_api.BaseURL(fake.URL),
+		rediscloud_api.Auth("key", "secret"),
+		rediscloud_api.TaskPolling(rediscloud_api.TaskPollingPolicy{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond}),
+	}
+	if transport != nil {
+		options = append(options, rediscloud_api.Transporter(transport))
+	}
+	client, err := rediscloud_api.NewClient(options...)
+	require.NoError(t, err)
+	return client
+}
@@ -0,0 +1,86 @@
+package rediscloud_api
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/RedisLabs/rediscloud-go-api/internal"
+)
+
+// Redacted replaces the values that are redacted from logged requests and responses.
+const Redacted = internal.Redacted
+
+// DefaultRedactedFields are the JSON fields whose values are redacted from logged requests and responses unless
+// `LogRedaction` is given other fields.
+func DefaultRedactedFields() []string {
+	return []string{
+		"password",
+		"clientSslCertificate",
+		"accessSecretKey",
+		"consolePassword",
+	}
+}
+
+// LogRedaction changes what is redacted from the requests and responses logged by `LogRequests` - will default to
+// redacting `DefaultRedactedFields` and any credentials in URIs.
+func LogRedaction(policy LogRedactionPolicy) Option {
+	return func(options *Options) {
+		options.redaction = &policy
+	}
+}
+
+// LogRedactionPolicy configures the redaction of logged requests and responses. Any zero values will be replaced with
+// the defaults.
+type LogRedactionPolicy struct {
+	// Fields are the names of the JSON fields, at any depth, whose values are redacted. The names are matched without
+	// regard to case - defaults to `DefaultRedactedFields`.
+	Fields []string
+	// KeepURICredentials stops the username and password being masked in URIs, such as the ones that a database is
+	// imported from.
+	KeepURICredentials bool
+	// Disabled logs the requests and responses without redacting anything.
+	Disabled bool
+}
+
+func (p LogRedactionPolicy) redactor() *logRedactor {
+	if p.Disabled {
+		return &logRedactor{disabled: true}
+	}
+
+	fields := p.Fields
+	if len(fields) == 0 {
+		fields = DefaultRedactedFields()
+	}
+	sensitive := map[string]bool{}
+	for _, field := range fields {
+		sensitive[strings.ToLower(field)] = true
+	}
+	return &logRedactor{redactor: internal.Redactor{
+		Sensitive: func(field string) bool {
+			return sensitive[strings.ToLower(field)]
+		},
+		MaskURIs: !p.KeepURICredentials,
+	}}
+}
+
+// logRedactor redacts the JSON bodies of logged requests and responses.
+type logRedactor struct {
+	redactor internal.Redactor
+	disabled bool
+}
+
+// redact replaces the values of the sensitive fields in the body of a request or response. The body is redacted as a
+// single JSON document, however it is laid out, and is replaced entirely if it can't be parsed - such as when it was
+// truncated. The body is kept unchanged if there is nothing to redact.
+func (r *logRedactor) redact(body []byte) []byte {
+	if r.disabled || len(bytes.TrimSpace(body)) == 0 {
+		return body
+	}
+
+	redacted, _, err := r.redactor.RedactJSON(body)
+	if err != nil {
+		// Never log a body that was meant to be redacted
+		return []byte(Redacted)
+	}
+	return redacted
+}
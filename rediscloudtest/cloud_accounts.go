@@ -0,0 +1,93 @@
+package rediscloudtest
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/RedisLabs/rediscloud-go-api/redis"
+	"github.com/RedisLabs/rediscloud-go-api/service/cloud_accounts"
+)
+
+func (s *Server) serveCloudAccounts(w http.ResponseWriter, r *http.Request, segments []string) {
+	if len(segments) == 0 || segments[0] == "" {
+		switch r.Method {
+		case http.MethodGet:
+			s.listCloudAccounts(w)
+		case http.MethodPost:
+			s.createCloudAccount(w, r)
+		default:
+			methodNotAllowed(w, r)
+		}
+		return
+	}
+
+	id, ok := parseID(w, "cloud account", segments[0])
+	if !ok {
+		return
+	}
+	cloudAccount, ok := s.cloudAccounts[id]
+	if !ok || len(segments) > 1 {
+		writeError(w, http.StatusNotFound, "CLOUD_ACCOUNT_NOT_FOUND", fmt.Sprintf("Cloud account %d was not found", id))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, cloudAccount)
+	case http.MethodPut:
+		var update cloud_accounts.UpdateCloudAccount
+		if !decode(w, r, &update) {
+			return
+		}
+		s.startTask(w, "cloudAccountUpdateRequest", func() taskResult {
+			if update.Name != nil {
+				cloudAccount.Name = update.Name
+			}
+			if update.AccessKeyID != nil {
+				cloudAccount.AccessKeyID = update.AccessKeyID
+			}
+			return succeeded(id)
+		})
+	case http.MethodDelete:
+		s.startTask(w, "cloudAccountDeleteRequest", func() taskResult {
+			delete(s.cloudAccounts, id)
+			return succeeded(id)
+		})
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+func (s *Server) listCloudAccounts(w http.ResponseWriter) {
+	var ids []int
+	for id := range s.cloudAccounts {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	list := []*cloud_accounts.CloudAccount{}
+	for _, id := range ids {
+		list = append(list, s.cloudAccounts[id])
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"cloudAccounts": list})
+}
+
+func (s *Server) createCloudAccount(w http.ResponseWriter, r *http.Request) {
+	var create cloud_accounts.CreateCloudAccount
+	if !decode(w, r, &create) {
+		return
+	}
+
+	s.startTask(w, "cloudAccountCreateRequest", func() taskResult {
+		id := s.newID()
+		s.cloudAccounts[id] = &cloud_accounts.CloudAccount{
+			ID:          redis.Int(id),
+			Name:        create.Name,
+			Provider:    create.Provider,
+			Status:      redis.String(cloud_accounts.StatusActive),
+			AccessKeyID: create.AccessKeyID,
+		}
+		return succeeded(id)
+	})
+}
@@ -0,0 +1,292 @@
+package rediscloudtest
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/RedisLabs/rediscloud-go-api/redis"
+	"github.com/RedisLabs/rediscloud-go-api/service/databases"
+	"github.com/RedisLabs/rediscloud-go-api/service/subscriptions"
+)
+
+func (s *Server) serveDatabases(w http.ResponseWriter, r *http.Request, sub *subscription, segments []string) {
+	if len(segments) == 0 || segments[0] == "" {
+		switch r.Method {
+		case http.MethodGet:
+			s.listDatabases(w, r, sub)
+		case http.MethodPost:
+			s.createDatabase(w, r, sub)
+		default:
+			methodNotAllowed(w, r)
+		}
+		return
+	}
+
+	id, ok := parseID(w, "database", segments[0])
+	if !ok {
+		return
+	}
+	db, ok := sub.databases[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "DATABASE_NOT_FOUND", fmt.Sprintf("Database %d was not found", id))
+		return
+	}
+
+	if len(segments) == 1 {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, db)
+		case http.MethodPut:
+			s.updateDatabase(w, r, sub, db)
+		case http.MethodDelete:
+			s.deleteDatabase(w, sub, id)
+		default:
+			methodNotAllowed(w, r)
+		}
+		return
+	}
+
+	if len(segments) > 2 {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", fmt.Sprintf("No endpoint for %s", r.URL.Path))
+		return
+	}
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	switch segments[1] {
+	case "backup":
+		s.changeDatabase(w, sub, db, "databaseBackupRequest", func() {})
+	case "import":
+		var request databases.Import
+		if !decode(w, r, &request) {
+			return
+		}
+		s.changeDatabase(w, sub, db, "databaseImportRequest", func() {})
+	default:
+		writeError(w, http.StatusNotFound, "NOT_FOUND", fmt.Sprintf("No endpoint for %s", r.URL.Path))
+	}
+}
+
+// listDatabases pages through the databases using the `limit` and `offset` query parameters, responding with a 404
+// once there are no more databases - as the real API does.
+func (s *Server) listDatabases(w http.ResponseWriter, r *http.Request, sub *subscription) {
+	var ids []int
+	for id := range sub.databases {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+
+	if offset < 0 || offset >= len(ids) {
+		writeError(w, http.StatusNotFound, "DATABASES_NOT_FOUND", fmt.Sprintf("Subscription %d has no more databases", redis.IntValue(sub.ID)))
+		return
+	}
+
+	end := offset + limit
+	if end > len(ids) {
+		end = len(ids)
+	}
+
+	var page []*databases.Database
+	for _, id := range ids[offset:end] {
+		page = append(page, sub.databases[id])
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"subscription": []map[string]interface{}{
+			{
+				"subscriptionId": redis.IntValue(sub.ID),
+				"databases":      page,
+			},
+		},
+	})
+}
+
+func (s *Server) createDatabase(w http.ResponseWriter, r *http.Request, sub *subscription) {
+	var create databases.CreateDatabase
+	if !decode(w, r, &create) || !requireActive(w, sub) {
+		return
+	}
+
+	if redis.BoolValue(create.DryRun) {
+		s.startTask(w, "databaseCreateRequest", func() taskResult {
+			return taskResult{}
+		})
+		return
+	}
+
+	sub.Status = redis.String(subscriptions.SubscriptionStatusPending)
+	s.startTask(w, "databaseCreateRequest", func() taskResult {
+		id := s.addDatabase(sub, create, databases.StatusActive)
+		sub.Status = redis.String(subscriptions.SubscriptionStatusActive)
+		return succeeded(id)
+	})
+}
+
+// addDatabase stores a new database built from the create request and returns its identifier.
+func (s *Server) addDatabase(sub *subscription, create databases.CreateDatabase, status string) int {
+	id := s.newID()
+	now := time.Now().UTC()
+
+	db := &databases.Database{
+		ID:                   redis.Int(id),
+		Name:                 create.Name,
+		Protocol:             create.Protocol,
+		Status:               redis.String(status),
+		MemoryLimitInGB:      create.MemoryLimitInGB,
+		MemoryUsedInMB:       redis.Float64(0),
+		SupportOSSClusterAPI: create.SupportOSSClusterAPI,
+		DataPersistence:      create.DataPersistence,
+		Replication:          create.Replication,
+		DataEvictionPolicy:   create.DataEvictionPolicy,
+		Security: &databases.Security{
+			SSLClientAuthentication: redis.Bool(create.ClientSSLCertificate != nil),
+			SourceIPs:               create.SourceIP,
+			Password:                create.Password,
+		},
+		ActivatedOn:     redis.Time(now),
+		LastModified:    redis.Time(now),
+		MemoryStorage:   sub.MemoryStorage,
+		PrivateEndpoint: redis.String(fmt.Sprintf("redis-%d.internal.example.com:%d", id, 10000+id)),
+		PublicEndpoint:  redis.String(fmt.Sprintf("redis-%d.example.com:%d", id, 10000+id)),
+	}
+
+	if db.Protocol == nil {
+		db.Protocol = redis.String("redis")
+	}
+	if db.DataPersistence == nil {
+		db.DataPersistence = redis.String("none")
+	}
+	if db.DataEvictionPolicy == nil {
+		db.DataEvictionPolicy = redis.String("volatile-lru")
+	}
+	if db.Replication == nil {
+		db.Replication = redis.Bool(true)
+	}
+	if db.SupportOSSClusterAPI == nil {
+		db.SupportOSSClusterAPI = redis.Bool(false)
+	}
+	if len(db.Security.SourceIPs) == 0 {
+		db.Security.SourceIPs = []*string{redis.String("0.0.0.0/0")}
+	}
+
+	if len(sub.CloudDetails) > 0 {
+		db.Provider = sub.CloudDetails[0].Provider
+		if len(sub.CloudDetails[0].Regions) > 0 {
+			db.Region = sub.CloudDetails[0].Regions[0].Region
+		}
+	}
+	if create.ThroughputMeasurement != nil {
+		db.ThroughputMeasurement = &databases.Throughput{
+			By:    create.ThroughputMeasurement.By,
+			Value: create.ThroughputMeasurement.Value,
+		}
+	}
+	if len(create.ReplicaOf) > 0 {
+		db.ReplicaOf = &databases.ReplicaOf{Endpoints: create.ReplicaOf}
+	}
+	for _, module := range create.Modules {
+		db.Modules = append(db.Modules, &databases.Module{Name: module.Name})
+	}
+	for _, alert := range create.Alerts {
+		db.Alerts = append(db.Alerts, &databases.Alert{Name: alert.Name, Value: alert.Value})
+	}
+
+	sub.databases[id] = db
+	sub.NumberOfDatabases = redis.Int(len(sub.databases))
+	return id
+}
+
+func (s *Server) updateDatabase(w http.ResponseWriter, r *http.Request, sub *subscription, db *databases.Database) {
+	var update databases.UpdateDatabase
+	if !decode(w, r, &update) {
+		return
+	}
+
+	s.changeDatabase(w, sub, db, "databaseUpdateRequest", func() {
+		if update.Name != nil {
+			db.Name = update.Name
+		}
+		if update.MemoryLimitInGB != nil {
+			db.MemoryLimitInGB = update.MemoryLimitInGB
+		}
+		if update.SupportOSSClusterAPI != nil {
+			db.SupportOSSClusterAPI = update.SupportOSSClusterAPI
+		}
+		if update.DataEvictionPolicy != nil {
+			db.DataEvictionPolicy = update.DataEvictionPolicy
+		}
+		if update.Replication != nil {
+			db.Replication = update.Replication
+		}
+		if update.ThroughputMeasurement != nil {
+			db.ThroughputMeasurement = &databases.Throughput{
+				By:    update.ThroughputMeasurement.By,
+				Value: update.ThroughputMeasurement.Value,
+			}
+		}
+		if update.DataPersistence != nil {
+			db.DataPersistence = update.DataPersistence
+		}
+		if update.ReplicaOf != nil {
+			db.ReplicaOf = &databases.ReplicaOf{Endpoints: update.ReplicaOf}
+		}
+		if db.Security == nil {
+			db.Security = &databases.Security{}
+		}
+		if update.SourceIP != nil {
+			db.Security.SourceIPs = update.SourceIP
+		}
+		if update.Password != nil {
+			db.Security.Password = update.Password
+		}
+		if update.Alerts != nil {
+			db.Alerts = nil
+			for _, alert := range update.Alerts {
+				db.Alerts = append(db.Alerts, &databases.Alert{Name: alert.Name, Value: alert.Value})
+			}
+		}
+	})
+}
+
+// changeDatabase starts a task to change an existing database, with both the database and its subscription pending
+// until the task completes.
+func (s *Server) changeDatabase(w http.ResponseWriter, sub *subscription, db *databases.Database, commandType string, change func()) {
+	if !requireActive(w, sub) {
+		return
+	}
+
+	sub.Status = redis.String(subscriptions.SubscriptionStatusPending)
+	db.Status = redis.String(databases.StatusPending)
+	s.startTask(w, commandType, func() taskResult {
+		change()
+		db.LastModified = redis.Time(time.Now().UTC())
+		db.Status = redis.String(databases.StatusActive)
+		sub.Status = redis.String(subscriptions.SubscriptionStatusActive)
+		return succeeded(redis.IntValue(db.ID))
+	})
+}
+
+func (s *Server) deleteDatabase(w http.ResponseWriter, sub *subscription, id int) {
+	if !requireActive(w, sub) {
+		return
+	}
+
+	sub.Status = redis.String(subscriptions.SubscriptionStatusPending)
+	s.startTask(w, "databaseDeleteRequest", func() taskResult {
+		delete(sub.databases, id)
+		sub.NumberOfDatabases = redis.Int(len(sub.databases))
+		sub.Status = redis.String(subscriptions.SubscriptionStatusActive)
+		return succeeded(id)
+	})
+}
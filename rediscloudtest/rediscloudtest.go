@@ -0,0 +1,16 @@
+// Package rediscloudtest provides an in-memory fake of the Redis Cloud API, so that code using this SDK can be tested
+// without network access or a real account.
+//
+// The fake keeps the state of cloud accounts, subscriptions and their databases, and simulates the asynchronous
+// tasks that the API uses for any change: a change is only applied once its task has been polled until it completes.
+// As the SDK polls tasks with a delay of a second by default, tests will usually want to configure a shorter delay:
+//
+//	fake := rediscloudtest.NewServer()
+//	defer fake.Close()
+//
+//	client, err := rediscloud_api.NewClient(
+//		rediscloud_api.BaseURL(fake.URL),
+//		rediscloud_api.Auth("key", "secret"),
+//		rediscloud_api.TaskPolling(rediscloud_api.TaskPollingPolicy{InitialDelay: time.Millisecond}),
+//	)
+package rediscloudtest
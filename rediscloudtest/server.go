@@ -0,0 +1,296 @@
+package rediscloudtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/RedisLabs/rediscloud-go-api/redis"
+	"github.com/RedisLabs/rediscloud-go-api/service/account"
+	"github.com/RedisLabs/rediscloud-go-api/service/cloud_accounts"
+	"github.com/RedisLabs/rediscloud-go-api/service/databases"
+	"github.com/RedisLabs/rediscloud-go-api/service/subscriptions"
+)
+
+// Server is a fake Redis Cloud API. It is safe to use from multiple goroutines.
+type Server struct {
+	// URL is the base URL of the fake, which should be passed to the client with the `BaseURL` option.
+	URL string
+
+	server *httptest.Server
+
+	mu              sync.Mutex
+	apiKey          string
+	secretKey       string
+	inProgressPolls int
+	nextID          int
+	nextTaskID      int
+	cloudAccounts   map[int]*cloud_accounts.CloudAccount
+	subscriptions   map[int]*subscription
+	tasks           map[string]*task
+	taskOrder       []string
+	paymentMethods  []*account.PaymentMethod
+	regions         []*account.Region
+	dataPersistence []*account.DataPersistence
+	databaseModules []*account.DatabaseModule
+}
+
+type subscription struct {
+	subscriptions.Subscription
+	databases map[int]*databases.Database
+	cidr      subscriptions.CIDRAllowlist
+	peerings  map[int]*subscriptions.VPCPeering
+}
+
+type Option func(*Server)
+
+// Credentials makes the fake reject any request that doesn't use these credentials - will default to accepting any
+// credentials.
+func Credentials(apiKey string, secretKey string) Option {
+	return func(s *Server) {
+		s.apiKey = apiKey
+		s.secretKey = secretKey
+	}
+}
+
+// TaskPolls sets the number of times a task will be reported as `processing-in-progress` before it completes - will
+// default to 1.
+func TaskPolls(polls int) Option {
+	return func(s *Server) {
+		s.inProgressPolls = polls
+	}
+}
+
+// NewServer starts a new fake, which should be closed once it is no longer needed.
+func NewServer(options ...Option) *Server {
+	s := &Server{
+		inProgressPolls: 1,
+		nextID:          1,
+		nextTaskID:      1,
+		cloudAccounts:   map[int]*cloud_accounts.CloudAccount{},
+		subscriptions:   map[int]*subscription{},
+		tasks:           map[string]*task{},
+		paymentMethods: []*account.PaymentMethod{
+			{
+				ID:                 redis.Int(1),
+				Type:               redis.String("Visa"),
+				CreditCardEndsWith: redis.Int(1234),
+				ExpirationMonth:    redis.Int(12),
+				ExpirationYear:     redis.Int(2030),
+			},
+		},
+		regions: []*account.Region{
+			{Name: redis.String("us-east-1"), Provider: redis.String("AWS")},
+			{Name: redis.String("eu-west-1"), Provider: redis.String("AWS")},
+			{Name: redis.String("europe-west1"), Provider: redis.String("GCP")},
+		},
+		dataPersistence: []*account.DataPersistence{
+			{Name: redis.String("none"), Description: redis.String("None")},
+			{Name: redis.String("aof-every-1-second"), Description: redis.String("Append only file (AOF) - fsync every 1 second")},
+			{Name: redis.String("snapshot-every-1-hour"), Description: redis.String("Snapshot every 1 hour")},
+		},
+		databaseModules: []*account.DatabaseModule{
+			{Name: redis.String("RedisBloom"), Description: redis.String("Probabilistic data structures")},
+			{Name: redis.String("RedisJSON"), Description: redis.String("Native JSON data type")},
+			{Name: redis.String("RediSearch"), Description: redis.String("Full-text search")},
+			{Name: redis.String("RedisTimeSeries"), Description: redis.String("Time series data structure")},
+		},
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL
+	return s
+}
+
+// Close shuts down the fake.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// AddCloudAccount stores a cloud account, as if it had already been created, and returns its identifier.
+func (s *Server) AddCloudAccount(cloudAccount cloud_accounts.CloudAccount) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.newID()
+	cloudAccount.ID = redis.Int(id)
+	if cloudAccount.Status == nil {
+		cloudAccount.Status = redis.String(cloud_accounts.StatusActive)
+	}
+	s.cloudAccounts[id] = &cloudAccount
+	return id
+}
+
+// AddSubscription stores a subscription, as if it had already been created, and returns its identifier.
+func (s *Server) AddSubscription(sub subscriptions.Subscription) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.newID()
+	sub.ID = redis.Int(id)
+	if sub.Status == nil {
+		sub.Status = redis.String(subscriptions.SubscriptionStatusActive)
+	}
+	s.subscriptions[id] = &subscription{
+		Subscription: sub,
+		databases:    map[int]*databases.Database{},
+		peerings:     map[int]*subscriptions.VPCPeering{},
+	}
+	s.subscriptions[id].NumberOfDatabases = redis.Int(0)
+	return id
+}
+
+// AddDatabase stores a database in an existing subscription, as if it had already been created, and returns its
+// identifier.
+func (s *Server) AddDatabase(subscriptionID int, db databases.Database) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.subscriptions[subscriptionID]
+	if !ok {
+		return 0, fmt.Errorf("subscription %d not found", subscriptionID)
+	}
+
+	id := s.newID()
+	db.ID = redis.Int(id)
+	if db.Status == nil {
+		db.Status = redis.String(databases.StatusActive)
+	}
+	sub.databases[id] = &db
+	sub.NumberOfDatabases = redis.Int(len(sub.databases))
+	return id, nil
+}
+
+// CloudAccount returns a copy of the current state of the cloud account, or nil if it doesn't exist.
+func (s *Server) CloudAccount(id int) *cloud_accounts.CloudAccount {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cloudAccount, ok := s.cloudAccounts[id]
+	if !ok {
+		return nil
+	}
+	c := *cloudAccount
+	return &c
+}
+
+// Subscription returns a copy of the current state of the subscription, or nil if it doesn't exist.
+func (s *Server) Subscription(id int) *subscriptions.Subscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.subscriptions[id]
+	if !ok {
+		return nil
+	}
+	c := sub.Subscription
+	return &c
+}
+
+// Database returns a copy of the current state of the database, or nil if it doesn't exist.
+func (s *Server) Database(subscriptionID int, id int) *databases.Database {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.subscriptions[subscriptionID]
+	if !ok {
+		return nil
+	}
+	db, ok := sub.databases[id]
+	if !ok {
+		return nil
+	}
+	c := *db
+	return &c
+}
+
+func (s *Server) newID() int {
+	id := s.nextID
+	s.nextID++
+	return id
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.apiKey != "" && (r.Header.Get("X-Api-Key") != s.apiKey || r.Header.Get("X-Api-Secret-Key") != s.secretKey) {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Authentication failed for the provided credentials")
+		return
+	}
+
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch segments[0] {
+	case "payment-methods":
+		s.get(w, r, map[string]interface{}{"paymentMethods": s.paymentMethods})
+	case "regions":
+		s.get(w, r, map[string]interface{}{"regions": s.regions})
+	case "data-persistence":
+		s.get(w, r, map[string]interface{}{"dataPersistence": s.dataPersistence})
+	case "database-modules":
+		s.get(w, r, map[string]interface{}{"modules": s.databaseModules})
+	case "tasks":
+		s.serveTasks(w, r, segments[1:])
+	case "cloud-accounts":
+		s.serveCloudAccounts(w, r, segments[1:])
+	case "subscriptions":
+		s.serveSubscriptions(w, r, segments[1:])
+	default:
+		writeError(w, http.StatusNotFound, "NOT_FOUND", fmt.Sprintf("No endpoint for %s", r.URL.Path))
+	}
+}
+
+func (s *Server) get(w http.ResponseWriter, r *http.Request, body interface{}) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, body)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, errorType string, description string) {
+	writeJSON(w, status, errorDocument(status, errorType, description))
+}
+
+func errorDocument(status int, errorType string, description string) map[string]string {
+	return map[string]string{
+		"type":        errorType,
+		"status":      fmt.Sprintf("%d %s", status, strings.ReplaceAll(strings.ToUpper(http.StatusText(status)), " ", "_")),
+		"description": description,
+	}
+}
+
+func methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", fmt.Sprintf("%s is not supported for %s", r.Method, r.URL.Path))
+}
+
+func decode(w http.ResponseWriter, r *http.Request, body interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(body); err != nil {
+		writeError(w, http.StatusBadRequest, "INVALID_REQUEST", fmt.Sprintf("Request body could not be parsed: %s", err))
+		return false
+	}
+	return true
+}
+
+func parseID(w http.ResponseWriter, kind string, value string) (int, bool) {
+	id, err := strconv.Atoi(value)
+	if err != nil {
+		writeError(w, http.StatusNotFound, strings.ToUpper(strings.ReplaceAll(kind, " ", "_"))+"_NOT_FOUND", fmt.Sprintf("%s %s was not found", kind, value))
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,216 @@
+package rediscloudtest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	rediscloud_api "github.com/RedisLabs/rediscloud-go-api"
+	"github.com/RedisLabs/rediscloud-go-api/apierrors"
+	"github.com/RedisLabs/rediscloud-go-api/redis"
+	"github.com/RedisLabs/rediscloud-go-api/rediscloudtest"
+	"github.com/RedisLabs/rediscloud-go-api/service/cloud_accounts"
+	"github.com/RedisLabs/rediscloud-go-api/service/databases"
+	"github.com/RedisLabs/rediscloud-go-api/service/subscriptions"
+	"github.com/RedisLabs/rediscloud-go-api/service/tasks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_SubscriptionAndDatabaseLifecycle(t *testing.T) {
+	fake := rediscloudtest.NewServer(rediscloudtest.TaskPolls(2))
+	defer fake.Close()
+	client := newClient(t, fake)
+	ctx := context.Background()
+
+	subID, err := client.Subscription.Create(ctx, subscriptions.CreateSubscription{
+		Name:            redis.String("example"),
+		PaymentMethodID: redis.Int(1),
+		CloudProviders: []*subscriptions.CreateCloudProvider{
+			{
+				Provider: redis.String("AWS"),
+				Regions: []*subscriptions.CreateRegion{
+					{Region: redis.String("us-east-1")},
+				},
+			},
+		},
+		Databases: []*subscriptions.CreateDatabase{
+			{Name: redis.String("first"), MemoryLimitInGB: redis.Float64(1), Quantity: redis.Int(1)},
+		},
+	})
+	require.NoError(t, err)
+
+	sub, err := client.Subscription.Get(ctx, subID)
+	require.NoError(t, err)
+	assert.Equal(t, "example", redis.StringValue(sub.Name))
+	assert.Equal(t, subscriptions.SubscriptionStatusActive, redis.StringValue(sub.Status))
+	assert.Equal(t, 1, redis.IntValue(sub.NumberOfDatabases))
+
+	dbID, err := client.Database.Create(ctx, subID, databases.CreateDatabase{
+		Name:            redis.String("second"),
+		MemoryLimitInGB: redis.Float64(2),
+		Password:        redis.String("secret"),
+	})
+	require.NoError(t, err)
+
+	db, err := client.Database.Get(ctx, subID, dbID)
+	require.NoError(t, err)
+	assert.Equal(t, "second", redis.StringValue(db.Name))
+	assert.Equal(t, databases.StatusActive, redis.StringValue(db.Status))
+	assert.Equal(t, "AWS", redis.StringValue(db.Provider))
+	assert.Equal(t, "us-east-1", redis.StringValue(db.Region))
+	assert.NotEmpty(t, redis.StringValue(db.PublicEndpoint))
+
+	require.NoError(t, client.Database.Update(ctx, subID, dbID, databases.UpdateDatabase{Name: redis.String("renamed")}))
+	assert.Equal(t, "renamed", redis.StringValue(fake.Database(subID, dbID).Name))
+
+	var names []string
+	list := client.Database.List(ctx, subID)
+	for list.Next() {
+		names = append(names, redis.StringValue(list.Value().Name))
+	}
+	require.NoError(t, list.Err())
+	assert.Equal(t, []string{"first", "renamed"}, names)
+
+	err = client.Subscription.Delete(ctx, subID)
+	assert.True(t, errors.Is(err, apierrors.ErrTaskFailed), "got %v", err)
+
+	for _, id := range []int{dbID, dbID - 1} {
+		require.NoError(t, client.Database.Delete(ctx, subID, id))
+	}
+	require.NoError(t, client.Subscription.Delete(ctx, subID))
+
+	_, err = client.Subscription.Get(ctx, subID)
+	assert.True(t, errors.Is(err, apierrors.ErrNotFound), "got %v", err)
+	assert.Nil(t, fake.Subscription(subID))
+}
+
+func TestServer_ChangesWhileTheSubscriptionIsBusyAreRejected(t *testing.T) {
+	fake := rediscloudtest.NewServer()
+	defer fake.Close()
+	client := newClient(t, fake)
+	ctx := context.Background()
+
+	subID := fake.AddSubscription(subscriptions.Subscription{Name: redis.String("example")})
+	dbID, err := fake.AddDatabase(subID, databases.Database{Name: redis.String("example")})
+	require.NoError(t, err)
+
+	task, err := client.Database.UpdateAsync(ctx, subID, dbID, databases.UpdateDatabase{Name: redis.String("renamed")})
+	require.NoError(t, err)
+	assert.Equal(t, subscriptions.SubscriptionStatusPending, redis.StringValue(fake.Subscription(subID).Status))
+	assert.Equal(t, databases.StatusPending, redis.StringValue(fake.Database(subID, dbID).Status))
+
+	_, err = client.Database.UpdateAsync(ctx, subID, dbID, databases.UpdateDatabase{Name: redis.String("other")})
+	var httpErr *apierrors.HTTPError
+	require.True(t, errors.As(err, &httpErr), "got %v", err)
+	assert.Equal(t, "SUBSCRIPTION_NOT_ACTIVE", httpErr.ErrorCode)
+
+	require.NoError(t, client.Task.Wait(ctx, redis.StringValue(task.ID)))
+	assert.Equal(t, subscriptions.SubscriptionStatusActive, redis.StringValue(fake.Subscription(subID).Status))
+	assert.Equal(t, "renamed", redis.StringValue(fake.Database(subID, dbID).Name))
+}
+
+func TestServer_TasksProgressThroughTheirStatuses(t *testing.T) {
+	fake := rediscloudtest.NewServer(rediscloudtest.TaskPolls(1))
+	defer fake.Close()
+	client := newClient(t, fake)
+	ctx := context.Background()
+
+	created, err := client.CloudAccount.CreateAsync(ctx, cloud_accounts.CreateCloudAccount{
+		Name:     redis.String("example"),
+		Provider: redis.String("AWS"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, tasks.StatusReceived, redis.StringValue(created.Status))
+
+	id := redis.StringValue(created.ID)
+	task, err := client.Task.Get(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, tasks.StatusProcessingInProgress, redis.StringValue(task.Status))
+
+	task, err = client.Task.Get(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, tasks.StatusProcessingCompleted, redis.StringValue(task.Status))
+
+	list, err := client.Task.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, id, redis.StringValue(list[0].ID))
+
+	cloudAccount, err := client.CloudAccount.Get(ctx, redis.IntValue(task.Response.ID))
+	require.NoError(t, err)
+	assert.Equal(t, "example", redis.StringValue(cloudAccount.Name))
+	assert.Equal(t, cloud_accounts.StatusActive, redis.StringValue(cloudAccount.Status))
+}
+
+func TestServer_NetworkingIsStoredOnTheSubscription(t *testing.T) {
+	fake := rediscloudtest.NewServer()
+	defer fake.Close()
+	client := newClient(t, fake)
+	ctx := context.Background()
+
+	subID := fake.AddSubscription(subscriptions.Subscription{Name: redis.String("example")})
+
+	require.NoError(t, client.Subscription.UpdateCIDRAllowlist(ctx, subID, subscriptions.UpdateCIDRAllowlist{
+		CIDRIPs: []*string{redis.String("10.0.0.0/24")},
+	}))
+	cidr, err := client.Subscription.GetCIDRAllowlist(ctx, subID)
+	require.NoError(t, err)
+	assert.Equal(t, []*string{redis.String("10.0.0.0/24")}, cidr.CIDRIPs)
+
+	peeringID, err := client.Subscription.CreateVPCPeering(ctx, subID, subscriptions.CreateVPCPeering{
+		Region:       redis.String("us-east-1"),
+		AWSAccountID: redis.String("123456789012"),
+		VPCId:        redis.String("vpc-01234567"),
+		VPCCidr:      redis.String("10.1.0.0/24"),
+	})
+	require.NoError(t, err)
+
+	peerings, err := client.Subscription.ListVPCPeering(ctx, subID)
+	require.NoError(t, err)
+	require.Len(t, peerings, 1)
+	assert.Equal(t, peeringID, redis.IntValue(peerings[0].ID))
+	assert.Equal(t, "vpc-01234567", redis.StringValue(peerings[0].VPCId))
+
+	require.NoError(t, client.Subscription.DeleteVPCPeering(ctx, subID, peeringID))
+	peerings, err = client.Subscription.ListVPCPeering(ctx, subID)
+	require.NoError(t, err)
+	assert.Empty(t, peerings)
+}
+
+func TestServer_Account(t *testing.T) {
+	fake := rediscloudtest.NewServer()
+	defer fake.Close()
+	client := newClient(t, fake)
+	ctx := context.Background()
+
+	regions, err := client.Account.ListRegions(ctx)
+	require.NoError(t, err)
+	assert.NotEmpty(t, regions)
+
+	methods, err := client.Account.ListPaymentMethods(ctx)
+	require.NoError(t, err)
+	assert.Len(t, methods, 1)
+}
+
+func TestServer_RejectsOtherCredentials(t *testing.T) {
+	fake := rediscloudtest.NewServer(rediscloudtest.Credentials("key", "secret"))
+	defer fake.Close()
+
+	client, err := rediscloud_api.NewClient(rediscloud_api.BaseURL(fake.URL), rediscloud_api.Auth("key", "wrong"))
+	require.NoError(t, err)
+
+	_, err = client.Account.ListRegions(context.Background())
+	assert.True(t, errors.Is(err, apierrors.ErrUnauthorized), "got %v", err)
+}
+
+func newClient(t *testing.T, fake *rediscloudtest.Server) *rediscloud_api.Client {
+	client, err := rediscloud_api.NewClient(
+		rediscloud_api.BaseURL(fake.URL),
+		rediscloud_api.Auth("key", "secret"),
+		rediscloud_api.TaskPolling(rediscloud_api.TaskPollingPolicy{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond}),
+	)
+	require.NoError(t, err)
+	return client
+}
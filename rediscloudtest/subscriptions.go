@@ -0,0 +1,322 @@
+package rediscloudtest
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/RedisLabs/rediscloud-go-api/redis"
+	"github.com/RedisLabs/rediscloud-go-api/service/databases"
+	"github.com/RedisLabs/rediscloud-go-api/service/subscriptions"
+)
+
+func (s *Server) serveSubscriptions(w http.ResponseWriter, r *http.Request, segments []string) {
+	if len(segments) == 0 || segments[0] == "" {
+		switch r.Method {
+		case http.MethodGet:
+			s.listSubscriptions(w)
+		case http.MethodPost:
+			s.createSubscription(w, r)
+		default:
+			methodNotAllowed(w, r)
+		}
+		return
+	}
+
+	sub, ok := s.findSubscription(w, segments[0])
+	if !ok {
+		return
+	}
+
+	if len(segments) == 1 {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, sub.Subscription)
+		case http.MethodPut:
+			s.updateSubscription(w, r, sub)
+		case http.MethodDelete:
+			s.deleteSubscription(w, sub)
+		default:
+			methodNotAllowed(w, r)
+		}
+		return
+	}
+
+	switch segments[1] {
+	case "databases":
+		s.serveDatabases(w, r, sub, segments[2:])
+	case "cidr":
+		s.serveCIDRAllowlist(w, r, sub, segments[2:])
+	case "peerings":
+		s.servePeerings(w, r, sub, segments[2:])
+	default:
+		writeError(w, http.StatusNotFound, "NOT_FOUND", fmt.Sprintf("No endpoint for %s", r.URL.Path))
+	}
+}
+
+func (s *Server) findSubscription(w http.ResponseWriter, value string) (*subscription, bool) {
+	id, ok := parseID(w, "subscription", value)
+	if !ok {
+		return nil, false
+	}
+
+	sub, ok := s.subscriptions[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "SUBSCRIPTION_NOT_FOUND", fmt.Sprintf("Subscription %d was not found", id))
+		return nil, false
+	}
+	return sub, true
+}
+
+// requireActive rejects changes to a subscription that is still busy with a previous change, in the same way as
+// the real API.
+func requireActive(w http.ResponseWriter, sub *subscription) bool {
+	if redis.StringValue(sub.Status) != subscriptions.SubscriptionStatusActive {
+		writeError(w, http.StatusBadRequest, "SUBSCRIPTION_NOT_ACTIVE", "Cannot preform any actions for subscription that is not in an active state")
+		return false
+	}
+	return true
+}
+
+func (s *Server) listSubscriptions(w http.ResponseWriter) {
+	var ids []int
+	for id := range s.subscriptions {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	list := []*subscriptions.Subscription{}
+	for _, id := range ids {
+		sub := s.subscriptions[id].Subscription
+		list = append(list, &sub)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"subscriptions": list})
+}
+
+func (s *Server) createSubscription(w http.ResponseWriter, r *http.Request) {
+	var create subscriptions.CreateSubscription
+	if !decode(w, r, &create) {
+		return
+	}
+
+	if redis.BoolValue(create.DryRun) {
+		s.startTask(w, "subscriptionCreateRequest", func() taskResult {
+			return taskResult{}
+		})
+		return
+	}
+
+	id := s.newID()
+	sub := &subscription{
+		Subscription: subscriptions.Subscription{
+			ID:                redis.Int(id),
+			Name:              create.Name,
+			Status:            redis.String(subscriptions.SubscriptionStatusPending),
+			PaymentMethodID:   create.PaymentMethodID,
+			MemoryStorage:     create.MemoryStorage,
+			StorageEncryption: create.PersistentStorageEncryption,
+			NumberOfDatabases: redis.Int(0),
+		},
+		databases: map[int]*databases.Database{},
+		peerings:  map[int]*subscriptions.VPCPeering{},
+	}
+	if sub.MemoryStorage == nil {
+		sub.MemoryStorage = redis.String("ram")
+	}
+
+	for _, provider := range create.CloudProviders {
+		detail := &subscriptions.CloudDetail{
+			Provider:       provider.Provider,
+			CloudAccountID: provider.CloudAccountID,
+			TotalSizeInGB:  redis.Float64(0),
+		}
+		for _, region := range provider.Regions {
+			r := &subscriptions.Region{
+				Region:                     region.Region,
+				MultipleAvailabilityZones:  region.MultipleAvailabilityZones,
+				PreferredAvailabilityZones: region.PreferredAvailabilityZones,
+			}
+			if region.Networking != nil {
+				r.Networking = []*subscriptions.Networking{
+					{
+						DeploymentCIDR: region.Networking.DeploymentCIDR,
+						VPCId:          region.Networking.VPCId,
+						SubnetID:       redis.String(fmt.Sprintf("subnet-%08d", id)),
+					},
+				}
+			}
+			detail.Regions = append(detail.Regions, r)
+		}
+		sub.CloudDetails = append(sub.CloudDetails, detail)
+	}
+	s.subscriptions[id] = sub
+
+	s.startTask(w, "subscriptionCreateRequest", func() taskResult {
+		for _, db := range create.Databases {
+			for i := 0; i < redis.IntValue(db.Quantity) || (i == 0 && db.Quantity == nil); i++ {
+				s.addDatabase(sub, databases.CreateDatabase{
+					Name:                   db.Name,
+					Protocol:               db.Protocol,
+					MemoryLimitInGB:        db.MemoryLimitInGB,
+					SupportOSSClusterAPI:   db.SupportOSSClusterAPI,
+					DataPersistence:        db.DataPersistence,
+					Replication:            db.Replication,
+					ThroughputMeasurement:  createThroughput(db.ThroughputMeasurement),
+					Modules:                createModules(db.Modules),
+					AverageItemSizeInBytes: db.AverageItemSizeInBytes,
+				}, databases.StatusActive)
+			}
+		}
+		sub.Status = redis.String(subscriptions.SubscriptionStatusActive)
+		return succeeded(id)
+	})
+}
+
+func createThroughput(throughput *subscriptions.CreateThroughput) *databases.CreateThroughputMeasurement {
+	if throughput == nil {
+		return nil
+	}
+	return &databases.CreateThroughputMeasurement{By: throughput.By, Value: throughput.Value}
+}
+
+func createModules(modules []*subscriptions.CreateModules) []*databases.CreateModule {
+	var ret []*databases.CreateModule
+	for _, module := range modules {
+		ret = append(ret, &databases.CreateModule{Name: module.Name})
+	}
+	return ret
+}
+
+func (s *Server) updateSubscription(w http.ResponseWriter, r *http.Request, sub *subscription) {
+	var update subscriptions.UpdateSubscription
+	if !decode(w, r, &update) || !requireActive(w, sub) {
+		return
+	}
+
+	sub.Status = redis.String(subscriptions.SubscriptionStatusPending)
+	s.startTask(w, "subscriptionUpdateRequest", func() taskResult {
+		if update.Name != nil {
+			sub.Name = update.Name
+		}
+		if update.PaymentMethodID != nil {
+			sub.PaymentMethodID = update.PaymentMethodID
+		}
+		sub.Status = redis.String(subscriptions.SubscriptionStatusActive)
+		return succeeded(redis.IntValue(sub.ID))
+	})
+}
+
+func (s *Server) deleteSubscription(w http.ResponseWriter, sub *subscription) {
+	if !requireActive(w, sub) {
+		return
+	}
+
+	id := redis.IntValue(sub.ID)
+	sub.Status = redis.String(subscriptions.SubscriptionStatusDeleting)
+	s.startTask(w, "subscriptionDeleteRequest", func() taskResult {
+		if len(sub.databases) > 0 {
+			sub.Status = redis.String(subscriptions.SubscriptionStatusActive)
+			return failed(http.StatusBadRequest, "SUBSCRIPTION_NOT_EMPTY", fmt.Sprintf("Subscription %d still has databases", id))
+		}
+		delete(s.subscriptions, id)
+		return succeeded(id)
+	})
+}
+
+func (s *Server) serveCIDRAllowlist(w http.ResponseWriter, r *http.Request, sub *subscription, segments []string) {
+	if len(segments) > 0 {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", fmt.Sprintf("No endpoint for %s", r.URL.Path))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.startTask(w, "subscriptionCidrAllowlistGetRequest", func() taskResult {
+			cidr := sub.cidr
+			if cidr.CIDRIPs == nil {
+				cidr.CIDRIPs = []*string{}
+			}
+			if cidr.SecurityGroupIDs == nil {
+				cidr.SecurityGroupIDs = []*string{}
+			}
+			return succeededWithResource(cidr)
+		})
+	case http.MethodPut:
+		var update subscriptions.UpdateCIDRAllowlist
+		if !decode(w, r, &update) || !requireActive(w, sub) {
+			return
+		}
+		s.startTask(w, "subscriptionCidrAllowlistUpdateRequest", func() taskResult {
+			sub.cidr = subscriptions.CIDRAllowlist{
+				CIDRIPs:          update.CIDRIPs,
+				SecurityGroupIDs: update.SecurityGroupIDs,
+			}
+			return succeeded(redis.IntValue(sub.ID))
+		})
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+func (s *Server) servePeerings(w http.ResponseWriter, r *http.Request, sub *subscription, segments []string) {
+	if len(segments) == 0 || segments[0] == "" {
+		switch r.Method {
+		case http.MethodGet:
+			s.startTask(w, "vpcPeeringGetRequest", func() taskResult {
+				var ids []int
+				for id := range sub.peerings {
+					ids = append(ids, id)
+				}
+				sort.Ints(ids)
+
+				list := []*subscriptions.VPCPeering{}
+				for _, id := range ids {
+					list = append(list, sub.peerings[id])
+				}
+				return succeededWithResource(map[string]interface{}{"peerings": list})
+			})
+		case http.MethodPost:
+			var create subscriptions.CreateVPCPeering
+			if !decode(w, r, &create) || !requireActive(w, sub) {
+				return
+			}
+			s.startTask(w, "vpcPeeringCreateRequest", func() taskResult {
+				id := s.newID()
+				sub.peerings[id] = &subscriptions.VPCPeering{
+					ID:             redis.Int(id),
+					Status:         redis.String(subscriptions.VPCPeeringStatusPendingAcceptance),
+					AWSAccountID:   create.AWSAccountID,
+					AWSPeeringID:   redis.String(fmt.Sprintf("pcx-%08d", id)),
+					VPCId:          create.VPCId,
+					VPCCidr:        create.VPCCidr,
+					GCPProjectUID:  create.VPCProjectUID,
+					NetworkName:    create.VPCNetworkName,
+					CloudPeeringID: redis.String(fmt.Sprintf("peering-%08d", id)),
+				}
+				return succeeded(id)
+			})
+		default:
+			methodNotAllowed(w, r)
+		}
+		return
+	}
+
+	id, ok := parseID(w, "vpc peering", segments[0])
+	if !ok {
+		return
+	}
+	if _, ok := sub.peerings[id]; !ok || len(segments) > 1 {
+		writeError(w, http.StatusNotFound, "VPC_PEERING_NOT_FOUND", fmt.Sprintf("VPC peering %d was not found", id))
+		return
+	}
+
+	if r.Method != http.MethodDelete {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	s.startTask(w, "vpcPeeringDeleteRequest", func() taskResult {
+		delete(sub.peerings, id)
+		return succeeded(id)
+	})
+}
@@ -0,0 +1,121 @@
+package rediscloudtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/RedisLabs/rediscloud-go-api/apierrors"
+	"github.com/RedisLabs/rediscloud-go-api/redis"
+	"github.com/RedisLabs/rediscloud-go-api/service/tasks"
+)
+
+type task struct {
+	tasks.Task
+	polls int
+	// apply makes the change requested by the task once the task completes.
+	apply func() taskResult
+}
+
+type taskResult struct {
+	id       *int
+	resource interface{}
+	err      *apierrors.Error
+}
+
+func succeeded(id int) taskResult {
+	return taskResult{id: redis.Int(id)}
+}
+
+func succeededWithResource(resource interface{}) taskResult {
+	return taskResult{resource: resource}
+}
+
+func failed(status int, errorType string, description string) taskResult {
+	document := errorDocument(status, errorType, description)
+	return taskResult{err: &apierrors.Error{
+		Type:        redis.String(document["type"]),
+		Description: redis.String(document["description"]),
+		Status:      redis.String(document["status"]),
+	}}
+}
+
+// startTask records a new task and writes it as the response to the request that started it.
+func (s *Server) startTask(w http.ResponseWriter, commandType string, apply func() taskResult) {
+	id := fmt.Sprintf("%08d-0000-4000-8000-%012d", s.nextTaskID, s.nextTaskID)
+	s.nextTaskID++
+
+	t := &task{
+		Task: tasks.Task{
+			ID:          redis.String(id),
+			CommandType: redis.String(commandType),
+			Status:      redis.String(tasks.StatusReceived),
+			Description: redis.String("Task request received and is being queued for processing."),
+			Timestamp:   redis.Time(time.Now().UTC()),
+		},
+		apply: apply,
+	}
+	s.tasks[id] = t
+	s.taskOrder = append(s.taskOrder, id)
+
+	writeJSON(w, http.StatusAccepted, t.Task)
+}
+
+// advance moves the task on to its next status, applying the change once the task completes.
+func (t *task) advance(inProgressPolls int) {
+	status := redis.StringValue(t.Status)
+	if status == tasks.StatusProcessingCompleted || status == tasks.StatusProcessingError {
+		return
+	}
+
+	t.polls++
+	t.Timestamp = redis.Time(time.Now().UTC())
+	if t.polls <= inProgressPolls {
+		t.Status = redis.String(tasks.StatusProcessingInProgress)
+		t.Description = redis.String("Task request is being processed")
+		return
+	}
+
+	result := t.apply()
+	t.Response = &tasks.Response{ID: result.id}
+	if result.err != nil {
+		t.Status = redis.String(tasks.StatusProcessingError)
+		t.Description = redis.String("Task request failed during processing. See error information for failure details.")
+		t.Response.Error = result.err
+		return
+	}
+
+	if result.resource != nil {
+		raw, _ := json.Marshal(result.resource)
+		resource := json.RawMessage(raw)
+		t.Response.Resource = &resource
+	}
+	t.Status = redis.String(tasks.StatusProcessingCompleted)
+	t.Description = redis.String("Request processing completed successfully and its resources are now being provisioned / de-provisioned.")
+}
+
+func (s *Server) serveTasks(w http.ResponseWriter, r *http.Request, segments []string) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	if len(segments) == 0 || segments[0] == "" {
+		list := []tasks.Task{}
+		for _, id := range s.taskOrder {
+			list = append(list, s.tasks[id].Task)
+		}
+		writeJSON(w, http.StatusOK, list)
+		return
+	}
+
+	t, ok := s.tasks[segments[0]]
+	if !ok || len(segments) > 1 {
+		writeError(w, http.StatusNotFound, "TASK_NOT_FOUND", fmt.Sprintf("Task %s was not found", segments[0]))
+		return
+	}
+
+	t.advance(s.inProgressPolls)
+	writeJSON(w, http.StatusOK, t.Task)
+}
@@ -2,6 +2,8 @@ package account
 
 import (
 	"context"
+
+	"github.com/RedisLabs/rediscloud-go-api/tracing"
 )
 
 type HttpClient interface {
@@ -10,14 +12,34 @@ type HttpClient interface {
 
 type API struct {
 	client HttpClient
+	tracer tracing.Tracer
+}
+
+// Option configures the optional behaviour of the API.
+type Option func(*API)
+
+// WithTracer records a span for every call to the API with `tracer`, instead of not recording any.
+func WithTracer(tracer tracing.Tracer) Option {
+	return func(a *API) {
+		if tracer != nil {
+			a.tracer = tracer
+		}
+	}
 }
 
-func NewAPI(client HttpClient) *API {
-	return &API{client: client}
+func NewAPI(client HttpClient, options ...Option) *API {
+	a := &API{client: client, tracer: tracing.Noop}
+	for _, option := range options {
+		option(a)
+	}
+	return a
 }
 
 // ListPaymentMethods will return the list of available payment methods.
-func (a *API) ListPaymentMethods(ctx context.Context) ([]*PaymentMethod, error) {
+func (a *API) ListPaymentMethods(ctx context.Context) (_ []*PaymentMethod, err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "list payment methods", "list payment methods")
+	defer func() { span.End(err) }()
+
 	var body paymentMethods
 	if err := a.client.Get(ctx, "list payment methods", "/payment-methods", &body); err != nil {
 		return nil, err
@@ -27,7 +49,10 @@ func (a *API) ListPaymentMethods(ctx context.Context) ([]*PaymentMethod, error)
 }
 
 // ListRegions will return the list of available regions.
-func (a *API) ListRegions(ctx context.Context) ([]*Region, error) {
+func (a *API) ListRegions(ctx context.Context) (_ []*Region, err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "list regions", "list regions")
+	defer func() { span.End(err) }()
+
 	var body regions
 	if err := a.client.Get(ctx, "list regions", "/regions", &body); err != nil {
 		return nil, err
@@ -37,7 +62,10 @@ func (a *API) ListRegions(ctx context.Context) ([]*Region, error) {
 }
 
 // ListDataPersistence will return the list of available data persistence values.
-func (a *API) ListDataPersistence(ctx context.Context) ([]*DataPersistence, error) {
+func (a *API) ListDataPersistence(ctx context.Context) (_ []*DataPersistence, err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "list data persistence", "list data persistence")
+	defer func() { span.End(err) }()
+
 	var body dataPersistence
 	if err := a.client.Get(ctx, "list data persistence", "/data-persistence", &body); err != nil {
 		return nil, err
@@ -47,7 +75,10 @@ func (a *API) ListDataPersistence(ctx context.Context) ([]*DataPersistence, erro
 }
 
 // ListDataModules will return the list of available data modules that can be applied to a database.
-func (a *API) ListDatabaseModules(ctx context.Context) ([]*DatabaseModule, error) {
+func (a *API) ListDatabaseModules(ctx context.Context) (_ []*DatabaseModule, err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "list database modules", "list database modules")
+	defer func() { span.End(err) }()
+
 	var body databaseModules
 	if err := a.client.Get(ctx, "list database modules", "/database-modules", &body); err != nil {
 		return nil, err
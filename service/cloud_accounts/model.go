@@ -3,6 +3,7 @@ package cloud_accounts
 import (
 	"fmt"
 
+	"github.com/RedisLabs/rediscloud-go-api/apierrors"
 	"github.com/RedisLabs/rediscloud-go-api/internal"
 )
 
@@ -34,23 +35,25 @@ func (o UpdateCloudAccount) String() string {
 }
 
 type NotFound struct {
-	id int
+	ID int
+	// Err is the error that the API responded with.
+	Err error
 }
 
 func (f *NotFound) Error() string {
-	return fmt.Sprintf("cloud account %d not found", f.id)
+	return fmt.Sprintf("cloud account %d not found", f.ID)
 }
 
-type listCloudAccounts struct {
-	CloudAccounts []*CloudAccount `json:"cloudAccounts"`
+func (f *NotFound) Is(target error) bool {
+	return target == apierrors.ErrNotFound
 }
 
-type taskResponse struct {
-	ID *string `json:"taskId,omitempty"`
+func (f *NotFound) Unwrap() error {
+	return f.Err
 }
 
-func (o taskResponse) String() string {
-	return internal.ToString(o)
+type listCloudAccounts struct {
+	CloudAccounts []*CloudAccount `json:"cloudAccounts"`
 }
 
 type CloudAccount struct {
@@ -4,14 +4,15 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strconv"
 
-	"github.com/RedisLabs/rediscloud-go-api/internal"
+	"github.com/RedisLabs/rediscloud-go-api/apierrors"
+	"github.com/RedisLabs/rediscloud-go-api/logging"
+	"github.com/RedisLabs/rediscloud-go-api/redis"
+	"github.com/RedisLabs/rediscloud-go-api/service/tasks"
+	"github.com/RedisLabs/rediscloud-go-api/tracing"
 )
 
-type Log interface {
-	Printf(format string, args ...interface{})
-}
-
 type HttpClient interface {
 	Get(ctx context.Context, name, path string, responseBody interface{}) error
 	Post(ctx context.Context, name, path string, requestBody interface{}, responseBody interface{}) error
@@ -27,41 +28,81 @@ type Task interface {
 type API struct {
 	client HttpClient
 	task   Task
-	logger Log
+	logger logging.Logger
+	tracer tracing.Tracer
 }
 
-func NewAPI(client HttpClient, task Task, logger Log) *API {
-	return &API{client: client, task: task, logger: logger}
+// Option configures the optional behaviour of the API.
+type Option func(*API)
+
+// WithTracer records a span for every call to the API with `tracer`, instead of not recording any.
+func WithTracer(tracer tracing.Tracer) Option {
+	return func(a *API) {
+		if tracer != nil {
+			a.tracer = tracer
+		}
+	}
+}
+
+func NewAPI(client HttpClient, task Task, logger logging.Logger, options ...Option) *API {
+	a := &API{client: client, task: task, logger: logger, tracer: tracing.Noop}
+	for _, option := range options {
+		option(a)
+	}
+	return a
 }
 
 // Create will create a new Cloud Account and return the identifier of the new account.
-func (a *API) Create(ctx context.Context, account CreateCloudAccount) (int, error) {
-	var response taskResponse
-	if err := a.client.Post(ctx, "cloud account", "/cloud-accounts", account, &response); err != nil {
+func (a *API) Create(ctx context.Context, account CreateCloudAccount) (_ int, err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "create cloud account", "create cloud account")
+	defer func() { span.End(err) }()
+
+	task, err := a.CreateAsync(ctx, account)
+	if err != nil {
 		return 0, err
 	}
 
-	a.logger.Printf("Waiting for task %s to finish creating the cloud account", response)
+	a.logger.Log(ctx, logging.LevelInfo, "Waiting for the new cloud account to finish being created", logging.KeyTaskID, redis.StringValue(task.ID))
 
-	id, err := a.task.WaitForResourceId(ctx, *response.ID)
+	id, err := a.task.WaitForResourceId(ctx, redis.StringValue(task.ID))
 	if err != nil {
-		return 0, err
+		return 0, apierrors.WithResource(err, apierrors.ResourceCloudAccount, "")
 	}
 
 	return id, nil
 }
 
-func (a API) List(ctx context.Context) ([]*CloudAccount, error) {
+// CreateAsync will start creating a new Cloud Account and return the task without waiting for it to complete. The
+// identifier of the account can be retrieved by waiting on the task.
+func (a *API) CreateAsync(ctx context.Context, account CreateCloudAccount) (_ *tasks.Task, err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "create cloud account", "create cloud account")
+	defer func() { span.End(err) }()
+
+	var task tasks.Task
+	if err := a.client.Post(ctx, "cloud account", "/cloud-accounts", account, &task); err != nil {
+		return nil, apierrors.WithResource(err, apierrors.ResourceCloudAccount, "")
+	}
+
+	return &task, nil
+}
+
+func (a API) List(ctx context.Context) (_ []*CloudAccount, err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "list cloud accounts", "list cloud accounts")
+	defer func() { span.End(err) }()
+
 	var response listCloudAccounts
 	if err := a.client.Get(ctx, "list cloud accounts", "/cloud-accounts", &response); err != nil {
-		return nil, err
+		return nil, apierrors.WithResource(err, apierrors.ResourceCloudAccount, "")
 	}
 
 	return response.CloudAccounts, nil
 }
 
 // Get will retrieve an existing Cloud Account.
-func (a *API) Get(ctx context.Context, id int) (*CloudAccount, error) {
+func (a *API) Get(ctx context.Context, id int) (_ *CloudAccount, err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "get cloud account", fmt.Sprintf("retrieve cloud account %d", id), logging.KeyCloudAccountID, id)
+	defer func() { span.End(err) }()
+
 	var response CloudAccount
 	if err := a.client.Get(ctx, fmt.Sprintf("retrieve cloud account %d", id), fmt.Sprintf("/cloud-accounts/%d", id), &response); err != nil {
 		return nil, wrap404Error(id, err)
@@ -71,41 +112,80 @@ func (a *API) Get(ctx context.Context, id int) (*CloudAccount, error) {
 }
 
 // Update will update certain values of an existing Cloud Account.
-func (a *API) Update(ctx context.Context, id int, account UpdateCloudAccount) error {
-	var response taskResponse
-	if err := a.client.Put(ctx, fmt.Sprintf("update cloud account %d", id), fmt.Sprintf("/cloud-accounts/%d", id), account, &response); err != nil {
-		return wrap404Error(id, err)
+func (a *API) Update(ctx context.Context, id int, account UpdateCloudAccount) (err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "update cloud account", fmt.Sprintf("update cloud account %d", id), logging.KeyCloudAccountID, id)
+	defer func() { span.End(err) }()
+
+	task, err := a.UpdateAsync(ctx, id, account)
+	if err != nil {
+		return err
 	}
 
-	a.logger.Printf("Waiting for cloud account %d to finish being updated", id)
+	a.logger.Log(ctx, logging.LevelInfo, "Waiting for the cloud account to finish being updated", logging.KeyTaskID, redis.StringValue(task.ID))
 
-	err := a.task.Wait(ctx, *response.ID)
+	err = a.task.Wait(ctx, redis.StringValue(task.ID))
 	if err != nil {
-		return fmt.Errorf("failed when updating account %d: %w", id, err)
+		return fmt.Errorf("failed when updating account %d: %w", id, withCloudAccount(id, err))
 	}
 
 	return nil
 }
 
+// UpdateAsync will start updating certain values of an existing Cloud Account and return the task without waiting
+// for it to complete.
+func (a *API) UpdateAsync(ctx context.Context, id int, account UpdateCloudAccount) (_ *tasks.Task, err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "update cloud account", fmt.Sprintf("update cloud account %d", id), logging.KeyCloudAccountID, id)
+	defer func() { span.End(err) }()
+
+	var task tasks.Task
+	if err := a.client.Put(ctx, fmt.Sprintf("update cloud account %d", id), fmt.Sprintf("/cloud-accounts/%d", id), account, &task); err != nil {
+		return nil, wrap404Error(id, err)
+	}
+
+	return &task, nil
+}
+
 // Delete will delete an existing Cloud Account.
-func (a *API) Delete(ctx context.Context, id int) error {
-	var response taskResponse
-	if err := a.client.Delete(ctx, fmt.Sprintf("delete cloud account %d", id), fmt.Sprintf("/cloud-accounts/%d", id), &response); err != nil {
-		return wrap404Error(id, err)
+func (a *API) Delete(ctx context.Context, id int) (err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "delete cloud account", fmt.Sprintf("delete cloud account %d", id), logging.KeyCloudAccountID, id)
+	defer func() { span.End(err) }()
+
+	task, err := a.DeleteAsync(ctx, id)
+	if err != nil {
+		return err
 	}
 
-	a.logger.Printf("Waiting for cloud account %d to finish being deleted", id)
+	a.logger.Log(ctx, logging.LevelInfo, "Waiting for the cloud account to finish being deleted", logging.KeyTaskID, redis.StringValue(task.ID))
 
-	if err := a.task.Wait(ctx, *response.ID); err != nil {
-		return fmt.Errorf("failed when deleting account %d: %w", id, err)
+	if err := a.task.Wait(ctx, redis.StringValue(task.ID)); err != nil {
+		return fmt.Errorf("failed when deleting account %d: %w", id, withCloudAccount(id, err))
 	}
 
 	return nil
 }
 
+// DeleteAsync will start deleting an existing Cloud Account and return the task without waiting for it to
+// complete.
+func (a *API) DeleteAsync(ctx context.Context, id int) (_ *tasks.Task, err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "delete cloud account", fmt.Sprintf("delete cloud account %d", id), logging.KeyCloudAccountID, id)
+	defer func() { span.End(err) }()
+
+	var task tasks.Task
+	if err := a.client.Delete(ctx, fmt.Sprintf("delete cloud account %d", id), fmt.Sprintf("/cloud-accounts/%d", id), &task); err != nil {
+		return nil, wrap404Error(id, err)
+	}
+
+	return &task, nil
+}
+
 func wrap404Error(id int, err error) error {
-	if v, ok := err.(*internal.HTTPError); ok && v.StatusCode == http.StatusNotFound {
-		return &NotFound{id: id}
+	err = withCloudAccount(id, err)
+	if v, ok := err.(*apierrors.HTTPError); ok && v.StatusCode == http.StatusNotFound {
+		return &NotFound{ID: id, Err: err}
 	}
 	return err
 }
+
+func withCloudAccount(id int, err error) error {
+	return apierrors.WithResource(err, apierrors.ResourceCloudAccount, strconv.Itoa(id))
+}
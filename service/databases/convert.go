@@ -0,0 +1,130 @@
+package databases
+
+import (
+	"sort"
+)
+
+// ToCreate returns a request that would create a copy of the database. Fields that the API doesn't return, such as
+// the client certificate and the periodic backup path, aren't set.
+func (o Database) ToCreate() CreateDatabase {
+	create := CreateDatabase{
+		Name:                 copyString(o.Name),
+		Protocol:             copyString(o.Protocol),
+		MemoryLimitInGB:      copyFloat64(o.MemoryLimitInGB),
+		SupportOSSClusterAPI: copyBool(o.SupportOSSClusterAPI),
+		DataPersistence:      copyString(o.DataPersistence),
+		DataEvictionPolicy:   copyString(o.DataEvictionPolicy),
+		Replication:          copyBool(o.Replication),
+	}
+
+	if o.ThroughputMeasurement != nil {
+		create.ThroughputMeasurement = &CreateThroughputMeasurement{
+			By:    copyString(o.ThroughputMeasurement.By),
+			Value: copyInt(o.ThroughputMeasurement.Value),
+		}
+	}
+	if o.ReplicaOf != nil {
+		create.ReplicaOf = copyStrings(o.ReplicaOf.Endpoints)
+	}
+	if o.Security != nil {
+		create.SourceIP = copyStrings(o.Security.SourceIPs)
+		create.Password = copyString(o.Security.Password)
+	}
+	for _, alert := range o.Alerts {
+		create.Alerts = append(create.Alerts, &CreateAlert{Name: copyString(alert.Name), Value: copyInt(alert.Value)})
+	}
+	for _, module := range o.Modules {
+		create.Modules = append(create.Modules, &CreateModule{Name: copyString(module.Name)})
+	}
+
+	return create
+}
+
+// ToUpdate returns an update that would set every field of the database that can be updated to its current value, to
+// be changed before being used. Fields that the API doesn't return, such as the client certificate and the periodic
+// backup path, aren't set.
+func (o Database) ToUpdate() UpdateDatabase {
+	update := UpdateDatabase{
+		Name:                 copyString(o.Name),
+		MemoryLimitInGB:      copyFloat64(o.MemoryLimitInGB),
+		SupportOSSClusterAPI: copyBool(o.SupportOSSClusterAPI),
+		DataEvictionPolicy:   copyString(o.DataEvictionPolicy),
+		Replication:          copyBool(o.Replication),
+		DataPersistence:      copyString(o.DataPersistence),
+	}
+
+	if o.ThroughputMeasurement != nil {
+		update.ThroughputMeasurement = &UpdateThroughputMeasurement{
+			By:    copyString(o.ThroughputMeasurement.By),
+			Value: copyInt(o.ThroughputMeasurement.Value),
+		}
+	}
+	if o.Clustering != nil && o.Clustering.RegexRules != nil {
+		rules := append([]*RegexRule{}, o.Clustering.RegexRules...)
+		sort.SliceStable(rules, func(i, j int) bool {
+			return rules[i].Ordinal < rules[j].Ordinal
+		})
+		update.RegexRules = []*string{}
+		for _, rule := range rules {
+			pattern := rule.Pattern
+			update.RegexRules = append(update.RegexRules, &pattern)
+		}
+	}
+	if o.ReplicaOf != nil {
+		update.ReplicaOf = copyStrings(o.ReplicaOf.Endpoints)
+	}
+	if o.Security != nil {
+		update.SourceIP = copyStrings(o.Security.SourceIPs)
+		update.Password = copyString(o.Security.Password)
+	}
+	for _, alert := range o.Alerts {
+		update.Alerts = append(update.Alerts, &UpdateAlert{Name: copyString(alert.Name), Value: copyInt(alert.Value)})
+	}
+
+	return update
+}
+
+// The conversions copy every value, so that changing the request doesn't change the database it came from.
+
+func copyString(v *string) *string {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
+
+func copyInt(v *int) *int {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
+
+func copyFloat64(v *float64) *float64 {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
+
+func copyBool(v *bool) *bool {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
+
+func copyStrings(v []*string) []*string {
+	if v == nil {
+		return nil
+	}
+	c := make([]*string, 0, len(v))
+	for _, s := range v {
+		c = append(c, copyString(s))
+	}
+	return c
+}
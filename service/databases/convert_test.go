@@ -0,0 +1,94 @@
+package databases
+
+import (
+	"testing"
+
+	"github.com/RedisLabs/rediscloud-go-api/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDatabase_ToCreate(t *testing.T) {
+	db := liveDatabase()
+	db.ReplicaOf = &ReplicaOf{Endpoints: redis.StringSlice("redis://example.com:6379")}
+
+	create := db.ToCreate()
+
+	assert.Equal(t, CreateDatabase{
+		Name:                  redis.String("cache"),
+		Protocol:              redis.String("redis"),
+		MemoryLimitInGB:       redis.Float64(1),
+		SupportOSSClusterAPI:  redis.Bool(false),
+		DataPersistence:       redis.String("none"),
+		DataEvictionPolicy:    redis.String("volatile-lru"),
+		Replication:           redis.Bool(true),
+		ThroughputMeasurement: &CreateThroughputMeasurement{By: redis.String("operations-per-second"), Value: redis.Int(10000)},
+		ReplicaOf:             redis.StringSlice("redis://example.com:6379"),
+		SourceIP:              redis.StringSlice("10.0.0.0/24", "10.1.0.0/24"),
+		Password:              redis.String("secret"),
+		Alerts: []*CreateAlert{
+			{Name: redis.String("dataset-size"), Value: redis.Int(80)},
+			{Name: redis.String("latency"), Value: redis.Int(5)},
+		},
+		Modules: []*CreateModule{{Name: redis.String("RedisJSON")}, {Name: redis.String("RediSearch")}},
+	}, create)
+	assert.True(t, CompareCreate(db, create).Empty())
+}
+
+func TestDatabase_ToUpdate(t *testing.T) {
+	db := liveDatabase()
+
+	update := db.ToUpdate()
+
+	assert.Equal(t, UpdateDatabase{
+		Name:                  redis.String("cache"),
+		MemoryLimitInGB:       redis.Float64(1),
+		SupportOSSClusterAPI:  redis.Bool(false),
+		DataEvictionPolicy:    redis.String("volatile-lru"),
+		Replication:           redis.Bool(true),
+		ThroughputMeasurement: &UpdateThroughputMeasurement{By: redis.String("operations-per-second"), Value: redis.Int(10000)},
+		RegexRules:            redis.StringSlice(".*\\{(?<tag>.*)\\}.*", ".*"),
+		DataPersistence:       redis.String("none"),
+		SourceIP:              redis.StringSlice("10.0.0.0/24", "10.1.0.0/24"),
+		Password:              redis.String("secret"),
+		Alerts: []*UpdateAlert{
+			{Name: redis.String("dataset-size"), Value: redis.Int(80)},
+			{Name: redis.String("latency"), Value: redis.Int(5)},
+		},
+	}, update)
+	assert.True(t, CompareUpdate(db, update).Empty())
+
+	// Changing the update leaves the database alone
+	*update.Name = "changed"
+	*update.ThroughputMeasurement.Value = 1
+	*update.SourceIP[0] = "0.0.0.0/0"
+	assert.Equal(t, liveDatabase(), db)
+}
+
+func TestChangedFields(t *testing.T) {
+	original := liveDatabase().ToUpdate()
+
+	changed := liveDatabase().ToUpdate()
+	changed.MemoryLimitInGB = redis.Float64(2)
+	changed.Alerts = append(changed.Alerts, &UpdateAlert{Name: redis.String("throughput-higher-than"), Value: redis.Int(100)})
+
+	patch, err := changedFields(original, changed)
+	assert.NoError(t, err)
+	assert.Equal(t, &UpdateDatabase{
+		MemoryLimitInGB: redis.Float64(2),
+		Alerts: []*UpdateAlert{
+			{Name: redis.String("dataset-size"), Value: redis.Int(80)},
+			{Name: redis.String("latency"), Value: redis.Int(5)},
+			{Name: redis.String("throughput-higher-than"), Value: redis.Int(100)},
+		},
+	}, patch)
+
+	patch, err = changedFields(original, liveDatabase().ToUpdate())
+	assert.NoError(t, err)
+	assert.Nil(t, patch)
+
+	cleared := liveDatabase().ToUpdate()
+	cleared.Name = nil
+	cleared.Password = nil
+	_, err = changedFields(original, cleared)
+	assert.EqualError(t, err, "fields can't be cleared: name, password")
+}
@@ -0,0 +1,333 @@
+package databases
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/RedisLabs/rediscloud-go-api/redis"
+)
+
+// Difference is a single field of a database that doesn't have its desired value.
+type Difference struct {
+	// Path of the field in `Database`, using the names of the JSON fields - such as `throughputMeasurement.value` or
+	// `alerts.dataset-size`.
+	Path string
+	// Live is the value of the field in the database, or nil if it isn't set. Lists are sorted when their order
+	// doesn't matter.
+	Live interface{}
+	// Desired is the value that the field should have, or nil if it shouldn't be set.
+	Desired interface{}
+	// Sensitive is true for fields, such as the password, whose values shouldn't be shown.
+	Sensitive bool
+}
+
+func (d Difference) String() string {
+	if d.Sensitive {
+		return fmt.Sprintf("%s: (sensitive value changed)", d.Path)
+	}
+	return fmt.Sprintf("%s: %s -> %s", d.Path, FormatValue(d.Live), FormatValue(d.Desired))
+}
+
+// Drift is the list of differences between a database and its desired configuration, ordered by path.
+type Drift []Difference
+
+// Empty reports whether the database has its desired configuration.
+func (d Drift) Empty() bool {
+	return len(d) == 0
+}
+
+func (d Drift) String() string {
+	var lines []string
+	for _, difference := range d {
+		lines = append(lines, difference.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// CompareCreate returns how the database differs from the request that would create it. Only the fields that are set
+// in the request are compared. Fields that the API never returns, such as `AverageItemSizeInBytes` and
+// `PeriodicBackupPath`, are ignored.
+func CompareCreate(live *Database, desired CreateDatabase) Drift {
+	c := comparison{live: live}
+	c.string("name", live.Name, desired.Name)
+	c.string("protocol", live.Protocol, desired.Protocol)
+	c.common(desiredFields{
+		memoryLimitInGB:      desired.MemoryLimitInGB,
+		supportOSSClusterAPI: desired.SupportOSSClusterAPI,
+		dataPersistence:      desired.DataPersistence,
+		dataEvictionPolicy:   desired.DataEvictionPolicy,
+		replication:          desired.Replication,
+		replicaOf:            desired.ReplicaOf,
+		sourceIP:             desired.SourceIP,
+		clientSSLCertificate: desired.ClientSSLCertificate,
+		password:             desired.Password,
+		throughput:           (*Throughput)(desired.ThroughputMeasurement),
+		alerts:               createAlerts(desired.Alerts),
+	})
+
+	if desired.Modules != nil {
+		var want, have []string
+		for _, module := range desired.Modules {
+			want = append(want, redis.StringValue(module.Name))
+		}
+		for _, module := range live.Modules {
+			have = append(have, redis.StringValue(module.Name))
+		}
+		c.set("modules", have, want)
+	}
+
+	return c.drift()
+}
+
+// CompareUpdate returns how the database differs from what it would be after the update. Only the fields that are set
+// in the update are compared. Fields that the API never returns, such as `PeriodicBackupPath`, are ignored.
+func CompareUpdate(live *Database, desired UpdateDatabase) Drift {
+	c := comparison{live: live}
+	c.string("name", live.Name, desired.Name)
+	c.common(desiredFields{
+		memoryLimitInGB:      desired.MemoryLimitInGB,
+		supportOSSClusterAPI: desired.SupportOSSClusterAPI,
+		dataPersistence:      desired.DataPersistence,
+		dataEvictionPolicy:   desired.DataEvictionPolicy,
+		replication:          desired.Replication,
+		replicaOf:            desired.ReplicaOf,
+		sourceIP:             desired.SourceIP,
+		clientSSLCertificate: desired.ClientSSLCertificate,
+		password:             desired.Password,
+		throughput:           (*Throughput)(desired.ThroughputMeasurement),
+		alerts:               updateAlerts(desired.Alerts),
+	})
+
+	if desired.RegexRules != nil {
+		var have []string
+		if live.Clustering != nil {
+			rules := append([]*RegexRule{}, live.Clustering.RegexRules...)
+			sort.SliceStable(rules, func(i, j int) bool {
+				return rules[i].Ordinal < rules[j].Ordinal
+			})
+			for _, rule := range rules {
+				have = append(have, rule.Pattern)
+			}
+		}
+		// The order of the rules matters, as the first rule that matches a key is used
+		want := redis.StringSliceValue(desired.RegexRules...)
+		if strings.Join(have, "\n") != strings.Join(want, "\n") {
+			c.add(Difference{Path: "clustering.regexRules", Live: list(have), Desired: list(want)})
+		}
+	}
+
+	return c.drift()
+}
+
+// desiredFields are the fields that `CreateDatabase` and `UpdateDatabase` have in common.
+type desiredFields struct {
+	memoryLimitInGB      *float64
+	supportOSSClusterAPI *bool
+	dataPersistence      *string
+	dataEvictionPolicy   *string
+	replication          *bool
+	replicaOf            []*string
+	sourceIP             []*string
+	clientSSLCertificate *string
+	password             *string
+	throughput           *Throughput
+	// alerts is nil if the alerts aren't being compared, rather than there not being any.
+	alerts []*Alert
+}
+
+func createAlerts(alerts []*CreateAlert) []*Alert {
+	if alerts == nil {
+		return nil
+	}
+	converted := []*Alert{}
+	for _, alert := range alerts {
+		converted = append(converted, (*Alert)(alert))
+	}
+	return converted
+}
+
+func updateAlerts(alerts []*UpdateAlert) []*Alert {
+	if alerts == nil {
+		return nil
+	}
+	converted := []*Alert{}
+	for _, alert := range alerts {
+		converted = append(converted, (*Alert)(alert))
+	}
+	return converted
+}
+
+type comparison struct {
+	live        *Database
+	differences Drift
+}
+
+func (c *comparison) add(difference Difference) {
+	c.differences = append(c.differences, difference)
+}
+
+func (c *comparison) drift() Drift {
+	sort.SliceStable(c.differences, func(i, j int) bool {
+		return c.differences[i].Path < c.differences[j].Path
+	})
+	return c.differences
+}
+
+func (c *comparison) common(desired desiredFields) {
+	live := c.live
+
+	if desired.memoryLimitInGB != nil && (live.MemoryLimitInGB == nil || *live.MemoryLimitInGB != *desired.memoryLimitInGB) {
+		c.add(Difference{Path: "memoryLimitInGb", Live: value(live.MemoryLimitInGB), Desired: *desired.memoryLimitInGB})
+	}
+	c.bool("supportOSSClusterApi", live.SupportOSSClusterAPI, desired.supportOSSClusterAPI)
+	c.string("dataPersistence", live.DataPersistence, desired.dataPersistence)
+	c.string("dataEvictionPolicy", live.DataEvictionPolicy, desired.dataEvictionPolicy)
+	c.bool("replication", live.Replication, desired.replication)
+
+	if desired.replicaOf != nil {
+		var have []*string
+		if live.ReplicaOf != nil {
+			have = live.ReplicaOf.Endpoints
+		}
+		c.set("replicaOf.endpoints", redis.StringSliceValue(have...), redis.StringSliceValue(desired.replicaOf...))
+	}
+
+	security := live.Security
+	if security == nil {
+		security = &Security{}
+	}
+
+	if desired.sourceIP != nil {
+		have, want := redis.StringSliceValue(security.SourceIPs...), redis.StringSliceValue(desired.sourceIP...)
+		// A database without any source IPs is open to all of them
+		if len(have) == 0 {
+			have = []string{openSourceIP}
+		}
+		if len(want) == 0 {
+			want = []string{openSourceIP}
+		}
+		c.set("security.sourceIps", have, want)
+	}
+
+	// Databases only report whether a client certificate is used, rather than the certificate itself
+	if desired.clientSSLCertificate != nil {
+		want := *desired.clientSSLCertificate != ""
+		c.bool("security.sslClientAuthentication", security.SSLClientAuthentication, &want)
+	}
+
+	if desired.password != nil && (security.Password == nil || *security.Password != *desired.password) {
+		c.add(Difference{Path: "security.password", Live: value(security.Password), Desired: *desired.password, Sensitive: true})
+	}
+
+	if desired.throughput != nil {
+		c.throughput(desired.throughput.By, desired.throughput.Value)
+	}
+	if desired.alerts != nil {
+		c.alerts(desired.alerts)
+	}
+}
+
+// The source IP range that allows connections from anywhere.
+const openSourceIP = "0.0.0.0/0"
+
+func (c *comparison) string(path string, live *string, desired *string) {
+	if desired != nil && (live == nil || *live != *desired) {
+		c.add(Difference{Path: path, Live: value(live), Desired: *desired})
+	}
+}
+
+func (c *comparison) bool(path string, live *bool, desired *bool) {
+	if desired != nil && redis.BoolValue(live) != *desired {
+		c.add(Difference{Path: path, Live: value(live), Desired: *desired})
+	}
+}
+
+func (c *comparison) throughput(by *string, val *int) {
+	live := c.live.ThroughputMeasurement
+	if live == nil {
+		live = &Throughput{}
+	}
+	c.string("throughputMeasurement.by", live.By, by)
+	if val != nil && (live.Value == nil || *live.Value != *val) {
+		c.add(Difference{Path: "throughputMeasurement.value", Live: value(live.Value), Desired: *val})
+	}
+}
+
+// alerts compares the alerts as a set, with a difference for each alert that is missing, unwanted or has a different
+// value.
+func (c *comparison) alerts(desired []*Alert) {
+	have, want := map[string]int{}, map[string]int{}
+	for _, alert := range c.live.Alerts {
+		have[redis.StringValue(alert.Name)] = redis.IntValue(alert.Value)
+	}
+	for _, alert := range desired {
+		want[redis.StringValue(alert.Name)] = redis.IntValue(alert.Value)
+	}
+
+	for name, wanted := range want {
+		if had, ok := have[name]; !ok {
+			c.add(Difference{Path: "alerts." + name, Desired: wanted})
+		} else if had != wanted {
+			c.add(Difference{Path: "alerts." + name, Live: had, Desired: wanted})
+		}
+	}
+	for name, had := range have {
+		if _, ok := want[name]; !ok {
+			c.add(Difference{Path: "alerts." + name, Live: had})
+		}
+	}
+}
+
+// set compares two lists whose order doesn't matter.
+func (c *comparison) set(path string, have []string, want []string) {
+	have, want = list(have), list(want)
+	sort.Strings(have)
+	sort.Strings(want)
+	if strings.Join(have, "\n") != strings.Join(want, "\n") {
+		c.add(Difference{Path: path, Live: have, Desired: want})
+	}
+}
+
+// list returns a copy of the values, which is never nil so that empty lists are reported consistently.
+func list(values []string) []string {
+	return append([]string{}, values...)
+}
+
+// value returns what a pointer points to, or nil.
+func value(v interface{}) interface{} {
+	switch p := v.(type) {
+	case *string:
+		if p != nil {
+			return *p
+		}
+	case *int:
+		if p != nil {
+			return *p
+		}
+	case *float64:
+		if p != nil {
+			return *p
+		}
+	case *bool:
+		if p != nil {
+			return *p
+		}
+	}
+	return nil
+}
+
+// FormatValue formats the live or desired value of a difference, such as `"redis"` or `["10.0.0.0/16"]`, or `(none)`
+// if it isn't set.
+func FormatValue(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return "(none)"
+	case string:
+		return fmt.Sprintf("%q", value)
+	case []string:
+		return fmt.Sprintf("%q", value)
+	default:
+		return fmt.Sprint(value)
+	}
+}
@@ -0,0 +1,139 @@
+package databases
+
+import (
+	"testing"
+
+	"github.com/RedisLabs/rediscloud-go-api/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+func liveDatabase() *Database {
+	return &Database{
+		ID:                   redis.Int(1),
+		Name:                 redis.String("cache"),
+		Protocol:             redis.String("redis"),
+		MemoryLimitInGB:      redis.Float64(1),
+		SupportOSSClusterAPI: redis.Bool(false),
+		DataPersistence:      redis.String("none"),
+		Replication:          redis.Bool(true),
+		DataEvictionPolicy:   redis.String("volatile-lru"),
+		ThroughputMeasurement: &Throughput{
+			By:    redis.String("operations-per-second"),
+			Value: redis.Int(10000),
+		},
+		Clustering: &Clustering{
+			RegexRules: []*RegexRule{
+				{Ordinal: 1, Pattern: ".*"},
+				{Ordinal: 0, Pattern: ".*\\{(?<tag>.*)\\}.*"},
+			},
+		},
+		Security: &Security{
+			SSLClientAuthentication: redis.Bool(false),
+			SourceIPs:               redis.StringSlice("10.0.0.0/24", "10.1.0.0/24"),
+			Password:                redis.String("secret"),
+		},
+		Modules: []*Module{{Name: redis.String("RedisJSON")}, {Name: redis.String("RediSearch")}},
+		Alerts: []*Alert{
+			{Name: redis.String("dataset-size"), Value: redis.Int(80)},
+			{Name: redis.String("latency"), Value: redis.Int(5)},
+		},
+	}
+}
+
+func TestCompareCreate_MatchesEquivalentConfiguration(t *testing.T) {
+	drift := CompareCreate(liveDatabase(), CreateDatabase{
+		Name:                  redis.String("cache"),
+		Protocol:              redis.String("redis"),
+		MemoryLimitInGB:       redis.Float64(1),
+		DataPersistence:       redis.String("none"),
+		Replication:           redis.Bool(true),
+		ThroughputMeasurement: &CreateThroughputMeasurement{By: redis.String("operations-per-second"), Value: redis.Int(10000)},
+		SourceIP:              redis.StringSlice("10.1.0.0/24", "10.0.0.0/24"),
+		ClientSSLCertificate:  redis.String(""),
+		Password:              redis.String("secret"),
+		Modules:               []*CreateModule{{Name: redis.String("RediSearch")}, {Name: redis.String("RedisJSON")}},
+		Alerts: []*CreateAlert{
+			{Name: redis.String("latency"), Value: redis.Int(5)},
+			{Name: redis.String("dataset-size"), Value: redis.Int(80)},
+		},
+	})
+
+	assert.True(t, drift.Empty(), drift.String())
+}
+
+func TestCompareCreate_ReportsEachDifference(t *testing.T) {
+	drift := CompareCreate(liveDatabase(), CreateDatabase{
+		MemoryLimitInGB:       redis.Float64(2),
+		DataEvictionPolicy:    redis.String("allkeys-lru"),
+		ThroughputMeasurement: &CreateThroughputMeasurement{By: redis.String("operations-per-second"), Value: redis.Int(20000)},
+		ReplicaOf:             redis.StringSlice("redis://example.com:6379"),
+		SourceIP:              redis.StringSlice("10.0.0.0/24"),
+		Password:              redis.String("changed"),
+		Modules:               []*CreateModule{{Name: redis.String("RedisJSON")}},
+		Alerts: []*CreateAlert{
+			{Name: redis.String("dataset-size"), Value: redis.Int(90)},
+			{Name: redis.String("throughput-higher-than"), Value: redis.Int(1000)},
+		},
+	})
+
+	assert.Equal(t, Drift{
+		{Path: "alerts.dataset-size", Live: 80, Desired: 90},
+		{Path: "alerts.latency", Live: 5},
+		{Path: "alerts.throughput-higher-than", Desired: 1000},
+		{Path: "dataEvictionPolicy", Live: "volatile-lru", Desired: "allkeys-lru"},
+		{Path: "memoryLimitInGb", Live: 1.0, Desired: 2.0},
+		{Path: "modules", Live: []string{"RediSearch", "RedisJSON"}, Desired: []string{"RedisJSON"}},
+		{Path: "replicaOf.endpoints", Live: []string{}, Desired: []string{"redis://example.com:6379"}},
+		{Path: "security.password", Live: "secret", Desired: "changed", Sensitive: true},
+		{Path: "security.sourceIps", Live: []string{"10.0.0.0/24", "10.1.0.0/24"}, Desired: []string{"10.0.0.0/24"}},
+		{Path: "throughputMeasurement.value", Live: 10000, Desired: 20000},
+	}, drift)
+
+	assert.Equal(t, `alerts.dataset-size: 80 -> 90
+alerts.latency: 5 -> (none)
+alerts.throughput-higher-than: (none) -> 1000
+dataEvictionPolicy: "volatile-lru" -> "allkeys-lru"
+memoryLimitInGb: 1 -> 2
+modules: ["RediSearch" "RedisJSON"] -> ["RedisJSON"]
+replicaOf.endpoints: [] -> ["redis://example.com:6379"]
+security.password: (sensitive value changed)
+security.sourceIps: ["10.0.0.0/24" "10.1.0.0/24"] -> ["10.0.0.0/24"]
+throughputMeasurement.value: 10000 -> 20000`, drift.String())
+}
+
+func TestCompareUpdate(t *testing.T) {
+	live := liveDatabase()
+
+	drift := CompareUpdate(live, UpdateDatabase{
+		Name:                 redis.String("cache"),
+		RegexRules:           redis.StringSlice(".*\\{(?<tag>.*)\\}.*", ".*"),
+		ClientSSLCertificate: redis.String("-----BEGIN CERTIFICATE-----"),
+		Alerts:               []*UpdateAlert{},
+	})
+
+	assert.Equal(t, Drift{
+		{Path: "alerts.dataset-size", Live: 80},
+		{Path: "alerts.latency", Live: 5},
+		{Path: "security.sslClientAuthentication", Live: false, Desired: true},
+	}, drift)
+
+	drift = CompareUpdate(live, UpdateDatabase{RegexRules: redis.StringSlice(".*", ".*\\{(?<tag>.*)\\}.*")})
+	assert.Equal(t, Drift{
+		{
+			Path:    "clustering.regexRules",
+			Live:    []string{".*\\{(?<tag>.*)\\}.*", ".*"},
+			Desired: []string{".*", ".*\\{(?<tag>.*)\\}.*"},
+		},
+	}, drift)
+}
+
+func TestCompareUpdate_NoSourceIPsAllowsAll(t *testing.T) {
+	live := liveDatabase()
+	live.Security.SourceIPs = nil
+
+	assert.Empty(t, CompareUpdate(live, UpdateDatabase{SourceIP: redis.StringSlice("0.0.0.0/0")}))
+	assert.Empty(t, CompareUpdate(&Database{}, UpdateDatabase{SourceIP: []*string{}, MemoryLimitInGB: nil}))
+	assert.Equal(t, Drift{
+		{Path: "memoryLimitInGb", Desired: 1.0},
+	}, CompareUpdate(&Database{}, UpdateDatabase{MemoryLimitInGB: redis.Float64(1)}))
+}
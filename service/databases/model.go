@@ -1,19 +1,13 @@
 package databases
 
 import (
+	"fmt"
 	"time"
 
+	"github.com/RedisLabs/rediscloud-go-api/apierrors"
 	"github.com/RedisLabs/rediscloud-go-api/internal"
 )
 
-type taskResponse struct {
-	ID *string `json:"taskId,omitempty"`
-}
-
-func (o taskResponse) String() string {
-	return internal.ToString(o)
-}
-
 type CreateDatabase struct {
 	DryRun                              *bool                        `json:"dryRun,omitempty"`
 	Name                                *string                      `json:"name,omitempty"`
@@ -205,6 +199,55 @@ func (o Import) String() string {
 	return internal.ToString(o)
 }
 
+// NotFound is returned when the database, or the subscription when creating or listing its databases, doesn't exist.
+type NotFound struct {
+	SubscriptionID int
+	// DatabaseID is 0 if it was the subscription that wasn't found.
+	DatabaseID int
+	// Err is the error that the API responded with.
+	Err error
+}
+
+func (f *NotFound) Error() string {
+	if f.DatabaseID == 0 {
+		return fmt.Sprintf("subscription %d not found", f.SubscriptionID)
+	}
+	return fmt.Sprintf("database %d in subscription %d not found", f.DatabaseID, f.SubscriptionID)
+}
+
+func (f *NotFound) Is(target error) bool {
+	return target == apierrors.ErrNotFound
+}
+
+func (f *NotFound) Unwrap() error {
+	return f.Err
+}
+
+// Modified is returned by `Patch` when the database was modified since the change was based on it.
+type Modified struct {
+	SubscriptionID int
+	DatabaseID     int
+	// LastModified is when the change expected the database to have last been modified.
+	LastModified *time.Time
+	// ModifiedSince is when the database was modified since.
+	ModifiedSince *time.Time
+}
+
+func (f *Modified) Error() string {
+	return fmt.Sprintf("database %d in subscription %d was modified at %s while being patched", f.DatabaseID, f.SubscriptionID, formatTime(f.ModifiedSince))
+}
+
+func (f *Modified) Is(target error) bool {
+	return target == apierrors.ErrConflict
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "an unknown time"
+	}
+	return t.Format(time.RFC3339)
+}
+
 type listDatabaseResponse struct {
 	Subscription []*listDbSubscription `json:"subscription,omitempty"`
 }
@@ -281,6 +324,13 @@ func DataEvictionPolicyValues() []string {
 	}
 }
 
+func ThroughputMeasurementByValues() []string {
+	return []string{
+		"operations-per-second",
+		"number-of-shards",
+	}
+}
+
 func SourceTypeValues() []string {
 	return []string{
 		"http",
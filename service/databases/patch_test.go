@@ -0,0 +1,163 @@
+package databases_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	rediscloud_api "github.com/RedisLabs/rediscloud-go-api"
+	"github.com/RedisLabs/rediscloud-go-api/apierrors"
+	"github.com/RedisLabs/rediscloud-go-api/redis"
+	"github.com/RedisLabs/rediscloud-go-api/rediscloudtest"
+	"github.com/RedisLabs/rediscloud-go-api/service/databases"
+	"github.com/RedisLabs/rediscloud-go-api/service/subscriptions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatch_UpdatesOnlyTheChangedFields(t *testing.T) {
+	fake := rediscloudtest.NewServer()
+	defer fake.Close()
+	client := newClient(t, fake)
+
+	sub := fake.AddSubscription(subscriptions.Subscription{Name: redis.String("example")})
+	db, err := fake.AddDatabase(sub, databases.Database{
+		Name:            redis.String("cache"),
+		MemoryLimitInGB: redis.Float64(1),
+		Security:        &databases.Security{Password: redis.String("secret")},
+		LastModified:    redis.Time(time.Now()),
+	})
+	require.NoError(t, err)
+
+	err = client.Database.Patch(context.Background(), sub, db, lastModified(fake, sub, db), func(update *databases.UpdateDatabase) {
+		update.MemoryLimitInGB = redis.Float64(2)
+		update.Alerts = append(update.Alerts, &databases.UpdateAlert{Name: redis.String("dataset-size"), Value: redis.Int(80)})
+	})
+	require.NoError(t, err)
+
+	actual := fake.Database(sub, db)
+	assert.Equal(t, 2.0, redis.Float64Value(actual.MemoryLimitInGB))
+	assert.Equal(t, "cache", redis.StringValue(actual.Name))
+	assert.Equal(t, "secret", redis.StringValue(actual.Security.Password))
+	require.Len(t, actual.Alerts, 1)
+	assert.Equal(t, 80, redis.IntValue(actual.Alerts[0].Value))
+}
+
+func TestPatch_DoesNothingWithoutChanges(t *testing.T) {
+	fake := rediscloudtest.NewServer()
+	defer fake.Close()
+	client := newClient(t, fake)
+
+	sub := fake.AddSubscription(subscriptions.Subscription{Name: redis.String("example")})
+	db, err := fake.AddDatabase(sub, databases.Database{Name: redis.String("cache"), MemoryLimitInGB: redis.Float64(1), LastModified: redis.Time(time.Now())})
+	require.NoError(t, err)
+	before := fake.Database(sub, db).LastModified
+
+	err = client.Database.Patch(context.Background(), sub, db, *before, func(update *databases.UpdateDatabase) {
+		update.MemoryLimitInGB = redis.Float64(1)
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, before, fake.Database(sub, db).LastModified)
+}
+
+func TestPatch_DetectsConcurrentModifications(t *testing.T) {
+	fake := rediscloudtest.NewServer()
+	defer fake.Close()
+	client := newClient(t, fake)
+	other := newClient(t, fake)
+
+	sub := fake.AddSubscription(subscriptions.Subscription{Name: redis.String("example")})
+	db, err := fake.AddDatabase(sub, databases.Database{Name: redis.String("cache"), MemoryLimitInGB: redis.Float64(1), LastModified: redis.Time(time.Now())})
+	require.NoError(t, err)
+
+	read, err := client.Database.Get(context.Background(), sub, db)
+	require.NoError(t, err)
+	require.NoError(t, other.Database.Update(context.Background(), sub, db, databases.UpdateDatabase{Name: redis.String("renamed")}))
+
+	err = client.Database.Patch(context.Background(), sub, db, *read.LastModified, func(update *databases.UpdateDatabase) {
+		t.Fatal("the change shouldn't be made to a database that was modified since it was read")
+	})
+
+	var modified *databases.Modified
+	require.True(t, errors.As(err, &modified), "got %v", err)
+	assert.True(t, errors.Is(err, apierrors.ErrConflict))
+	assert.Equal(t, sub, modified.SubscriptionID)
+	assert.Equal(t, db, modified.DatabaseID)
+	assert.True(t, read.LastModified.Equal(*modified.LastModified))
+	assert.True(t, fake.Database(sub, db).LastModified.Equal(*modified.ModifiedSince))
+
+	actual := fake.Database(sub, db)
+	assert.Equal(t, 1.0, redis.Float64Value(actual.MemoryLimitInGB))
+	assert.Equal(t, "renamed", redis.StringValue(actual.Name))
+}
+
+func TestPatch_RefusesToClearFields(t *testing.T) {
+	fake := rediscloudtest.NewServer()
+	defer fake.Close()
+	client := newClient(t, fake)
+
+	sub := fake.AddSubscription(subscriptions.Subscription{Name: redis.String("example")})
+	db, err := fake.AddDatabase(sub, databases.Database{
+		Name:            redis.String("cache"),
+		MemoryLimitInGB: redis.Float64(1),
+		Security:        &databases.Security{Password: redis.String("secret")},
+		LastModified:    redis.Time(time.Now()),
+	})
+	require.NoError(t, err)
+
+	err = client.Database.Patch(context.Background(), sub, db, lastModified(fake, sub, db), func(update *databases.UpdateDatabase) {
+		update.MemoryLimitInGB = redis.Float64(2)
+		update.Password = nil
+	})
+	assert.EqualError(t, err, fmt.Sprintf("database %d in subscription %d can't be patched: fields can't be cleared: password", db, sub))
+	assert.Equal(t, 1.0, redis.Float64Value(fake.Database(sub, db).MemoryLimitInGB))
+}
+
+func TestPatch_RefusesDatabasesWithoutLastModified(t *testing.T) {
+	fake := rediscloudtest.NewServer()
+	defer fake.Close()
+	client := newClient(t, fake)
+
+	sub := fake.AddSubscription(subscriptions.Subscription{Name: redis.String("example")})
+	db, err := fake.AddDatabase(sub, databases.Database{Name: redis.String("cache"), MemoryLimitInGB: redis.Float64(1)})
+	require.NoError(t, err)
+
+	err = client.Database.Patch(context.Background(), sub, db, time.Now(), func(update *databases.UpdateDatabase) {
+		t.Fatal("the change shouldn't be made when modifications can't be detected")
+	})
+	assert.EqualError(t, err, fmt.Sprintf("database %d in subscription %d can't be patched as it doesn't report when it was last modified", db, sub))
+	assert.Equal(t, 1.0, redis.Float64Value(fake.Database(sub, db).MemoryLimitInGB))
+}
+
+func TestPatch_NotFound(t *testing.T) {
+	fake := rediscloudtest.NewServer()
+	defer fake.Close()
+	client := newClient(t, fake)
+
+	sub := fake.AddSubscription(subscriptions.Subscription{Name: redis.String("example")})
+
+	err := client.Database.Patch(context.Background(), sub, 999, time.Now(), func(update *databases.UpdateDatabase) {
+		t.Fatal("the change shouldn't be made to a database that doesn't exist")
+	})
+	var notFound *databases.NotFound
+	require.True(t, errors.As(err, &notFound))
+	assert.Equal(t, sub, notFound.SubscriptionID)
+	assert.Equal(t, 999, notFound.DatabaseID)
+}
+
+func lastModified(fake *rediscloudtest.Server, subscription int, database int) time.Time {
+	return *fake.Database(subscription, database).LastModified
+}
+
+func newClient(t *testing.T, fake *rediscloudtest.Server) *rediscloud_api.Client {
+	client, err := rediscloud_api.NewClient(
+		rediscloud_api.BaseURL(fake.URL),
+		rediscloud_api.Auth("key", "secret"),
+		rediscloud_api.TaskPolling(rediscloud_api.TaskPollingPolicy{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond}),
+	)
+	require.NoError(t, err)
+	return client
+}
@@ -1,20 +1,24 @@
 package databases
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
-	"github.com/RedisLabs/rediscloud-go-api/internal"
+	"github.com/RedisLabs/rediscloud-go-api/apierrors"
+	"github.com/RedisLabs/rediscloud-go-api/logging"
 	"github.com/RedisLabs/rediscloud-go-api/redis"
+	"github.com/RedisLabs/rediscloud-go-api/service/tasks"
+	"github.com/RedisLabs/rediscloud-go-api/tracing"
 )
 
-type Log interface {
-	Printf(format string, args ...interface{})
-}
-
 type HttpClient interface {
 	Get(ctx context.Context, name, path string, responseBody interface{}) error
 	GetWithQuery(ctx context.Context, name, path string, query url.Values, responseBody interface{}) error
@@ -28,125 +32,474 @@ type Task interface {
 	Wait(ctx context.Context, id string) error
 }
 
+type BusyRetry interface {
+	Do(ctx context.Context, subscription int, request func() error) error
+}
+
+type Queue interface {
+	Lock(ctx context.Context, subscription int) (context.Context, func(), error)
+	Started(ctx context.Context, subscription int, task string)
+}
+
 type API struct {
 	client HttpClient
 	task   Task
-	logger Log
+	logger logging.Logger
+	tracer tracing.Tracer
+	busy   BusyRetry
+	queue  Queue
+}
+
+// Option configures the optional behaviour of the API.
+type Option func(*API)
+
+// WithBusyRetry uses `busy` to retry the changes that the API rejects because the subscription is busy, instead of
+// failing them straight away.
+func WithBusyRetry(busy BusyRetry) Option {
+	return func(a *API) {
+		if busy != nil {
+			a.busy = busy
+		}
+	}
 }
 
-func NewAPI(client HttpClient, task Task, logger Log) *API {
-	return &API{client: client, task: task, logger: logger}
+// WithQueue holds the subscription's lock in `queue` while making a change, so that only one change is made to each
+// subscription at a time.
+func WithQueue(queue Queue) Option {
+	return func(a *API) {
+		if queue != nil {
+			a.queue = queue
+		}
+	}
+}
+
+// WithTracer records a span for every call to the API with `tracer`, instead of not recording any.
+func WithTracer(tracer tracing.Tracer) Option {
+	return func(a *API) {
+		if tracer != nil {
+			a.tracer = tracer
+		}
+	}
 }
 
+func NewAPI(client HttpClient, task Task, logger logging.Logger, options ...Option) *API {
+	a := &API{client: client, task: task, logger: logger, tracer: tracing.Noop, busy: noBusyRetry{}, queue: noQueue{}}
+	for _, option := range options {
+		option(a)
+	}
+	return a
+}
+
+type noBusyRetry struct{}
+
+func (noBusyRetry) Do(_ context.Context, _ int, request func() error) error {
+	return request()
+}
+
+type noQueue struct{}
+
+func (noQueue) Lock(ctx context.Context, _ int) (context.Context, func(), error) {
+	return ctx, func() {}, nil
+}
+
+func (noQueue) Started(context.Context, int, string) {}
+
 // Create will create a new database for the subscription and return the identifier of the database.
-func (a *API) Create(ctx context.Context, subscription int, db CreateDatabase) (int, error) {
-	var task taskResponse
-	err := a.client.Post(ctx, fmt.Sprintf("create database for subscription %d", subscription), fmt.Sprintf("/subscriptions/%d/databases", subscription), db, &task)
+func (a *API) Create(ctx context.Context, subscription int, db CreateDatabase) (_ int, err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "create database", fmt.Sprintf("create database for subscription %d", subscription), logging.KeySubscriptionID, subscription)
+	defer func() { span.End(err) }()
+
+	ctx, unlock, err := a.queue.Lock(ctx, subscription)
 	if err != nil {
 		return 0, err
 	}
+	defer unlock()
 
-	a.logger.Printf("Waiting for new database for subscription %d to finish being created", subscription)
-
-	id, err := a.task.WaitForResourceId(ctx, *task.ID)
+	task, err := a.CreateAsync(ctx, subscription, db)
 	if err != nil {
 		return 0, err
 	}
 
+	a.logger.Log(ctx, logging.LevelInfo, "Waiting for the new database to finish being created", logging.KeyTaskID, redis.StringValue(task.ID))
+
+	id, err := a.task.WaitForResourceId(ctx, redis.StringValue(task.ID))
+	if err != nil {
+		return 0, withSubscription(subscription, err)
+	}
+
 	return id, nil
 }
 
+// CreateAsync will start creating a new database for the subscription and return the task without waiting for it
+// to complete. The identifier of the database can be retrieved by waiting on the task.
+func (a *API) CreateAsync(ctx context.Context, subscription int, db CreateDatabase) (_ *tasks.Task, err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "create database", fmt.Sprintf("create database for subscription %d", subscription), logging.KeySubscriptionID, subscription)
+	defer func() { span.End(err) }()
+
+	ctx, unlock, err := a.queue.Lock(ctx, subscription)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	var task tasks.Task
+	err = a.busy.Do(ctx, subscription, func() error {
+		return a.client.Post(ctx, fmt.Sprintf("create database for subscription %d", subscription), fmt.Sprintf("/subscriptions/%d/databases", subscription), db, &task)
+	})
+	if err != nil {
+		return nil, wrap404Error(subscription, 0, err)
+	}
+
+	a.queue.Started(ctx, subscription, redis.StringValue(task.ID))
+
+	return &task, nil
+}
+
 // List will return a ListDatabase that is capable of paging through all of the databases associated with a
 // subscription.
 func (a *API) List(ctx context.Context, subscription int) *ListDatabase {
-	return newListDatabase(ctx, a.client, subscription, 100)
+	return newListDatabase(ctx, a.client, a.tracer, subscription, 100)
 }
 
 // Get will retrieve an existing database.
-func (a *API) Get(ctx context.Context, subscription int, database int) (*Database, error) {
+func (a *API) Get(ctx context.Context, subscription int, database int) (_ *Database, err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "get database", fmt.Sprintf("get database %d for subscription %d", database, subscription), logging.KeySubscriptionID, subscription, logging.KeyDatabaseID, database)
+	defer func() { span.End(err) }()
+
 	var db Database
-	err := a.client.Get(ctx, fmt.Sprintf("get database %d for subscription %d", subscription, database), fmt.Sprintf("/subscriptions/%d/databases/%d", subscription, database), &db)
+	err = a.client.Get(ctx, fmt.Sprintf("get database %d for subscription %d", database, subscription), fmt.Sprintf("/subscriptions/%d/databases/%d", subscription, database), &db)
 	if err != nil {
-		return nil, err
+		return nil, wrap404Error(subscription, database, err)
 	}
 
 	return &db, nil
 }
 
 // Update will update certain values of an existing database.
-func (a *API) Update(ctx context.Context, subscription int, database int, update UpdateDatabase) error {
-	var task taskResponse
-	err := a.client.Put(ctx, fmt.Sprintf("update database %d for subscription %d", database, subscription), fmt.Sprintf("/subscriptions/%d/databases/%d", subscription, database), update, &task)
+func (a *API) Update(ctx context.Context, subscription int, database int, update UpdateDatabase) (err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "update database", fmt.Sprintf("update database %d for subscription %d", database, subscription), logging.KeySubscriptionID, subscription, logging.KeyDatabaseID, database)
+	defer func() { span.End(err) }()
+
+	ctx, unlock, err := a.queue.Lock(ctx, subscription)
 	if err != nil {
 		return err
 	}
+	defer unlock()
 
-	a.logger.Printf("Waiting for database %d for subscription %d to finish being updated", database, subscription)
-
-	err = a.task.Wait(ctx, *task.ID)
+	task, err := a.UpdateAsync(ctx, subscription, database, update)
 	if err != nil {
 		return err
 	}
 
+	a.logger.Log(ctx, logging.LevelInfo, "Waiting for the database to finish being updated", logging.KeyTaskID, redis.StringValue(task.ID))
+
+	err = a.task.Wait(ctx, redis.StringValue(task.ID))
+	if err != nil {
+		return withDatabase(subscription, database, err)
+	}
+
 	return nil
 }
 
-// Delete will destroy an existing database.
-func (a *API) Delete(ctx context.Context, subscription int, database int) error {
-	var task taskResponse
-	err := a.client.Delete(ctx, fmt.Sprintf("delete database %d/%d", subscription, database), fmt.Sprintf("/subscriptions/%d/databases/%d", subscription, database), &task)
+// UpdateAsync will start updating certain values of an existing database and return the task without waiting for
+// it to complete.
+func (a *API) UpdateAsync(ctx context.Context, subscription int, database int, update UpdateDatabase) (_ *tasks.Task, err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "update database", fmt.Sprintf("update database %d for subscription %d", database, subscription), logging.KeySubscriptionID, subscription, logging.KeyDatabaseID, database)
+	defer func() { span.End(err) }()
+
+	ctx, unlock, err := a.queue.Lock(ctx, subscription)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	var task tasks.Task
+	err = a.busy.Do(ctx, subscription, func() error {
+		return a.client.Put(ctx, fmt.Sprintf("update database %d for subscription %d", database, subscription), fmt.Sprintf("/subscriptions/%d/databases/%d", subscription, database), update, &task)
+	})
+	if err != nil {
+		return nil, wrap404Error(subscription, database, err)
+	}
+
+	a.queue.Started(ctx, subscription, redis.StringValue(task.ID))
+
+	return &task, nil
+}
+
+// Patch will read an existing database, apply the change to an update built from it with `ToUpdate`, and update
+// the database with only the fields that the change modified - doing nothing if there are none. `lastModified` is the
+// `LastModified` of the database that the change was based on: if the database has been modified since, a
+// `*Modified` error is returned without updating it. A database that doesn't report when it was last modified can't
+// be checked, so isn't patched. Fields can't be cleared by a patch, so setting one to nil returns an error.
+func (a *API) Patch(ctx context.Context, subscription int, database int, lastModified time.Time, change func(*UpdateDatabase)) (err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "patch database", fmt.Sprintf("patch database %d for subscription %d", database, subscription), logging.KeySubscriptionID, subscription, logging.KeyDatabaseID, database)
+	defer func() { span.End(err) }()
+
+	ctx, unlock, err := a.queue.Lock(ctx, subscription)
 	if err != nil {
 		return err
 	}
+	defer unlock()
 
-	a.logger.Printf("Waiting for database %d for subscription %d to finish being deleted", database, subscription)
+	db, err := a.Get(ctx, subscription, database)
+	if err != nil {
+		return err
+	}
+	if db.LastModified == nil {
+		return fmt.Errorf("database %d in subscription %d can't be patched as it doesn't report when it was last modified", database, subscription)
+	}
+	if !db.LastModified.Equal(lastModified) {
+		return &Modified{
+			SubscriptionID: subscription,
+			DatabaseID:     database,
+			LastModified:   &lastModified,
+			ModifiedSince:  db.LastModified,
+		}
+	}
+
+	update := db.ToUpdate()
+	change(&update)
 
-	err = a.task.Wait(ctx, *task.ID)
+	patch, err := changedFields(db.ToUpdate(), update)
+	if err != nil {
+		return fmt.Errorf("database %d in subscription %d can't be patched: %w", database, subscription, err)
+	}
+	if patch == nil {
+		a.logger.Log(ctx, logging.LevelInfo, "Database is unchanged by the patch")
+		return nil
+	}
+
+	return a.Update(ctx, subscription, database, *patch)
+}
+
+// changedFields returns an update with only the fields of `changed` that differ from `original`, or nil if there are
+// none. Fields that were cleared can't be sent to the API, so are returned as an error.
+func changedFields(original UpdateDatabase, changed UpdateDatabase) (*UpdateDatabase, error) {
+	var before, after map[string]json.RawMessage
+	if err := remarshal(original, &before); err != nil {
+		return nil, err
+	}
+	if err := remarshal(changed, &after); err != nil {
+		return nil, err
+	}
+
+	var cleared []string
+	for field := range before {
+		if _, ok := after[field]; !ok {
+			cleared = append(cleared, field)
+		}
+	}
+	if len(cleared) > 0 {
+		sort.Strings(cleared)
+		return nil, fmt.Errorf("fields can't be cleared: %s", strings.Join(cleared, ", "))
+	}
+
+	for field, value := range after {
+		if bytes.Equal(before[field], value) {
+			delete(after, field)
+		}
+	}
+	if len(after) == 0 {
+		return nil, nil
+	}
+
+	var patch UpdateDatabase
+	if err := remarshal(after, &patch); err != nil {
+		return nil, err
+	}
+	return &patch, nil
+}
+
+func remarshal(from interface{}, to interface{}) error {
+	data, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, to)
+}
+
+// Delete will destroy an existing database.
+func (a *API) Delete(ctx context.Context, subscription int, database int) (err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "delete database", fmt.Sprintf("delete database %d for subscription %d", database, subscription), logging.KeySubscriptionID, subscription, logging.KeyDatabaseID, database)
+	defer func() { span.End(err) }()
+
+	ctx, unlock, err := a.queue.Lock(ctx, subscription)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	task, err := a.DeleteAsync(ctx, subscription, database)
 	if err != nil {
 		return err
 	}
 
+	a.logger.Log(ctx, logging.LevelInfo, "Waiting for the database to finish being deleted", logging.KeyTaskID, redis.StringValue(task.ID))
+
+	err = a.task.Wait(ctx, redis.StringValue(task.ID))
+	if err != nil {
+		return withDatabase(subscription, database, err)
+	}
+
 	return nil
 }
 
+// DeleteAsync will start destroying an existing database and return the task without waiting for it to complete.
+func (a *API) DeleteAsync(ctx context.Context, subscription int, database int) (_ *tasks.Task, err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "delete database", fmt.Sprintf("delete database %d for subscription %d", database, subscription), logging.KeySubscriptionID, subscription, logging.KeyDatabaseID, database)
+	defer func() { span.End(err) }()
+
+	ctx, unlock, err := a.queue.Lock(ctx, subscription)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	var task tasks.Task
+	err = a.busy.Do(ctx, subscription, func() error {
+		return a.client.Delete(ctx, fmt.Sprintf("delete database %d/%d", subscription, database), fmt.Sprintf("/subscriptions/%d/databases/%d", subscription, database), &task)
+	})
+	if err != nil {
+		return nil, wrap404Error(subscription, database, err)
+	}
+
+	a.queue.Started(ctx, subscription, redis.StringValue(task.ID))
+
+	return &task, nil
+}
+
 // Backup will create a manual backup of the database to the destination the database has been configured to backup to.
-func (a *API) Backup(ctx context.Context, subscription int, database int) error {
-	var task taskResponse
-	err := a.client.Post(ctx, fmt.Sprintf("backup database %d for subscription %d", database, subscription), fmt.Sprintf("/subscriptions/%d/databases/%d/backup", subscription, database), nil, &task)
+func (a *API) Backup(ctx context.Context, subscription int, database int) (err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "backup database", fmt.Sprintf("backup database %d for subscription %d", database, subscription), logging.KeySubscriptionID, subscription, logging.KeyDatabaseID, database)
+	defer func() { span.End(err) }()
+
+	ctx, unlock, err := a.queue.Lock(ctx, subscription)
 	if err != nil {
 		return err
 	}
+	defer unlock()
 
-	a.logger.Printf("Waiting for backup of database %d for subscription %d to finish", database, subscription)
-
-	err = a.task.Wait(ctx, *task.ID)
+	task, err := a.BackupAsync(ctx, subscription, database)
 	if err != nil {
 		return err
 	}
 
+	a.logger.Log(ctx, logging.LevelInfo, "Waiting for the backup of the database to finish", logging.KeyTaskID, redis.StringValue(task.ID))
+
+	err = a.task.Wait(ctx, redis.StringValue(task.ID))
+	if err != nil {
+		return withDatabase(subscription, database, err)
+	}
+
 	return nil
 }
 
+// BackupAsync will start a manual backup of the database and return the task without waiting for it to complete.
+func (a *API) BackupAsync(ctx context.Context, subscription int, database int) (_ *tasks.Task, err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "backup database", fmt.Sprintf("backup database %d for subscription %d", database, subscription), logging.KeySubscriptionID, subscription, logging.KeyDatabaseID, database)
+	defer func() { span.End(err) }()
+
+	ctx, unlock, err := a.queue.Lock(ctx, subscription)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	var task tasks.Task
+	err = a.busy.Do(ctx, subscription, func() error {
+		return a.client.Post(ctx, fmt.Sprintf("backup database %d for subscription %d", database, subscription), fmt.Sprintf("/subscriptions/%d/databases/%d/backup", subscription, database), nil, &task)
+	})
+	if err != nil {
+		return nil, wrap404Error(subscription, database, err)
+	}
+
+	a.queue.Started(ctx, subscription, redis.StringValue(task.ID))
+
+	return &task, nil
+}
+
 // Import will import data from an RDB file or another Redis database into an existing database.
-func (a *API) Import(ctx context.Context, subscription int, database int, request Import) error {
-	var task taskResponse
-	err := a.client.Post(ctx, fmt.Sprintf("import database %d for subscription %d", database, subscription), fmt.Sprintf("/subscriptions/%d/databases/%d/import", subscription, database), request, &task)
+func (a *API) Import(ctx context.Context, subscription int, database int, request Import) (err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "import database", fmt.Sprintf("import database %d for subscription %d", database, subscription), logging.KeySubscriptionID, subscription, logging.KeyDatabaseID, database)
+	defer func() { span.End(err) }()
+
+	ctx, unlock, err := a.queue.Lock(ctx, subscription)
 	if err != nil {
 		return err
 	}
+	defer unlock()
 
-	a.logger.Printf("Waiting for import into database %d for subscription %d to finish", database, subscription)
-
-	err = a.task.Wait(ctx, *task.ID)
+	task, err := a.ImportAsync(ctx, subscription, database, request)
 	if err != nil {
 		return err
 	}
 
+	a.logger.Log(ctx, logging.LevelInfo, "Waiting for the import into the database to finish", logging.KeyTaskID, redis.StringValue(task.ID))
+
+	err = a.task.Wait(ctx, redis.StringValue(task.ID))
+	if err != nil {
+		return withDatabase(subscription, database, err)
+	}
+
 	return nil
 }
 
+// ImportAsync will start importing data into an existing database and return the task without waiting for it to
+// complete.
+func (a *API) ImportAsync(ctx context.Context, subscription int, database int, request Import) (_ *tasks.Task, err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "import database", fmt.Sprintf("import database %d for subscription %d", database, subscription), logging.KeySubscriptionID, subscription, logging.KeyDatabaseID, database)
+	defer func() { span.End(err) }()
+
+	ctx, unlock, err := a.queue.Lock(ctx, subscription)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	var task tasks.Task
+	err = a.busy.Do(ctx, subscription, func() error {
+		return a.client.Post(ctx, fmt.Sprintf("import database %d for subscription %d", database, subscription), fmt.Sprintf("/subscriptions/%d/databases/%d/import", subscription, database), request, &task)
+	})
+	if err != nil {
+		return nil, wrap404Error(subscription, database, err)
+	}
+
+	a.queue.Started(ctx, subscription, redis.StringValue(task.ID))
+
+	return &task, nil
+}
+
+// wrap404Error returns a `NotFound` if the request failed with a 404. A `database` of 0 is for requests that create
+// or list the subscription's databases.
+func wrap404Error(subscription int, database int, err error) error {
+	if database == 0 {
+		err = withSubscription(subscription, err)
+	} else {
+		err = withDatabase(subscription, database, err)
+	}
+	if v, ok := err.(*apierrors.HTTPError); ok && v.StatusCode == http.StatusNotFound {
+		return &NotFound{SubscriptionID: subscription, DatabaseID: database, Err: err}
+	}
+	return err
+}
+
+// subscriptionNotFound is the `ErrorCode` of the 404 that the API responds with when the subscription doesn't exist,
+// as opposed to it not having any more databases to list.
+const subscriptionNotFound = "SUBSCRIPTION_NOT_FOUND"
+
+// withSubscription sets the subscription as the resource of the error, for requests that create or list its
+// databases.
+func withSubscription(subscription int, err error) error {
+	return apierrors.WithResource(err, apierrors.ResourceSubscription, strconv.Itoa(subscription))
+}
+
+func withDatabase(subscription int, database int, err error) error {
+	return apierrors.WithResource(err, apierrors.ResourceDatabase, fmt.Sprintf("%d/%d", subscription, database))
+}
+
 type ListDatabase struct {
 	client       HttpClient
+	tracer       tracing.Tracer
 	subscription int
 	ctx          context.Context
 	pageSize     int
@@ -158,8 +511,8 @@ type ListDatabase struct {
 	value  *Database
 }
 
-func newListDatabase(ctx context.Context, client HttpClient, subscription int, pageSize int) *ListDatabase {
-	return &ListDatabase{client: client, subscription: subscription, ctx: ctx, pageSize: pageSize}
+func newListDatabase(ctx context.Context, client HttpClient, tracer tracing.Tracer, subscription int, pageSize int) *ListDatabase {
+	return &ListDatabase{client: client, tracer: tracer, subscription: subscription, ctx: ctx, pageSize: pageSize}
 }
 
 // Next attempts to retrieve the next page of databases and will return false if no more databases were found.
@@ -195,7 +548,17 @@ func (d *ListDatabase) Err() error {
 	return d.err
 }
 
-func (d *ListDatabase) nextPage() error {
+// nextPage retrieves the next page of databases, with a span for each page.
+func (d *ListDatabase) nextPage() (err error) {
+	ctx, span := tracing.StartOperation(d.ctx, d.tracer, "list databases", fmt.Sprintf("list databases for subscription %d", d.subscription), logging.KeySubscriptionID, d.subscription)
+	defer func() {
+		if lastPage(err) {
+			span.End(nil)
+			return
+		}
+		span.End(err)
+	}()
+
 	u := fmt.Sprintf("/subscriptions/%d/databases", d.subscription)
 	q := map[string][]string{
 		"limit":  {strconv.Itoa(d.pageSize)},
@@ -203,7 +566,7 @@ func (d *ListDatabase) nextPage() error {
 	}
 
 	var list listDatabaseResponse
-	err := d.client.GetWithQuery(d.ctx, fmt.Sprintf("list databases for %d", d.subscription), u, q, &list)
+	err = d.client.GetWithQuery(ctx, fmt.Sprintf("list databases for %d", d.subscription), u, q, &list)
 	if err != nil {
 		return err
 	}
@@ -223,13 +586,21 @@ func (d *ListDatabase) updateValue() {
 	d.page = d.page[1:]
 }
 
+// setError finishes the list if the API responded that there are no more databases, which it does with a 404, and
+// otherwise records the error.
 func (d *ListDatabase) setError(err error) {
-	if httpErr, ok := err.(*internal.HTTPError); ok && httpErr.StatusCode == http.StatusNotFound {
+	if lastPage(err) {
 		d.fin = true
 	} else {
-		d.err = err
+		d.err = wrap404Error(d.subscription, 0, err)
 	}
 
 	d.page = nil
 	d.value = nil
 }
+
+// lastPage reports whether the API responded that there are no more databases, rather than failing.
+func lastPage(err error) bool {
+	httpErr, ok := err.(*apierrors.HTTPError)
+	return ok && httpErr.StatusCode == http.StatusNotFound && httpErr.ErrorCode != subscriptionNotFound
+}
@@ -7,16 +7,18 @@ import (
 	"testing"
 
 	"github.com/RedisLabs/rediscloud-go-api/internal"
+	"github.com/RedisLabs/rediscloud-go-api/logging"
 	"github.com/RedisLabs/rediscloud-go-api/redis"
+	"github.com/RedisLabs/rediscloud-go-api/tracing"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
 func TestListDatabase_stopsOn404(t *testing.T) {
 	client := &mockHttpClient{}
-	subject := newListDatabase(context.TODO(), client, 5, 100)
+	subject := newListDatabase(context.TODO(), client, tracing.Noop, 5, 100)
 
-	client.On("GetWithQuery", context.TODO(), "list databases for 5", "/subscriptions/5/databases", url.Values{"limit": {"100"}, "offset": {"0"}}, mock.AnythingOfType("*databases.listDatabaseResponse")).Run(func(args mock.Arguments) {
+	client.On("GetWithQuery", mock.Anything, "list databases for 5", "/subscriptions/5/databases", url.Values{"limit": {"100"}, "offset": {"0"}}, mock.AnythingOfType("*databases.listDatabaseResponse")).Run(func(args mock.Arguments) {
 		response := args.Get(4).(*listDatabaseResponse)
 		response.Subscription = []*listDbSubscription{
 			{
@@ -44,7 +46,7 @@ func TestListDatabase_stopsOn404(t *testing.T) {
 		ID: redis.Int(2),
 	}, subject.Value())
 
-	client.On("GetWithQuery", context.TODO(), "list databases for 5", "/subscriptions/5/databases", url.Values{"limit": {"100"}, "offset": {"100"}}, mock.AnythingOfType("*databases.listDatabaseResponse")).Run(func(args mock.Arguments) {
+	client.On("GetWithQuery", mock.Anything, "list databases for 5", "/subscriptions/5/databases", url.Values{"limit": {"100"}, "offset": {"100"}}, mock.AnythingOfType("*databases.listDatabaseResponse")).Run(func(args mock.Arguments) {
 		response := args.Get(4).(*listDatabaseResponse)
 		response.Subscription = []*listDbSubscription{
 			{
@@ -64,7 +66,7 @@ func TestListDatabase_stopsOn404(t *testing.T) {
 		ID: redis.Int(3),
 	}, subject.Value())
 
-	client.On("GetWithQuery", context.TODO(), "list databases for 5", "/subscriptions/5/databases", url.Values{"limit": {"100"}, "offset": {"200"}}, mock.AnythingOfType("*databases.listDatabaseResponse")).
+	client.On("GetWithQuery", mock.Anything, "list databases for 5", "/subscriptions/5/databases", url.Values{"limit": {"100"}, "offset": {"200"}}, mock.AnythingOfType("*databases.listDatabaseResponse")).
 		Return(&internal.HTTPError{StatusCode: 404})
 
 	assert.False(t, subject.Next())
@@ -76,15 +78,40 @@ func TestListDatabase_recordsError(t *testing.T) {
 	client := &mockHttpClient{}
 
 	expected := fmt.Errorf("stop")
-	client.On("GetWithQuery", context.TODO(), "list databases for 5", "/subscriptions/5/databases", url.Values{"limit": {"1"}, "offset": {"0"}}, mock.AnythingOfType("*databases.listDatabaseResponse")).
+	client.On("GetWithQuery", mock.Anything, "list databases for 5", "/subscriptions/5/databases", url.Values{"limit": {"1"}, "offset": {"0"}}, mock.AnythingOfType("*databases.listDatabaseResponse")).
 		Return(expected)
 
-	subject := newListDatabase(context.TODO(), client, 5, 1)
+	subject := newListDatabase(context.TODO(), client, tracing.Noop, 5, 1)
 	assert.False(t, subject.Next())
 	assert.Equal(t, expected, subject.Err())
 	assert.Nil(t, subject.Value())
 }
 
+func TestListDatabase_returnsNotFoundForMissingSubscription(t *testing.T) {
+	client := &mockHttpClient{}
+
+	httpErr := &internal.HTTPError{StatusCode: 404, ErrorCode: "SUBSCRIPTION_NOT_FOUND"}
+	client.On("GetWithQuery", mock.Anything, "list databases for 5", "/subscriptions/5/databases", url.Values{"limit": {"1"}, "offset": {"0"}}, mock.AnythingOfType("*databases.listDatabaseResponse")).
+		Return(httpErr)
+
+	subject := newListDatabase(context.TODO(), client, tracing.Noop, 5, 1)
+	assert.False(t, subject.Next())
+	assert.Equal(t, &NotFound{SubscriptionID: 5, Err: httpErr}, subject.Err())
+	assert.Equal(t, "subscription 5 not found", subject.Err().Error())
+}
+
+func TestAPI_UpdateAsync_withoutOptions(t *testing.T) {
+	client := &mockHttpClient{}
+	client.On("Put", mock.Anything, "update database 3 for subscription 12", "/subscriptions/12/databases/3", UpdateDatabase{}, mock.AnythingOfType("*tasks.Task")).
+		Return(nil)
+
+	subject := NewAPI(client, nil, logging.Discard)
+
+	_, err := subject.UpdateAsync(context.TODO(), 12, 3, UpdateDatabase{})
+	assert.NoError(t, err)
+	client.AssertExpectations(t)
+}
+
 type mockHttpClient struct {
 	mock.Mock
 }
@@ -0,0 +1,132 @@
+package databases
+
+import (
+	"github.com/RedisLabs/rediscloud-go-api/internal"
+)
+
+// Validate checks the request for mistakes that the API would otherwise only report once the task to create the
+// database fails, returning a `*apierrors.ValidationError` listing each field that isn't valid.
+func (o CreateDatabase) Validate() error {
+	var v internal.Validator
+
+	v.Required("name", o.Name)
+	v.OneOf("protocol", o.Protocol, ProtocolValues())
+	if v.Required("memoryLimitInGb", o.MemoryLimitInGB) {
+		v.Positive("memoryLimitInGb", o.MemoryLimitInGB)
+	}
+	v.Positive("averageItemSizeInBytes", o.AverageItemSizeInBytes)
+	validateCommon(&v, commonFields{
+		dataPersistence:    o.DataPersistence,
+		dataEvictionPolicy: o.DataEvictionPolicy,
+		replicaOf:          o.ReplicaOf,
+		sourceIP:           o.SourceIP,
+	})
+
+	if o.ThroughputMeasurement != nil {
+		validateThroughput(&v, o.ThroughputMeasurement.By, o.ThroughputMeasurement.Value)
+	}
+	for i, alert := range o.Alerts {
+		if alert == nil {
+			v.Fail(internal.Index("alerts", i), "is required")
+			continue
+		}
+		validateAlert(&v, internal.Index("alerts", i), alert.Name, alert.Value)
+	}
+	for i, module := range o.Modules {
+		if module == nil {
+			v.Fail(internal.Index("modules", i), "is required")
+			continue
+		}
+		v.Required(internal.Index("modules", i)+".name", module.Name)
+	}
+
+	return v.Err()
+}
+
+// Validate checks the update for mistakes that the API would otherwise only report once the task to update the
+// database fails, returning a `*apierrors.ValidationError` listing each field that isn't valid.
+func (o UpdateDatabase) Validate() error {
+	var v internal.Validator
+
+	if o.Name != nil {
+		v.Required("name", o.Name)
+	}
+	v.Positive("memoryLimitInGb", o.MemoryLimitInGB)
+	validateCommon(&v, commonFields{
+		dataPersistence:    o.DataPersistence,
+		dataEvictionPolicy: o.DataEvictionPolicy,
+		replicaOf:          o.ReplicaOf,
+		sourceIP:           o.SourceIP,
+	})
+
+	if o.ThroughputMeasurement != nil {
+		validateThroughput(&v, o.ThroughputMeasurement.By, o.ThroughputMeasurement.Value)
+	}
+	for i, rule := range o.RegexRules {
+		v.Required(internal.Index("regexRules", i), rule)
+	}
+	for i, alert := range o.Alerts {
+		if alert == nil {
+			v.Fail(internal.Index("alerts", i), "is required")
+			continue
+		}
+		validateAlert(&v, internal.Index("alerts", i), alert.Name, alert.Value)
+	}
+
+	return v.Err()
+}
+
+// Validate checks that the source of the import is known and can be read from, returning a
+// `*apierrors.ValidationError` listing each field that isn't valid.
+func (o Import) Validate() error {
+	var v internal.Validator
+
+	if v.Required("sourceType", o.SourceType) {
+		v.OneOf("sourceType", o.SourceType, SourceTypeValues())
+	}
+	if len(o.ImportFromURI) == 0 {
+		v.Fail("importFromUri", "must have at least one URI")
+	}
+	for i, uri := range o.ImportFromURI {
+		if v.Required(internal.Index("importFromUri", i), uri) {
+			v.URI(internal.Index("importFromUri", i), uri)
+		}
+	}
+
+	return v.Err()
+}
+
+// commonFields are the fields that `CreateDatabase` and `UpdateDatabase` validate in the same way.
+type commonFields struct {
+	dataPersistence    *string
+	dataEvictionPolicy *string
+	replicaOf          []*string
+	sourceIP           []*string
+}
+
+func validateCommon(v *internal.Validator, fields commonFields) {
+	v.OneOf("dataPersistence", fields.dataPersistence, DataPersistenceValues())
+	v.OneOf("dataEvictionPolicy", fields.dataEvictionPolicy, DataEvictionPolicyValues())
+	for i, endpoint := range fields.replicaOf {
+		if v.Required(internal.Index("replicaOf", i), endpoint) {
+			v.URI(internal.Index("replicaOf", i), endpoint)
+		}
+	}
+	v.CIDRs("sourceIp", fields.sourceIP)
+}
+
+func validateThroughput(v *internal.Validator, by *string, value *int) {
+	if v.Required("throughputMeasurement.by", by) {
+		v.OneOf("throughputMeasurement.by", by, ThroughputMeasurementByValues())
+	}
+	if v.Required("throughputMeasurement.value", value) {
+		v.Positive("throughputMeasurement.value", value)
+	}
+}
+
+func validateAlert(v *internal.Validator, path string, name *string, value *int) {
+	if v.Required(path+".name", name) {
+		v.OneOf(path+".name", name, AlertNameValues())
+	}
+	v.Required(path+".value", value)
+}
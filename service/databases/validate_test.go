@@ -0,0 +1,80 @@
+package databases
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/RedisLabs/rediscloud-go-api/apierrors"
+	"github.com/RedisLabs/rediscloud-go-api/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateDatabase_Validate(t *testing.T) {
+	valid := CreateDatabase{
+		Name:                  redis.String("cache"),
+		Protocol:              redis.String("redis"),
+		MemoryLimitInGB:       redis.Float64(1),
+		DataEvictionPolicy:    redis.String("allkeys-lru"),
+		ThroughputMeasurement: &CreateThroughputMeasurement{By: redis.String("operations-per-second"), Value: redis.Int(1000)},
+		SourceIP:              redis.StringSlice("10.0.0.0/24"),
+		Alerts:                []*CreateAlert{{Name: redis.String("dataset-size"), Value: redis.Int(80)}},
+		Modules:               []*CreateModule{{Name: redis.String("RedisJSON")}},
+	}
+	assert.NoError(t, valid.Validate())
+
+	err := CreateDatabase{
+		Protocol:              redis.String("redis"),
+		MemoryLimitInGB:       redis.Float64(0),
+		DataEvictionPolicy:    redis.String("allkeys-lur"),
+		ThroughputMeasurement: &CreateThroughputMeasurement{By: redis.String("operations-per-second")},
+		SourceIP:              redis.StringSlice("10.0.0.0/24", "10.0.0.300/24"),
+		Alerts:                []*CreateAlert{{Name: redis.String("dataset-size"), Value: redis.Int(80)}, {Name: redis.String("dataset"), Value: redis.Int(1)}},
+		Modules:               []*CreateModule{{}},
+	}.Validate()
+
+	var validation *apierrors.ValidationError
+	require.True(t, errors.As(err, &validation))
+	assert.True(t, errors.Is(err, apierrors.ErrInvalid))
+
+	var paths []string
+	for _, field := range validation.Errors {
+		paths = append(paths, field.Path)
+	}
+	assert.Equal(t, []string{
+		"name",
+		"memoryLimitInGb",
+		"dataEvictionPolicy",
+		"sourceIp[1]",
+		"throughputMeasurement.value",
+		"alerts[1].name",
+		"modules[0].name",
+	}, paths)
+	assert.Contains(t, err.Error(), `dataEvictionPolicy must be one of ["allkeys-lru"`)
+}
+
+func TestUpdateDatabase_Validate(t *testing.T) {
+	assert.NoError(t, UpdateDatabase{}.Validate())
+	assert.NoError(t, UpdateDatabase{DataPersistence: redis.String("aof-every-write"), ReplicaOf: redis.StringSlice("redis://example.com:6379")}.Validate())
+
+	err := UpdateDatabase{
+		Name:            redis.String(""),
+		MemoryLimitInGB: redis.Float64(-1),
+		DataPersistence: redis.String("always"),
+		ReplicaOf:       redis.StringSlice("example.com:6379"),
+	}.Validate()
+	assert.EqualError(t, err, "invalid request: name is required, memoryLimitInGb must be greater than 0, not -1, "+
+		`dataPersistence must be one of ["none" "aof-every-1-second" "aof-every-write" "snapshot-every-1-hour" "snapshot-every-6-hours" "snapshot-every-12-hours"], not "always", `+
+		"replicaOf[0] must be an absolute URI")
+}
+
+func TestImport_Validate(t *testing.T) {
+	assert.NoError(t, Import{SourceType: redis.String("aws-s3"), ImportFromURI: redis.StringSlice("s3://bucket/dump.rdb")}.Validate())
+
+	err := Import{SourceType: redis.String("s3")}.Validate()
+	assert.EqualError(t, err, `invalid request: sourceType must be one of ["http" "redis" "ftp" "aws-s3" "azure-blob-storage" "google-blob-storage"], not "s3", importFromUri must have at least one URI`)
+
+	// The URI isn't included in the error, as it may contain credentials
+	err = Import{SourceType: redis.String("ftp"), ImportFromURI: redis.StringSlice("user:secret@example.com/dump.rdb")}.Validate()
+	assert.EqualError(t, err, "invalid request: importFromUri[0] must be an absolute URI")
+}
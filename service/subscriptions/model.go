@@ -3,6 +3,7 @@ package subscriptions
 import (
 	"fmt"
 
+	"github.com/RedisLabs/rediscloud-go-api/apierrors"
 	"github.com/RedisLabs/rediscloud-go-api/internal"
 )
 
@@ -208,11 +209,21 @@ func (o taskResponse) String() string {
 }
 
 type NotFound struct {
-	id int
+	ID int
+	// Err is the error that the API responded with.
+	Err error
 }
 
 func (f *NotFound) Error() string {
-	return fmt.Sprintf("subscription %d not found", f.id)
+	return fmt.Sprintf("subscription %d not found", f.ID)
+}
+
+func (f *NotFound) Is(target error) bool {
+	return target == apierrors.ErrNotFound
+}
+
+func (f *NotFound) Unwrap() error {
+	return f.Err
 }
 
 const (
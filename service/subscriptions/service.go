@@ -4,14 +4,15 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strconv"
 
-	"github.com/RedisLabs/rediscloud-go-api/internal"
+	"github.com/RedisLabs/rediscloud-go-api/apierrors"
+	"github.com/RedisLabs/rediscloud-go-api/logging"
+	"github.com/RedisLabs/rediscloud-go-api/redis"
+	"github.com/RedisLabs/rediscloud-go-api/service/tasks"
+	"github.com/RedisLabs/rediscloud-go-api/tracing"
 )
 
-type Log interface {
-	Printf(format string, args ...interface{})
-}
-
 type HttpClient interface {
 	Get(ctx context.Context, name, path string, responseBody interface{}) error
 	Post(ctx context.Context, name, path string, requestBody interface{}, responseBody interface{}) error
@@ -25,49 +26,134 @@ type Task interface {
 	Wait(ctx context.Context, id string) error
 }
 
+type BusyRetry interface {
+	Do(ctx context.Context, subscription int, request func() error) error
+}
+
+type Queue interface {
+	Lock(ctx context.Context, subscription int) (context.Context, func(), error)
+	Started(ctx context.Context, subscription int, task string)
+}
+
 type API struct {
 	client HttpClient
 	task   Task
-	logger Log
+	logger logging.Logger
+	tracer tracing.Tracer
+	busy   BusyRetry
+	queue  Queue
+}
+
+// Option configures the optional behaviour of the API.
+type Option func(*API)
+
+// WithBusyRetry uses `busy` to retry the changes that the API rejects because the subscription is busy, instead of
+// failing them straight away.
+func WithBusyRetry(busy BusyRetry) Option {
+	return func(a *API) {
+		if busy != nil {
+			a.busy = busy
+		}
+	}
+}
+
+// WithQueue holds the subscription's lock in `queue` while making a change, so that only one change is made to each
+// subscription at a time.
+func WithQueue(queue Queue) Option {
+	return func(a *API) {
+		if queue != nil {
+			a.queue = queue
+		}
+	}
+}
+
+// WithTracer records a span for every call to the API with `tracer`, instead of not recording any.
+func WithTracer(tracer tracing.Tracer) Option {
+	return func(a *API) {
+		if tracer != nil {
+			a.tracer = tracer
+		}
+	}
+}
+
+func NewAPI(client HttpClient, task Task, logger logging.Logger, options ...Option) *API {
+	a := &API{client: client, task: task, logger: logger, tracer: tracing.Noop, busy: noBusyRetry{}, queue: noQueue{}}
+	for _, option := range options {
+		option(a)
+	}
+	return a
 }
 
-func NewAPI(client HttpClient, task Task, logger Log) *API {
-	return &API{client: client, task: task, logger: logger}
+type noBusyRetry struct{}
+
+func (noBusyRetry) Do(_ context.Context, _ int, request func() error) error {
+	return request()
+}
+
+type noQueue struct{}
+
+func (noQueue) Lock(ctx context.Context, _ int) (context.Context, func(), error) {
+	return ctx, func() {}, nil
 }
 
+func (noQueue) Started(context.Context, int, string) {}
+
 // Create will create a new subscription.
-func (a *API) Create(ctx context.Context, subscription CreateSubscription) (int, error) {
-	var task taskResponse
-	err := a.client.Post(ctx, "create subscription", "/subscriptions", subscription, &task)
+func (a *API) Create(ctx context.Context, subscription CreateSubscription) (_ int, err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "create subscription", "create subscription")
+	defer func() { span.End(err) }()
+
+	task, err := a.CreateAsync(ctx, subscription)
 	if err != nil {
 		return 0, err
 	}
 
-	a.logger.Printf("Waiting for task %s to finish creating the subscription", task)
+	a.logger.Log(ctx, logging.LevelInfo, "Waiting for the new subscription to finish being created", logging.KeyTaskID, redis.StringValue(task.ID))
 
-	id, err := a.task.WaitForResourceId(ctx, *task.ID)
+	id, err := a.task.WaitForResourceId(ctx, redis.StringValue(task.ID))
 	if err != nil {
-		return 0, err
+		return 0, apierrors.WithResource(err, apierrors.ResourceSubscription, "")
 	}
 
 	return id, nil
 }
 
+// CreateAsync will start creating a new subscription and return the task without waiting for it to complete. The
+// identifier of the subscription can be retrieved by waiting on the task.
+func (a *API) CreateAsync(ctx context.Context, subscription CreateSubscription) (_ *tasks.Task, err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "create subscription", "create subscription")
+	defer func() { span.End(err) }()
+
+	var task tasks.Task
+	err = a.client.Post(ctx, "create subscription", "/subscriptions", subscription, &task)
+	if err != nil {
+		return nil, apierrors.WithResource(err, apierrors.ResourceSubscription, "")
+	}
+
+	return &task, nil
+}
+
 // List will list all of the current account's subscriptions.
-func (a API) List(ctx context.Context) ([]*Subscription, error) {
+func (a API) List(ctx context.Context) (_ []*Subscription, err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "list subscriptions", "list subscriptions")
+	defer func() { span.End(err) }()
+
 	var response listSubscriptionResponse
-	err := a.client.Get(ctx, "list subscriptions", "/subscriptions", &response)
+	err = a.client.Get(ctx, "list subscriptions", "/subscriptions", &response)
 	if err != nil {
-		return nil, err
+		return nil, apierrors.WithResource(err, apierrors.ResourceSubscription, "")
 	}
 
 	return response.Subscriptions, nil
 }
 
 // Get will retrieve an existing subscription.
-func (a *API) Get(ctx context.Context, id int) (*Subscription, error) {
+func (a *API) Get(ctx context.Context, id int) (_ *Subscription, err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "get subscription", fmt.Sprintf("retrieve subscription %d", id), logging.KeySubscriptionID, id)
+	defer func() { span.End(err) }()
+
 	var response Subscription
-	err := a.client.Get(ctx, fmt.Sprintf("retrieve subscription %d", id), fmt.Sprintf("/subscriptions/%d", id), &response)
+	err = a.client.Get(ctx, fmt.Sprintf("retrieve subscription %d", id), fmt.Sprintf("/subscriptions/%d", id), &response)
 	if err != nil {
 		return nil, wrap404Error(id, err)
 	}
@@ -76,57 +162,126 @@ func (a *API) Get(ctx context.Context, id int) (*Subscription, error) {
 }
 
 // Update will make changes to an existing subscription.
-func (a *API) Update(ctx context.Context, id int, subscription UpdateSubscription) error {
-	var task taskResponse
-	err := a.client.Put(ctx, fmt.Sprintf("update subscription %d", id), fmt.Sprintf("/subscriptions/%d", id), subscription, &task)
+func (a *API) Update(ctx context.Context, id int, subscription UpdateSubscription) (err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "update subscription", fmt.Sprintf("update subscription %d", id), logging.KeySubscriptionID, id)
+	defer func() { span.End(err) }()
+
+	ctx, unlock, err := a.queue.Lock(ctx, id)
 	if err != nil {
-		return wrap404Error(id, err)
+		return err
 	}
+	defer unlock()
 
-	a.logger.Printf("Waiting for task %s to finish creating the subscription", task)
+	task, err := a.UpdateAsync(ctx, id, subscription)
+	if err != nil {
+		return err
+	}
+
+	a.logger.Log(ctx, logging.LevelInfo, "Waiting for the subscription to finish being updated", logging.KeyTaskID, redis.StringValue(task.ID))
 
-	err = a.task.Wait(ctx, *task.ID)
+	err = a.task.Wait(ctx, redis.StringValue(task.ID))
 	if err != nil {
-		return fmt.Errorf("failed when updating subscription %d: %w", id, err)
+		return fmt.Errorf("failed when updating subscription %d: %w", id, withSubscription(id, err))
 	}
 
 	return nil
 }
 
+// UpdateAsync will start making changes to an existing subscription and return the task without waiting for it to
+// complete.
+func (a *API) UpdateAsync(ctx context.Context, id int, subscription UpdateSubscription) (_ *tasks.Task, err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "update subscription", fmt.Sprintf("update subscription %d", id), logging.KeySubscriptionID, id)
+	defer func() { span.End(err) }()
+
+	ctx, unlock, err := a.queue.Lock(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	var task tasks.Task
+	err = a.busy.Do(ctx, id, func() error {
+		return a.client.Put(ctx, fmt.Sprintf("update subscription %d", id), fmt.Sprintf("/subscriptions/%d", id), subscription, &task)
+	})
+	if err != nil {
+		return nil, wrap404Error(id, err)
+	}
+
+	a.queue.Started(ctx, id, redis.StringValue(task.ID))
+
+	return &task, nil
+}
+
 // Delete will destroy an existing subscription. All existing databases within the subscription should already be
 // deleted, otherwise this function will fail.
-func (a *API) Delete(ctx context.Context, id int) error {
-	var task taskResponse
-	err := a.client.Delete(ctx, fmt.Sprintf("delete subscription %d", id), fmt.Sprintf("/subscriptions/%d", id), &task)
+func (a *API) Delete(ctx context.Context, id int) (err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "delete subscription", fmt.Sprintf("delete subscription %d", id), logging.KeySubscriptionID, id)
+	defer func() { span.End(err) }()
+
+	ctx, unlock, err := a.queue.Lock(ctx, id)
 	if err != nil {
-		return wrap404Error(id, err)
+		return err
 	}
+	defer unlock()
 
-	a.logger.Printf("Waiting for subscription %d to finish being deleted", id)
-
-	err = a.task.Wait(ctx, *task.ID)
+	task, err := a.DeleteAsync(ctx, id)
 	if err != nil {
 		return err
 	}
 
+	a.logger.Log(ctx, logging.LevelInfo, "Waiting for the subscription to finish being deleted", logging.KeyTaskID, redis.StringValue(task.ID))
+
+	err = a.task.Wait(ctx, redis.StringValue(task.ID))
+	if err != nil {
+		return withSubscription(id, err)
+	}
+
 	return nil
 }
 
+// DeleteAsync will start destroying an existing subscription and return the task without waiting for it to
+// complete.
+func (a *API) DeleteAsync(ctx context.Context, id int) (_ *tasks.Task, err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "delete subscription", fmt.Sprintf("delete subscription %d", id), logging.KeySubscriptionID, id)
+	defer func() { span.End(err) }()
+
+	ctx, unlock, err := a.queue.Lock(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	var task tasks.Task
+	err = a.busy.Do(ctx, id, func() error {
+		return a.client.Delete(ctx, fmt.Sprintf("delete subscription %d", id), fmt.Sprintf("/subscriptions/%d", id), &task)
+	})
+	if err != nil {
+		return nil, wrap404Error(id, err)
+	}
+
+	a.queue.Started(ctx, id, redis.StringValue(task.ID))
+
+	return &task, nil
+}
+
 // GetCIDRAllowlist retrieves the CIDR addresses that are allowed to access an endpoint for a database associated with
 // a the subscription.
-func (a *API) GetCIDRAllowlist(ctx context.Context, id int) (*CIDRAllowlist, error) {
+func (a *API) GetCIDRAllowlist(ctx context.Context, id int) (_ *CIDRAllowlist, err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "get CIDR allowlist", fmt.Sprintf("get CIDR allowlist for subscription %d", id), logging.KeySubscriptionID, id)
+	defer func() { span.End(err) }()
+
 	var task taskResponse
-	err := a.client.Get(ctx, fmt.Sprintf("get cidr for subscription %d", id), fmt.Sprintf("/subscriptions/%d/cidr", id), &task)
+	err = a.client.Get(ctx, fmt.Sprintf("get cidr for subscription %d", id), fmt.Sprintf("/subscriptions/%d/cidr", id), &task)
 	if err != nil {
 		return nil, wrap404Error(id, err)
 	}
 
-	a.logger.Printf("Waiting for subscription %d CIDR allowlist to be retrieved", id)
+	a.logger.Log(ctx, logging.LevelInfo, "Waiting for the CIDR allowlist to be retrieved", logging.KeyTaskID, redis.StringValue(task.ID))
 
 	var response CIDRAllowlist
-	err = a.task.WaitForResource(ctx, *task.ID, &response)
+	err = a.task.WaitForResource(ctx, redis.StringValue(task.ID), &response)
 	if err != nil {
-		return nil, err
+		return nil, withSubscription(id, err)
 	}
 
 	return &response, nil
@@ -134,81 +289,192 @@ func (a *API) GetCIDRAllowlist(ctx context.Context, id int) (*CIDRAllowlist, err
 
 // UpdateCIDRAllowlist modifies the CIDR addresses that are allowed to access an endpoint for a database associated with
 // a the subscription.
-func (a *API) UpdateCIDRAllowlist(ctx context.Context, id int, cidr UpdateCIDRAllowlist) error {
-	var task taskResponse
-	err := a.client.Put(ctx, fmt.Sprintf("update cidr for subscription %d", id), fmt.Sprintf("/subscriptions/%d/cidr", id), cidr, &task)
+func (a *API) UpdateCIDRAllowlist(ctx context.Context, id int, cidr UpdateCIDRAllowlist) (err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "update CIDR allowlist", fmt.Sprintf("update CIDR allowlist for subscription %d", id), logging.KeySubscriptionID, id)
+	defer func() { span.End(err) }()
+
+	ctx, unlock, err := a.queue.Lock(ctx, id)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	task, err := a.UpdateCIDRAllowlistAsync(ctx, id, cidr)
 	if err != nil {
-		return wrap404Error(id, err)
+		return err
 	}
 
-	a.logger.Printf("Waiting for subscription %d CIDR allowlist to finish being updated", id)
+	a.logger.Log(ctx, logging.LevelInfo, "Waiting for the CIDR allowlist to finish being updated", logging.KeyTaskID, redis.StringValue(task.ID))
 
-	err = a.task.Wait(ctx, *task.ID)
+	err = a.task.Wait(ctx, redis.StringValue(task.ID))
 	if err != nil {
-		return err
+		return withSubscription(id, err)
 	}
 
 	return nil
 }
 
+// UpdateCIDRAllowlistAsync will start modifying the CIDR allowlist of the subscription and return the task without
+// waiting for it to complete.
+func (a *API) UpdateCIDRAllowlistAsync(ctx context.Context, id int, cidr UpdateCIDRAllowlist) (_ *tasks.Task, err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "update CIDR allowlist", fmt.Sprintf("update CIDR allowlist for subscription %d", id), logging.KeySubscriptionID, id)
+	defer func() { span.End(err) }()
+
+	ctx, unlock, err := a.queue.Lock(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	var task tasks.Task
+	err = a.busy.Do(ctx, id, func() error {
+		return a.client.Put(ctx, fmt.Sprintf("update cidr for subscription %d", id), fmt.Sprintf("/subscriptions/%d/cidr", id), cidr, &task)
+	})
+	if err != nil {
+		return nil, wrap404Error(id, err)
+	}
+
+	a.queue.Started(ctx, id, redis.StringValue(task.ID))
+
+	return &task, nil
+}
+
 // ListVPCPeering retrieves the VPCs that have been peered to the subscription VPC.
-func (a *API) ListVPCPeering(ctx context.Context, id int) ([]*VPCPeering, error) {
+func (a *API) ListVPCPeering(ctx context.Context, id int) (_ []*VPCPeering, err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "list VPC peerings", fmt.Sprintf("list VPC peerings for subscription %d", id), logging.KeySubscriptionID, id)
+	defer func() { span.End(err) }()
+
 	var task taskResponse
-	err := a.client.Get(ctx, fmt.Sprintf("get peerings for subscription %d", id), fmt.Sprintf("/subscriptions/%d/peerings", id), &task)
+	err = a.client.Get(ctx, fmt.Sprintf("get peerings for subscription %d", id), fmt.Sprintf("/subscriptions/%d/peerings", id), &task)
 	if err != nil {
 		return nil, wrap404Error(id, err)
 	}
 
-	a.logger.Printf("Waiting for subscription %d peering details to be retrieved", id)
+	a.logger.Log(ctx, logging.LevelInfo, "Waiting for the VPC peerings to be retrieved", logging.KeyTaskID, redis.StringValue(task.ID))
 
 	var peering listVpcPeering
-	err = a.task.WaitForResource(ctx, *task.ID, &peering)
+	err = a.task.WaitForResource(ctx, redis.StringValue(task.ID), &peering)
 	if err != nil {
-		return nil, err
+		return nil, withSubscription(id, err)
 	}
 
 	return peering.Peerings, nil
 }
 
 // CreateVPCPeering creates a new VPC peering from the subscription VPC and returns the identifier of the VPC peering.
-func (a *API) CreateVPCPeering(ctx context.Context, id int, create CreateVPCPeering) (int, error) {
-	var task taskResponse
-	err := a.client.Post(ctx, fmt.Sprintf("create peering for subscription %d", id), fmt.Sprintf("/subscriptions/%d/peerings", id), create, &task)
+func (a *API) CreateVPCPeering(ctx context.Context, id int, create CreateVPCPeering) (_ int, err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "create VPC peering", fmt.Sprintf("create VPC peering for subscription %d", id), logging.KeySubscriptionID, id)
+	defer func() { span.End(err) }()
+
+	ctx, unlock, err := a.queue.Lock(ctx, id)
 	if err != nil {
-		return 0, wrap404Error(id, err)
+		return 0, err
 	}
+	defer unlock()
 
-	a.logger.Printf("Waiting for subscription %d peering details to be retrieved", id)
-
-	id, err = a.task.WaitForResourceId(ctx, *task.ID)
+	task, err := a.CreateVPCPeeringAsync(ctx, id, create)
 	if err != nil {
 		return 0, err
 	}
 
-	return id, nil
+	a.logger.Log(ctx, logging.LevelInfo, "Waiting for the new VPC peering to finish being created", logging.KeyTaskID, redis.StringValue(task.ID))
+
+	peering, err := a.task.WaitForResourceId(ctx, redis.StringValue(task.ID))
+	if err != nil {
+		return 0, withSubscription(id, err)
+	}
+
+	return peering, nil
+}
+
+// CreateVPCPeeringAsync will start creating a new VPC peering from the subscription VPC and return the task without
+// waiting for it to complete.
+func (a *API) CreateVPCPeeringAsync(ctx context.Context, id int, create CreateVPCPeering) (_ *tasks.Task, err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "create VPC peering", fmt.Sprintf("create VPC peering for subscription %d", id), logging.KeySubscriptionID, id)
+	defer func() { span.End(err) }()
+
+	ctx, unlock, err := a.queue.Lock(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	var task tasks.Task
+	err = a.busy.Do(ctx, id, func() error {
+		return a.client.Post(ctx, fmt.Sprintf("create peering for subscription %d", id), fmt.Sprintf("/subscriptions/%d/peerings", id), create, &task)
+	})
+	if err != nil {
+		return nil, wrap404Error(id, err)
+	}
+
+	a.queue.Started(ctx, id, redis.StringValue(task.ID))
+
+	return &task, nil
 }
 
 // DeleteVPCPeering destroys an existing VPC peering connection.
-func (a *API) DeleteVPCPeering(ctx context.Context, subscription int, peering int) error {
-	var task taskResponse
-	err := a.client.Delete(ctx, fmt.Sprintf("deleting peering %d for subscription %d", peering, subscription), fmt.Sprintf("/subscriptions/%d/peerings/%d", subscription, peering), &task)
+func (a *API) DeleteVPCPeering(ctx context.Context, subscription int, peering int) (err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "delete VPC peering", fmt.Sprintf("delete VPC peering %d for subscription %d", peering, subscription), logging.KeySubscriptionID, subscription, logging.KeyPeeringID, peering)
+	defer func() { span.End(err) }()
+
+	ctx, unlock, err := a.queue.Lock(ctx, subscription)
 	if err != nil {
 		return err
 	}
+	defer unlock()
 
-	a.logger.Printf("Waiting for peering %d for subscription %d to be deleted", peering, subscription)
-
-	err = a.task.Wait(ctx, *task.ID)
+	task, err := a.DeleteVPCPeeringAsync(ctx, subscription, peering)
 	if err != nil {
 		return err
 	}
 
+	a.logger.Log(ctx, logging.LevelInfo, "Waiting for the VPC peering to finish being deleted", logging.KeyTaskID, redis.StringValue(task.ID))
+
+	err = a.task.Wait(ctx, redis.StringValue(task.ID))
+	if err != nil {
+		return withPeering(subscription, peering, err)
+	}
+
 	return nil
 }
 
+// DeleteVPCPeeringAsync will start destroying an existing VPC peering connection and return the task without
+// waiting for it to complete.
+func (a *API) DeleteVPCPeeringAsync(ctx context.Context, subscription int, peering int) (_ *tasks.Task, err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "delete VPC peering", fmt.Sprintf("delete VPC peering %d for subscription %d", peering, subscription), logging.KeySubscriptionID, subscription, logging.KeyPeeringID, peering)
+	defer func() { span.End(err) }()
+
+	ctx, unlock, err := a.queue.Lock(ctx, subscription)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	var task tasks.Task
+	err = a.busy.Do(ctx, subscription, func() error {
+		return a.client.Delete(ctx, fmt.Sprintf("deleting peering %d for subscription %d", peering, subscription), fmt.Sprintf("/subscriptions/%d/peerings/%d", subscription, peering), &task)
+	})
+	if err != nil {
+		return nil, withPeering(subscription, peering, err)
+	}
+
+	a.queue.Started(ctx, subscription, redis.StringValue(task.ID))
+
+	return &task, nil
+}
+
 func wrap404Error(id int, err error) error {
-	if v, ok := err.(*internal.HTTPError); ok && v.StatusCode == http.StatusNotFound {
-		return &NotFound{id: id}
+	err = withSubscription(id, err)
+	if v, ok := err.(*apierrors.HTTPError); ok && v.StatusCode == http.StatusNotFound {
+		return &NotFound{ID: id, Err: err}
 	}
 	return err
 }
+
+func withSubscription(id int, err error) error {
+	return apierrors.WithResource(err, apierrors.ResourceSubscription, strconv.Itoa(id))
+}
+
+func withPeering(subscription int, peering int, err error) error {
+	return apierrors.WithResource(err, apierrors.ResourceVPCPeering, fmt.Sprintf("%d/%d", subscription, peering))
+}
@@ -0,0 +1,116 @@
+package subscriptions
+
+import (
+	"regexp"
+
+	"github.com/RedisLabs/rediscloud-go-api/internal"
+	"github.com/RedisLabs/rediscloud-go-api/service/cloud_accounts"
+	"github.com/RedisLabs/rediscloud-go-api/service/databases"
+)
+
+// Validate checks the request for mistakes that the API would otherwise only report once the task to create the
+// subscription fails, returning a `*apierrors.ValidationError` listing each field that isn't valid.
+func (o CreateSubscription) Validate() error {
+	var v internal.Validator
+
+	v.OneOf("memoryStorage", o.MemoryStorage, databases.MemoryStorageValues())
+
+	if len(o.CloudProviders) == 0 {
+		v.Fail("cloudProviders", "must have at least one cloud provider")
+	}
+	for i, provider := range o.CloudProviders {
+		path := internal.Index("cloudProviders", i)
+		if provider == nil {
+			v.Fail(path, "is required")
+			continue
+		}
+		v.OneOf(path+".provider", provider.Provider, cloud_accounts.ProviderValues())
+		v.Positive(path+".cloudAccountId", provider.CloudAccountID)
+
+		if len(provider.Regions) == 0 {
+			v.Fail(path+".regions", "must have at least one region")
+		}
+		for j, region := range provider.Regions {
+			path := internal.Index(path+".regions", j)
+			if region == nil {
+				v.Fail(path, "is required")
+				continue
+			}
+			v.Required(path+".region", region.Region)
+			if region.Networking != nil {
+				v.CIDR(path+".networking.deploymentCIDR", region.Networking.DeploymentCIDR)
+			}
+		}
+	}
+
+	if len(o.Databases) == 0 {
+		v.Fail("databases", "must have at least one database")
+	}
+	for i, db := range o.Databases {
+		path := internal.Index("databases", i)
+		if db == nil {
+			v.Fail(path, "is required")
+			continue
+		}
+		v.Required(path+".name", db.Name)
+		v.OneOf(path+".protocol", db.Protocol, databases.ProtocolValues())
+		if v.Required(path+".memoryLimitInGb", db.MemoryLimitInGB) {
+			v.Positive(path+".memoryLimitInGb", db.MemoryLimitInGB)
+		}
+		v.OneOf(path+".dataPersistence", db.DataPersistence, databases.DataPersistenceValues())
+		if db.ThroughputMeasurement != nil {
+			v.OneOf(path+".throughputMeasurement.by", db.ThroughputMeasurement.By, databases.ThroughputMeasurementByValues())
+			v.Positive(path+".throughputMeasurement.value", db.ThroughputMeasurement.Value)
+		}
+		for j, module := range db.Modules {
+			if module == nil {
+				v.Fail(internal.Index(path+".modules", j), "is required")
+				continue
+			}
+			v.Required(internal.Index(path+".modules", j)+".name", module.Name)
+		}
+		v.Positive(path+".quantity", db.Quantity)
+		v.Positive(path+".averageItemSizeInBytes", db.AverageItemSizeInBytes)
+	}
+
+	return v.Err()
+}
+
+// Validate checks the allowlist for ranges that aren't valid, returning a `*apierrors.ValidationError` listing each
+// field that isn't valid.
+func (o UpdateCIDRAllowlist) Validate() error {
+	var v internal.Validator
+	v.CIDRs("cidrIps", o.CIDRIPs)
+	for i, id := range o.SecurityGroupIDs {
+		v.Required(internal.Index("securityGroupIds", i), id)
+	}
+	return v.Err()
+}
+
+// An AWS account ID is made of 12 digits.
+var awsAccountID = regexp.MustCompile(`^\d{12}$`)
+
+// Validate checks that the peering has the fields its provider needs, returning a `*apierrors.ValidationError` listing
+// each field that isn't valid.
+func (o CreateVPCPeering) Validate() error {
+	var v internal.Validator
+
+	v.OneOf("provider", o.Provider, cloud_accounts.ProviderValues())
+
+	if o.Provider != nil && *o.Provider == "GCP" {
+		v.Required("vpcProjectUid", o.VPCProjectUID)
+		v.Required("vpcNetworkName", o.VPCNetworkName)
+		return v.Err()
+	}
+
+	v.Required("region", o.Region)
+	if v.Required("awsAccountId", o.AWSAccountID) && !awsAccountID.MatchString(*o.AWSAccountID) {
+		v.Fail("awsAccountId", "must be 12 digits")
+	}
+	v.Required("vpcId", o.VPCId)
+	if v.Required("vpcCidr", o.VPCCidr) {
+		v.CIDR("vpcCidr", o.VPCCidr)
+	}
+
+	return v.Err()
+}
@@ -0,0 +1,76 @@
+package subscriptions
+
+import (
+	"testing"
+
+	"github.com/RedisLabs/rediscloud-go-api/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateSubscription_Validate(t *testing.T) {
+	valid := CreateSubscription{
+		Name:          redis.String("example"),
+		MemoryStorage: redis.String("ram"),
+		CloudProviders: []*CreateCloudProvider{
+			{
+				Provider: redis.String("AWS"),
+				Regions: []*CreateRegion{
+					{Region: redis.String("us-east-1"), Networking: &CreateNetworking{DeploymentCIDR: redis.String("10.0.0.0/24")}},
+				},
+			},
+		},
+		Databases: []*CreateDatabase{
+			{Name: redis.String("cache"), MemoryLimitInGB: redis.Float64(1)},
+		},
+	}
+	assert.NoError(t, valid.Validate())
+
+	err := CreateSubscription{
+		MemoryStorage: redis.String("disk"),
+		CloudProviders: []*CreateCloudProvider{
+			{
+				Provider: redis.String("Azure"),
+				Regions: []*CreateRegion{
+					{Region: redis.String("us-east-1"), Networking: &CreateNetworking{DeploymentCIDR: redis.String("10.0.0.0")}},
+					{},
+				},
+			},
+		},
+		Databases: []*CreateDatabase{
+			{Name: redis.String("cache"), Protocol: redis.String("redis"), MemoryLimitInGB: redis.Float64(1), ThroughputMeasurement: &CreateThroughput{By: redis.String("ops")}},
+		},
+	}.Validate()
+	assert.EqualError(t, err, `invalid request: memoryStorage must be one of ["ram" "ram-and-flash"], not "disk", `+
+		`cloudProviders[0].provider must be one of ["AWS" "GCP"], not "Azure", `+
+		`cloudProviders[0].regions[0].networking.deploymentCIDR must be a CIDR range such as 10.0.0.0/24, not "10.0.0.0", `+
+		`cloudProviders[0].regions[1].region is required, `+
+		`databases[0].throughputMeasurement.by must be one of ["operations-per-second" "number-of-shards"], not "ops"`)
+
+	assert.EqualError(t, CreateSubscription{}.Validate(),
+		"invalid request: cloudProviders must have at least one cloud provider, databases must have at least one database")
+}
+
+func TestCreateVPCPeering_Validate(t *testing.T) {
+	assert.NoError(t, CreateVPCPeering{
+		Region:       redis.String("us-east-1"),
+		AWSAccountID: redis.String("123456789012"),
+		VPCId:        redis.String("vpc-1"),
+		VPCCidr:      redis.String("10.1.0.0/24"),
+	}.Validate())
+	assert.NoError(t, CreateVPCPeering{
+		Provider:       redis.String("GCP"),
+		VPCProjectUID:  redis.String("project"),
+		VPCNetworkName: redis.String("network"),
+	}.Validate())
+
+	assert.EqualError(t, CreateVPCPeering{AWSAccountID: redis.String("1234"), VPCCidr: redis.String("10.1.0.0")}.Validate(),
+		`invalid request: region is required, awsAccountId must be 12 digits, vpcId is required, vpcCidr must be a CIDR range such as 10.0.0.0/24, not "10.1.0.0"`)
+	assert.EqualError(t, CreateVPCPeering{Provider: redis.String("GCP")}.Validate(),
+		"invalid request: vpcProjectUid is required, vpcNetworkName is required")
+}
+
+func TestUpdateCIDRAllowlist_Validate(t *testing.T) {
+	assert.NoError(t, UpdateCIDRAllowlist{CIDRIPs: redis.StringSlice("10.0.0.0/24"), SecurityGroupIDs: redis.StringSlice("sg-1")}.Validate())
+	assert.EqualError(t, UpdateCIDRAllowlist{CIDRIPs: redis.StringSlice("10.0.0.0/24", "everywhere"), SecurityGroupIDs: redis.StringSlice("")}.Validate(),
+		`invalid request: cidrIps[1] must be a CIDR range such as 10.0.0.0/24, not "everywhere", securityGroupIds[0] is required`)
+}
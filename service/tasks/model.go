@@ -0,0 +1,45 @@
+package tasks
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/RedisLabs/rediscloud-go-api/apierrors"
+	"github.com/RedisLabs/rediscloud-go-api/internal"
+)
+
+type Task struct {
+	ID          *string    `json:"taskId,omitempty"`
+	CommandType *string    `json:"commandType,omitempty"`
+	Status      *string    `json:"status,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	Timestamp   *time.Time `json:"timestamp,omitempty"`
+	Response    *Response  `json:"response,omitempty"`
+}
+
+func (o Task) String() string {
+	return internal.ToString(o)
+}
+
+type Response struct {
+	ID       *int             `json:"resourceId,omitempty"`
+	Resource *json.RawMessage `json:"resource,omitempty"`
+	Error    *apierrors.Error `json:"error,omitempty"`
+}
+
+func (o Response) String() string {
+	return internal.ToString(o)
+}
+
+const (
+	// Initialized value of the `Status` field in `Task`
+	StatusInitialized = "initialized"
+	// Received value of the `Status` field in `Task`
+	StatusReceived = "received"
+	// Processing in progress value of the `Status` field in `Task`
+	StatusProcessingInProgress = "processing-in-progress"
+	// Processing completed value of the `Status` field in `Task`
+	StatusProcessingCompleted = "processing-completed"
+	// Processing error value of the `Status` field in `Task`
+	StatusProcessingError = "processing-error"
+)
@@ -0,0 +1,99 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/RedisLabs/rediscloud-go-api/apierrors"
+	"github.com/RedisLabs/rediscloud-go-api/logging"
+	"github.com/RedisLabs/rediscloud-go-api/tracing"
+)
+
+type HttpClient interface {
+	Get(ctx context.Context, name, path string, responseBody interface{}) error
+}
+
+type Waiter interface {
+	WaitForResourceId(ctx context.Context, id string) (int, error)
+	WaitForResource(ctx context.Context, id string, resource interface{}) error
+	Wait(ctx context.Context, id string) error
+}
+
+type API struct {
+	client HttpClient
+	waiter Waiter
+	tracer tracing.Tracer
+}
+
+// Option configures the optional behaviour of the API.
+type Option func(*API)
+
+// WithTracer records a span for every call to the API with `tracer`, instead of not recording any.
+func WithTracer(tracer tracing.Tracer) Option {
+	return func(a *API) {
+		if tracer != nil {
+			a.tracer = tracer
+		}
+	}
+}
+
+func NewAPI(client HttpClient, waiter Waiter, options ...Option) *API {
+	a := &API{client: client, waiter: waiter, tracer: tracing.Noop}
+	for _, option := range options {
+		option(a)
+	}
+	return a
+}
+
+// Get will retrieve the current state of an existing task.
+func (a *API) Get(ctx context.Context, id string) (_ *Task, err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "get task", fmt.Sprintf("retrieve task %s", id), logging.KeyTaskID, id)
+	defer func() { span.End(err) }()
+
+	var task Task
+	if err := a.client.Get(ctx, fmt.Sprintf("retrieve task %s", id), "/tasks/"+url.PathEscape(id), &task); err != nil {
+		return nil, apierrors.WithResource(err, apierrors.ResourceTask, id)
+	}
+
+	return &task, nil
+}
+
+// List will list all of the current account's recent tasks.
+func (a *API) List(ctx context.Context) (_ []*Task, err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "list tasks", "list tasks")
+	defer func() { span.End(err) }()
+
+	var tasks []*Task
+	if err := a.client.Get(ctx, "list tasks", "/tasks", &tasks); err != nil {
+		return nil, apierrors.WithResource(err, apierrors.ResourceTask, "")
+	}
+
+	return tasks, nil
+}
+
+// Wait will poll the task until it has finished processing, returning an error if the task failed.
+func (a *API) Wait(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "wait for task", fmt.Sprintf("wait for task %s", id), logging.KeyTaskID, id)
+	defer func() { span.End(err) }()
+
+	return a.waiter.Wait(ctx, id)
+}
+
+// WaitForResourceId will poll the task until it has finished processing, returning the identifier of the resource
+// that the task created or modified.
+func (a *API) WaitForResourceId(ctx context.Context, id string) (_ int, err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "wait for task", fmt.Sprintf("wait for task %s", id), logging.KeyTaskID, id)
+	defer func() { span.End(err) }()
+
+	return a.waiter.WaitForResourceId(ctx, id)
+}
+
+// WaitForResource will poll the task until it has finished processing, unmarshalling the resource returned by the
+// task into the value pointed to by `resource`.
+func (a *API) WaitForResource(ctx context.Context, id string, resource interface{}) (err error) {
+	ctx, span := tracing.StartOperation(ctx, a.tracer, "wait for task", fmt.Sprintf("wait for task %s", id), logging.KeyTaskID, id)
+	defer func() { span.End(err) }()
+
+	return a.waiter.WaitForResource(ctx, id, resource)
+}
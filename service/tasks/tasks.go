@@ -0,0 +1,3 @@
+// Package tasks allows the retrieval of the asynchronous tasks that are created by the other services and waiting
+// for those tasks to complete.
+package tasks
@@ -2,10 +2,12 @@ package rediscloud_api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/RedisLabs/rediscloud-go-api/apierrors"
 	"github.com/RedisLabs/rediscloud-go-api/redis"
 	"github.com/RedisLabs/rediscloud-go-api/service/subscriptions"
 	"github.com/stretchr/testify/assert"
@@ -403,7 +405,10 @@ func TestSubscription_Get_wraps404Error(t *testing.T) {
 	actual, err := subject.Subscription.Get(context.TODO(), 123)
 
 	assert.Nil(t, actual)
-	assert.IsType(t, &subscriptions.NotFound{}, err)
+	var notFound *subscriptions.NotFound
+	require.True(t, errors.As(err, &notFound))
+	assert.Equal(t, 123, notFound.ID)
+	assert.True(t, errors.Is(err, apierrors.ErrNotFound))
 }
 
 func TestSubscription_Update(t *testing.T) {
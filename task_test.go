@@ -3,11 +3,15 @@ package rediscloud_api
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/RedisLabs/rediscloud-go-api/apierrors"
 	"github.com/RedisLabs/rediscloud-go-api/internal"
 	"github.com/RedisLabs/rediscloud-go-api/redis"
+	"github.com/RedisLabs/rediscloud-go-api/service/tasks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -49,12 +53,22 @@ func TestTaskErrorsGetUnwrapped(t *testing.T) {
 	subject, err := clientFromTestServer(s, "key", "secret")
 	require.NoError(t, err)
 
-	err = subject.CloudAccount.Delete(context.TODO(), 1)
+	err = subject.CloudAccount.Delete(WithCorrelationID(context.TODO(), "correlation"), 1)
+	assert.True(t, errors.Is(err, apierrors.ErrTaskFailed))
+
+	var actual *apierrors.TaskError
+	require.True(t, errors.As(err, &actual))
+	assert.Equal(t, "task", actual.TaskID)
+	assert.Equal(t, "cloudAccountDeleteRequest", actual.CommandType)
+	assert.Equal(t, "processing-error", actual.Status)
+	assert.Equal(t, "correlation", actual.CorrelationID)
+	assert.Equal(t, apierrors.ResourceCloudAccount, actual.ResourceKind)
+	assert.Equal(t, "1", actual.ResourceID)
 	assert.Equal(t, &internal.Error{
 		Type:        redis.String("SUBSCRIPTION_PI_NOT_FOUND"),
 		Description: redis.String("Payment info was not found for subscription. Use 'GET /payment-methods' to lookup valid payment methods for current Account"),
 		Status:      redis.String("400 BAD_REQUEST"),
-	}, errors.Unwrap(err))
+	}, errors.Unwrap(actual))
 }
 
 func TestTask_Handles404Eventually(t *testing.T) {
@@ -93,13 +107,182 @@ func TestTask_Handles404Eventually(t *testing.T) {
 	subject, err := clientFromTestServer(s, "key", "secret")
 	require.NoError(t, err)
 
-	err = subject.CloudAccount.Delete(context.TODO(), 1)
+	err = subject.CloudAccount.Delete(WithCorrelationID(context.TODO(), "correlation"), 1)
+
+	assert.True(t, errors.Is(err, apierrors.ErrNotFound))
 
 	var actual *internal.HTTPError
 	require.True(t, errors.As(err, &actual))
 	assert.Equal(t, &internal.HTTPError{
-		Name:       "retrieve task task",
-		StatusCode: 404,
-		Body:       []byte{},
+		Name:          "retrieve task task",
+		StatusCode:    404,
+		Body:          []byte{},
+		Attempts:      1,
+		CorrelationID: "correlation",
+		ResourceKind:  apierrors.ResourceTask,
+		ResourceID:    "task",
+	}, actual)
+}
+
+func TestTask_Get(t *testing.T) {
+	s := httptest.NewServer(testServer("key", "secret", getRequest(t, "/tasks/task-id", `{
+  "taskId": "task-id",
+  "commandType": "databaseCreateRequest",
+  "status": "processing-completed",
+  "description": "Request processing completed successfully and its resources are now being provisioned / de-provisioned.",
+  "timestamp": "2020-10-28T09:58:16.798Z",
+  "response": {
+    "resourceId": 1234
+  },
+  "_links": {
+    "self": {
+      "href": "https://example.com",
+      "type": "GET"
+    }
+  }
+}`)))
+
+	subject, err := clientFromTestServer(s, "key", "secret")
+	require.NoError(t, err)
+
+	actual, err := subject.Task.Get(context.TODO(), "task-id")
+	require.NoError(t, err)
+
+	assert.Equal(t, &tasks.Task{
+		ID:          redis.String("task-id"),
+		CommandType: redis.String("databaseCreateRequest"),
+		Status:      redis.String(tasks.StatusProcessingCompleted),
+		Description: redis.String("Request processing completed successfully and its resources are now being provisioned / de-provisioned."),
+		Timestamp:   redis.Time(time.Date(2020, 10, 28, 9, 58, 16, 798000000, time.UTC)),
+		Response: &tasks.Response{
+			ID: redis.Int(1234),
+		},
+	}, actual)
+}
+
+func TestTask_List(t *testing.T) {
+	s := httptest.NewServer(testServer("key", "secret", getRequest(t, "/tasks", `[
+  {
+    "taskId": "first",
+    "commandType": "subscriptionCreateRequest",
+    "status": "processing-in-progress",
+    "description": "Task request is being processed",
+    "timestamp": "2020-10-28T09:58:16.798Z",
+    "response": {}
+  },
+  {
+    "taskId": "second",
+    "commandType": "cloudAccountDeleteRequest",
+    "status": "processing-error",
+    "timestamp": "2020-10-28T09:58:16.798Z",
+    "response": {
+      "error": {
+        "type": "CLOUD_ACCOUNT_NOT_FOUND",
+        "status": "404 NOT_FOUND",
+        "description": "Cloud account was not found"
+      }
+    }
+  }
+]`)))
+
+	subject, err := clientFromTestServer(s, "key", "secret")
+	require.NoError(t, err)
+
+	actual, err := subject.Task.List(context.TODO())
+	require.NoError(t, err)
+
+	assert.Equal(t, []*tasks.Task{
+		{
+			ID:          redis.String("first"),
+			CommandType: redis.String("subscriptionCreateRequest"),
+			Status:      redis.String(tasks.StatusProcessingInProgress),
+			Description: redis.String("Task request is being processed"),
+			Timestamp:   redis.Time(time.Date(2020, 10, 28, 9, 58, 16, 798000000, time.UTC)),
+			Response:    &tasks.Response{},
+		},
+		{
+			ID:          redis.String("second"),
+			CommandType: redis.String("cloudAccountDeleteRequest"),
+			Status:      redis.String(tasks.StatusProcessingError),
+			Timestamp:   redis.Time(time.Date(2020, 10, 28, 9, 58, 16, 798000000, time.UTC)),
+			Response: &tasks.Response{
+				Error: &apierrors.Error{
+					Type:        redis.String("CLOUD_ACCOUNT_NOT_FOUND"),
+					Description: redis.String("Cloud account was not found"),
+					Status:      redis.String("404 NOT_FOUND"),
+				},
+			},
+		},
 	}, actual)
 }
+
+func TestTask_ObserversAreNotifiedOfStatusChanges(t *testing.T) {
+	taskWithStatus := func(status string, description string) string {
+		return fmt.Sprintf(`{
+  "taskId": "task",
+  "commandType": "cloudAccountDeleteRequest",
+  "status": "%s",
+  "description": "%s",
+  "timestamp": "2020-10-28T09:58:16.798Z",
+  "response": {}
+}`, status, description)
+	}
+
+	s := httptest.NewServer(testServer("key", "secret",
+		deleteRequest(t, "/cloud-accounts/1", taskWithStatus("received", "Task request received and is being queued for processing.")),
+		getRequest(t, "/tasks/task", taskWithStatus("received", "Task request received and is being queued for processing.")),
+		getRequest(t, "/tasks/task", taskWithStatus("processing-in-progress", "Task request is being processed")),
+		getRequest(t, "/tasks/task", taskWithStatus("processing-in-progress", "Task request is being processed")),
+		getRequest(t, "/tasks/task", taskWithStatus("processing-completed", "Request processing completed successfully"))))
+
+	var clientChanges []TaskStatusChange
+	subject, err := NewClient(BaseURL(s.URL), Auth("key", "secret"), Transporter(s.Client().Transport),
+		TaskPolling(TaskPollingPolicy{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond}),
+		ObserveTasks(TaskObserverFunc(func(change TaskStatusChange) {
+			clientChanges = append(clientChanges, change)
+		})))
+	require.NoError(t, err)
+
+	var callChanges []TaskStatusChange
+	ctx := WithTaskObserver(context.TODO(), TaskObserverFunc(func(change TaskStatusChange) {
+		callChanges = append(callChanges, change)
+	}))
+	var nestedChanges []TaskStatusChange
+	ctx = WithTaskObserver(ctx, TaskObserverFunc(func(change TaskStatusChange) {
+		nestedChanges = append(nestedChanges, change)
+	}))
+
+	err = subject.CloudAccount.Delete(ctx, 1)
+	require.NoError(t, err)
+
+	timestamp := time.Date(2020, 10, 28, 9, 58, 16, 798000000, time.UTC)
+	expected := []TaskStatusChange{
+		{
+			TaskID:         "task",
+			CommandType:    "cloudAccountDeleteRequest",
+			PreviousStatus: "",
+			Status:         "received",
+			Description:    "Task request received and is being queued for processing.",
+			Timestamp:      timestamp,
+		},
+		{
+			TaskID:         "task",
+			CommandType:    "cloudAccountDeleteRequest",
+			PreviousStatus: "received",
+			Status:         "processing-in-progress",
+			Description:    "Task request is being processed",
+			Timestamp:      timestamp,
+		},
+		{
+			TaskID:         "task",
+			CommandType:    "cloudAccountDeleteRequest",
+			PreviousStatus: "processing-in-progress",
+			Status:         "processing-completed",
+			Description:    "Request processing completed successfully",
+			Timestamp:      timestamp,
+		},
+	}
+	assert.Equal(t, expected, clientChanges)
+	assert.Equal(t, expected, callChanges)
+	assert.Equal(t, expected, nestedChanges)
+}
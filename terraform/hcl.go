@@ -0,0 +1,153 @@
+package terraform
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// body is the content of an HCL block, written in the same layout as `terraform fmt`.
+type body struct {
+	items []item
+}
+
+// item is either an attribute or a nested block.
+type item struct {
+	name  string
+	value string
+	block *block
+}
+
+type block struct {
+	typ    string
+	labels []string
+	body   *body
+}
+
+func (b *body) attribute(name string, value string) {
+	b.items = append(b.items, item{name: name, value: value})
+}
+
+func (b *body) block(typ string, labels ...string) *body {
+	nested := &body{}
+	b.items = append(b.items, item{block: &block{typ: typ, labels: labels, body: nested}})
+	return nested
+}
+
+func (b *body) string(name string, value *string) {
+	if value != nil {
+		b.attribute(name, quote(*value))
+	}
+}
+
+func (b *body) int(name string, value *int) {
+	if value != nil {
+		b.attribute(name, strconv.Itoa(*value))
+	}
+}
+
+func (b *body) float(name string, value *float64) {
+	if value != nil {
+		b.attribute(name, strconv.FormatFloat(*value, 'f', -1, 64))
+	}
+}
+
+func (b *body) bool(name string, value *bool) {
+	if value != nil {
+		b.attribute(name, strconv.FormatBool(*value))
+	}
+}
+
+func (b *body) strings(name string, values []*string) {
+	if len(values) == 0 {
+		return
+	}
+	var quoted []string
+	for _, value := range values {
+		if value != nil {
+			quoted = append(quoted, quote(*value))
+		}
+	}
+	b.attribute(name, "["+strings.Join(quoted, ", ")+"]")
+}
+
+// write writes the items of the body at the given depth. As `terraform fmt` does, the equals signs of consecutive
+// attributes are aligned, and blocks are separated from what comes before them by a blank line.
+func (b *body) write(w io.Writer, depth int) error {
+	indent := strings.Repeat("  ", depth)
+
+	for i := 0; i < len(b.items); i++ {
+		it := b.items[i]
+
+		if it.block != nil {
+			if i > 0 {
+				if _, err := fmt.Fprintln(w); err != nil {
+					return err
+				}
+			}
+			if err := it.block.write(w, depth); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Find the attributes in this run to align their values
+		end := i
+		width := 0
+		for ; end < len(b.items) && b.items[end].block == nil; end++ {
+			if len(b.items[end].name) > width {
+				width = len(b.items[end].name)
+			}
+		}
+		if i > 0 && b.items[i-1].block != nil {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		for ; i < end; i++ {
+			if _, err := fmt.Fprintf(w, "%s%-*s = %s\n", indent, width, b.items[i].name, b.items[i].value); err != nil {
+				return err
+			}
+		}
+		i--
+	}
+	return nil
+}
+
+func (b *block) write(w io.Writer, depth int) error {
+	indent := strings.Repeat("  ", depth)
+
+	header := b.typ
+	for _, label := range b.labels {
+		header += " " + quote(label)
+	}
+	if len(b.body.items) == 0 {
+		_, err := fmt.Fprintf(w, "%s%s {}\n", indent, header)
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "%s%s {\n", indent, header); err != nil {
+		return err
+	}
+	if err := b.body.write(w, depth+1); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "%s}\n", indent)
+	return err
+}
+
+var quoter = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\t", `\t`,
+	"${", "$${",
+	"%{", "%%{",
+)
+
+// quote returns the value as an HCL string literal, escaping anything that would otherwise be interpolated.
+func quote(value string) string {
+	return `"` + quoter.Replace(value) + `"`
+}
@@ -0,0 +1,306 @@
+// Package terraform generates the Terraform configuration for resources that already exist in an account, so that
+// they can be brought under the management of the `rediscloud` Terraform provider:
+//
+//	config, err := terraform.Generate(ctx, client)
+//	...
+//	err = config.WriteHCL(mainTF)
+//	...
+//	err = config.WriteImports(importScript)
+//
+// Each cloud account, subscription - along with its databases and CIDR allowlist - and VPC peering is written as a
+// resource, with a `terraform import` command to match. Secrets aren't returned by the API, so they are written as
+// references to sensitive variables instead, and the region of each AWS VPC peering - which isn't returned either - is
+// a required variable.
+package terraform
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	rediscloud_api "github.com/RedisLabs/rediscloud-go-api"
+	"github.com/RedisLabs/rediscloud-go-api/export"
+	"github.com/RedisLabs/rediscloud-go-api/redis"
+	"github.com/RedisLabs/rediscloud-go-api/service/cloud_accounts"
+	"github.com/RedisLabs/rediscloud-go-api/service/databases"
+	"github.com/RedisLabs/rediscloud-go-api/service/subscriptions"
+)
+
+const (
+	ResourceCloudAccount = "rediscloud_cloud_account"
+	ResourceSubscription = "rediscloud_subscription"
+	ResourcePeering      = "rediscloud_subscription_peering"
+)
+
+// Config is the generated configuration.
+type Config struct {
+	Variables []Variable
+	Resources []Resource
+}
+
+// Variable is an input variable, which is used for each secret and each value that the API doesn't return.
+type Variable struct {
+	Name        string
+	Description string
+	// Sensitive is set for the variables of secrets.
+	Sensitive bool
+}
+
+// Resource is a single generated resource.
+type Resource struct {
+	Type string
+	Name string
+	// ImportID is the identifier given to `terraform import` to import the existing resource.
+	ImportID string
+
+	body *body
+}
+
+// Address returns the address of the resource, such as `rediscloud_subscription.production`.
+func (r Resource) Address() string {
+	return r.Type + "." + r.Name
+}
+
+// Generate reads everything in the account and generates its configuration.
+func Generate(ctx context.Context, client *rediscloud_api.Client) (*Config, error) {
+	snapshot, err := export.Export(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	return FromSnapshot(snapshot), nil
+}
+
+// FromSnapshot generates the configuration of the resources in a snapshot taken by the export package.
+func FromSnapshot(snapshot *export.Snapshot) *Config {
+	g := &generator{config: &Config{}, names: map[string]bool{}, cloudAccounts: map[int]string{}}
+
+	for _, cloudAccount := range snapshot.CloudAccounts {
+		g.cloudAccount(cloudAccount)
+	}
+	for _, sub := range snapshot.Subscriptions {
+		g.subscription(sub)
+	}
+	return g.config
+}
+
+type generator struct {
+	config *Config
+	// names that have been used, either for resources of a particular type or for variables.
+	names map[string]bool
+	// cloudAccounts maps the identifier of a cloud account to the address of its resource.
+	cloudAccounts map[int]string
+}
+
+func (g *generator) cloudAccount(cloudAccount *cloud_accounts.CloudAccount) {
+	name := g.name(ResourceCloudAccount, redis.StringValue(cloudAccount.Name))
+	b := &body{}
+
+	b.string("name", cloudAccount.Name)
+	b.string("provider_type", cloudAccount.Provider)
+	b.string("access_key_id", cloudAccount.AccessKeyID)
+	b.attribute("access_secret_key", g.variable(name+"_access_secret_key", fmt.Sprintf("Access secret key of cloud account %q", redis.StringValue(cloudAccount.Name))))
+	b.attribute("console_username", g.variable(name+"_console_username", fmt.Sprintf("Console username of cloud account %q", redis.StringValue(cloudAccount.Name))))
+	b.attribute("console_password", g.variable(name+"_console_password", fmt.Sprintf("Console password of cloud account %q", redis.StringValue(cloudAccount.Name))))
+	b.attribute("sign_in_login_url", g.variable(name+"_sign_in_login_url", fmt.Sprintf("Console sign-in URL of cloud account %q", redis.StringValue(cloudAccount.Name))))
+
+	resource := g.add(ResourceCloudAccount, name, strconv.Itoa(redis.IntValue(cloudAccount.ID)), b)
+	g.cloudAccounts[redis.IntValue(cloudAccount.ID)] = resource.Address()
+}
+
+func (g *generator) subscription(sub *export.Subscription) {
+	name := g.name(ResourceSubscription, redis.StringValue(sub.Name))
+	b := &body{}
+
+	b.string("name", sub.Name)
+	b.int("payment_method_id", sub.PaymentMethodID)
+	b.string("memory_storage", sub.MemoryStorage)
+	b.bool("persistent_storage_encryption", sub.StorageEncryption)
+
+	for _, cloud := range sub.CloudDetails {
+		g.cloudProvider(b.block("cloud_provider"), cloud)
+	}
+
+	if sub.CIDRAllowlist != nil && (len(sub.CIDRAllowlist.CIDRIPs) > 0 || len(sub.CIDRAllowlist.SecurityGroupIDs) > 0) {
+		allowlist := b.block("allowlist")
+		allowlist.strings("cidrs", sub.CIDRAllowlist.CIDRIPs)
+		allowlist.strings("security_group_ids", sub.CIDRAllowlist.SecurityGroupIDs)
+	}
+
+	for _, db := range sub.Databases {
+		g.database(b.block("database"), name, db)
+	}
+
+	resource := g.add(ResourceSubscription, name, strconv.Itoa(redis.IntValue(sub.ID)), b)
+
+	for _, peering := range sub.Peerings {
+		g.peering(resource, sub, peering)
+	}
+}
+
+func (g *generator) cloudProvider(b *body, cloud *subscriptions.CloudDetail) {
+	b.string("provider", cloud.Provider)
+	if cloud.CloudAccountID != nil {
+		if address, ok := g.cloudAccounts[*cloud.CloudAccountID]; ok {
+			b.attribute("cloud_account_id", address+".id")
+		} else {
+			b.int("cloud_account_id", cloud.CloudAccountID)
+		}
+	}
+
+	for _, region := range cloud.Regions {
+		r := b.block("region")
+		r.string("region", region.Region)
+		r.bool("multiple_availability_zones", region.MultipleAvailabilityZones)
+		r.strings("preferred_availability_zones", region.PreferredAvailabilityZones)
+		if len(region.Networking) > 0 {
+			r.string("networking_deployment_cidr", region.Networking[0].DeploymentCIDR)
+			r.string("networking_vpc_id", region.Networking[0].VPCId)
+		}
+	}
+}
+
+func (g *generator) database(b *body, subscription string, db *databases.Database) {
+	b.string("name", db.Name)
+	b.string("protocol", db.Protocol)
+	b.float("memory_limit_in_gb", db.MemoryLimitInGB)
+	b.bool("support_oss_cluster_api", db.SupportOSSClusterAPI)
+	b.string("data_persistence", db.DataPersistence)
+	b.string("data_eviction", db.DataEvictionPolicy)
+	b.bool("replication", db.Replication)
+	if db.ThroughputMeasurement != nil {
+		b.string("throughput_measurement_by", db.ThroughputMeasurement.By)
+		b.int("throughput_measurement_value", db.ThroughputMeasurement.Value)
+	}
+	if db.ReplicaOf != nil {
+		b.strings("replica_of", db.ReplicaOf.Endpoints)
+	}
+	if db.Security != nil {
+		b.strings("source_ips", db.Security.SourceIPs)
+		if db.Security.Password != nil {
+			variable := identifier(subscription + "_" + redis.StringValue(db.Name) + "_password")
+			b.attribute("password", g.variable(variable, fmt.Sprintf("Password of database %q", redis.StringValue(db.Name))))
+		}
+	}
+
+	for _, alert := range db.Alerts {
+		a := b.block("alert")
+		a.string("name", alert.Name)
+		a.int("value", alert.Value)
+	}
+	for _, module := range db.Modules {
+		b.block("module").string("name", module.Name)
+	}
+}
+
+func (g *generator) peering(subscription Resource, sub *export.Subscription, peering *subscriptions.VPCPeering) {
+	b := &body{}
+	b.attribute("subscription_id", subscription.Address()+".id")
+
+	var name string
+	if peering.GCPProjectUID != nil {
+		name = g.name(ResourcePeering, subscription.Name+"_"+redis.StringValue(peering.GCPProjectUID)+"_"+redis.StringValue(peering.NetworkName))
+		b.attribute("provider_name", quote("GCP"))
+		b.string("gcp_project_id", peering.GCPProjectUID)
+		b.string("gcp_network_name", peering.NetworkName)
+	} else {
+		name = g.name(ResourcePeering, subscription.Name+"_"+redis.StringValue(peering.VPCId))
+		// The API doesn't return the region of the peered VPC, which needn't be the region of the subscription
+		description := fmt.Sprintf("Region of VPC %q peered with subscription %q", redis.StringValue(peering.VPCId), redis.StringValue(sub.Name))
+		b.attribute("region", g.required(name+"_region", description))
+		b.string("aws_account_id", peering.AWSAccountID)
+		b.string("vpc_id", peering.VPCId)
+		b.string("vpc_cidr", peering.VPCCidr)
+	}
+
+	importID := fmt.Sprintf("%d/%d", redis.IntValue(sub.ID), redis.IntValue(peering.ID))
+	g.add(ResourcePeering, name, importID, b)
+}
+
+func (g *generator) add(typ string, name string, importID string, b *body) Resource {
+	resource := Resource{Type: typ, Name: name, ImportID: importID, body: b}
+	g.config.Resources = append(g.config.Resources, resource)
+	return resource
+}
+
+// variable adds a sensitive variable for a secret, returning a reference to it.
+func (g *generator) variable(name string, description string) string {
+	return g.addVariable(Variable{Name: name, Description: description, Sensitive: true})
+}
+
+// required adds a variable for a value that the API doesn't return, returning a reference to it.
+func (g *generator) required(name string, description string) string {
+	return g.addVariable(Variable{Name: name, Description: description})
+}
+
+func (g *generator) addVariable(variable Variable) string {
+	variable.Name = g.name("var", variable.Name)
+	g.config.Variables = append(g.config.Variables, variable)
+	return "var." + variable.Name
+}
+
+// name returns a unique identifier, based on the name of the resource in the API, for a resource of the given type.
+func (g *generator) name(typ string, name string) string {
+	base := identifier(name)
+	name = base
+	for i := 2; g.names[typ+"."+name]; i++ {
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+	g.names[typ+"."+name] = true
+	return name
+}
+
+// identifier turns a name into a valid Terraform identifier, such as `My Database` into `my_database`.
+func identifier(name string) string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' {
+			b.WriteRune(r)
+			underscore = false
+		} else if !underscore {
+			b.WriteRune('_')
+			underscore = true
+		}
+	}
+
+	id := strings.Trim(b.String(), "_")
+	if id == "" {
+		return "unnamed"
+	}
+	if id[0] >= '0' && id[0] <= '9' || id[0] == '-' {
+		id = "_" + id
+	}
+	return id
+}
+
+// WriteHCL writes the variables and resources of the configuration.
+func (c *Config) WriteHCL(w io.Writer) error {
+	top := &body{}
+	for _, variable := range c.Variables {
+		b := top.block("variable", variable.Name)
+		b.string("description", &variable.Description)
+		b.attribute("type", "string")
+		if variable.Sensitive {
+			b.attribute("sensitive", "true")
+		}
+	}
+	for _, resource := range c.Resources {
+		top.items = append(top.items, item{block: &block{typ: "resource", labels: []string{resource.Type, resource.Name}, body: resource.body}})
+	}
+	return top.write(w, 0)
+}
+
+// WriteImports writes a `terraform import` command for each resource, as a shell script.
+func (c *Config) WriteImports(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, "#!/bin/sh\nset -e"); err != nil {
+		return err
+	}
+	for _, resource := range c.Resources {
+		if _, err := fmt.Fprintf(w, "terraform import %s %s\n", resource.Address(), resource.ImportID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
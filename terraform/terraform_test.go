@@ -0,0 +1,249 @@
+package terraform_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	rediscloud_api "github.com/RedisLabs/rediscloud-go-api"
+	"github.com/RedisLabs/rediscloud-go-api/export"
+	"github.com/RedisLabs/rediscloud-go-api/redis"
+	"github.com/RedisLabs/rediscloud-go-api/rediscloudtest"
+	"github.com/RedisLabs/rediscloud-go-api/service/cloud_accounts"
+	"github.com/RedisLabs/rediscloud-go-api/service/databases"
+	"github.com/RedisLabs/rediscloud-go-api/service/subscriptions"
+	"github.com/RedisLabs/rediscloud-go-api/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromSnapshot(t *testing.T) {
+	snapshot := &export.Snapshot{
+		CloudAccounts: []*cloud_accounts.CloudAccount{
+			{ID: redis.Int(10), Name: redis.String("Production AWS"), Provider: redis.String("AWS"), AccessKeyID: redis.String("AKIA1234")},
+		},
+		Subscriptions: []*export.Subscription{
+			{
+				Subscription: subscriptions.Subscription{
+					ID:                redis.Int(20),
+					Name:              redis.String("production"),
+					PaymentMethodID:   redis.Int(1),
+					MemoryStorage:     redis.String("ram"),
+					StorageEncryption: redis.Bool(true),
+					CloudDetails: []*subscriptions.CloudDetail{
+						{
+							Provider:       redis.String("AWS"),
+							CloudAccountID: redis.Int(10),
+							Regions: []*subscriptions.Region{
+								{
+									Region:                     redis.String("us-east-1"),
+									MultipleAvailabilityZones:  redis.Bool(false),
+									PreferredAvailabilityZones: redis.StringSlice("us-east-1a"),
+									Networking: []*subscriptions.Networking{
+										{DeploymentCIDR: redis.String("10.0.0.0/24"), VPCId: redis.String("vpc-0001")},
+									},
+								},
+							},
+						},
+					},
+				},
+				Databases: []*databases.Database{
+					{
+						ID:                    redis.Int(30),
+						Name:                  redis.String("cache"),
+						Protocol:              redis.String("redis"),
+						MemoryLimitInGB:       redis.Float64(1.5),
+						SupportOSSClusterAPI:  redis.Bool(false),
+						DataPersistence:       redis.String("none"),
+						DataEvictionPolicy:    redis.String("allkeys-lru"),
+						Replication:           redis.Bool(true),
+						ThroughputMeasurement: &databases.Throughput{By: redis.String("operations-per-second"), Value: redis.Int(10000)},
+						Security: &databases.Security{
+							SourceIPs: redis.StringSlice("10.0.0.0/16"),
+							Password:  redis.String(export.Redacted),
+						},
+						Alerts:  []*databases.Alert{{Name: redis.String("dataset-size"), Value: redis.Int(80)}},
+						Modules: []*databases.Module{{Name: redis.String("RedisJSON")}},
+					},
+				},
+				CIDRAllowlist: &subscriptions.CIDRAllowlist{CIDRIPs: redis.StringSlice("10.0.0.0/24")},
+				Peerings: []*subscriptions.VPCPeering{
+					{ID: redis.Int(40), AWSAccountID: redis.String("123456789012"), VPCId: redis.String("vpc-0002"), VPCCidr: redis.String("10.1.0.0/24")},
+					{ID: redis.Int(41), GCPProjectUID: redis.String("my-project"), NetworkName: redis.String("default")},
+				},
+			},
+		},
+	}
+
+	config := terraform.FromSnapshot(snapshot)
+
+	var hcl bytes.Buffer
+	require.NoError(t, config.WriteHCL(&hcl))
+	assert.Equal(t, `variable "production_aws_access_secret_key" {
+  description = "Access secret key of cloud account \"Production AWS\""
+  type        = string
+  sensitive   = true
+}
+
+variable "production_aws_console_username" {
+  description = "Console username of cloud account \"Production AWS\""
+  type        = string
+  sensitive   = true
+}
+
+variable "production_aws_console_password" {
+  description = "Console password of cloud account \"Production AWS\""
+  type        = string
+  sensitive   = true
+}
+
+variable "production_aws_sign_in_login_url" {
+  description = "Console sign-in URL of cloud account \"Production AWS\""
+  type        = string
+  sensitive   = true
+}
+
+variable "production_cache_password" {
+  description = "Password of database \"cache\""
+  type        = string
+  sensitive   = true
+}
+
+variable "production_vpc-0002_region" {
+  description = "Region of VPC \"vpc-0002\" peered with subscription \"production\""
+  type        = string
+}
+
+resource "rediscloud_cloud_account" "production_aws" {
+  name              = "Production AWS"
+  provider_type     = "AWS"
+  access_key_id     = "AKIA1234"
+  access_secret_key = var.production_aws_access_secret_key
+  console_username  = var.production_aws_console_username
+  console_password  = var.production_aws_console_password
+  sign_in_login_url = var.production_aws_sign_in_login_url
+}
+
+resource "rediscloud_subscription" "production" {
+  name                          = "production"
+  payment_method_id             = 1
+  memory_storage                = "ram"
+  persistent_storage_encryption = true
+
+  cloud_provider {
+    provider         = "AWS"
+    cloud_account_id = rediscloud_cloud_account.production_aws.id
+
+    region {
+      region                       = "us-east-1"
+      multiple_availability_zones  = false
+      preferred_availability_zones = ["us-east-1a"]
+      networking_deployment_cidr   = "10.0.0.0/24"
+      networking_vpc_id            = "vpc-0001"
+    }
+  }
+
+  allowlist {
+    cidrs = ["10.0.0.0/24"]
+  }
+
+  database {
+    name                         = "cache"
+    protocol                     = "redis"
+    memory_limit_in_gb           = 1.5
+    support_oss_cluster_api      = false
+    data_persistence             = "none"
+    data_eviction                = "allkeys-lru"
+    replication                  = true
+    throughput_measurement_by    = "operations-per-second"
+    throughput_measurement_value = 10000
+    source_ips                   = ["10.0.0.0/16"]
+    password                     = var.production_cache_password
+
+    alert {
+      name  = "dataset-size"
+      value = 80
+    }
+
+    module {
+      name = "RedisJSON"
+    }
+  }
+}
+
+resource "rediscloud_subscription_peering" "production_vpc-0002" {
+  subscription_id = rediscloud_subscription.production.id
+  region          = var.production_vpc-0002_region
+  aws_account_id  = "123456789012"
+  vpc_id          = "vpc-0002"
+  vpc_cidr        = "10.1.0.0/24"
+}
+
+resource "rediscloud_subscription_peering" "production_my-project_default" {
+  subscription_id  = rediscloud_subscription.production.id
+  provider_name    = "GCP"
+  gcp_project_id   = "my-project"
+  gcp_network_name = "default"
+}
+`, hcl.String())
+
+	var imports bytes.Buffer
+	require.NoError(t, config.WriteImports(&imports))
+	assert.Equal(t, `#!/bin/sh
+set -e
+terraform import rediscloud_cloud_account.production_aws 10
+terraform import rediscloud_subscription.production 20
+terraform import rediscloud_subscription_peering.production_vpc-0002 20/40
+terraform import rediscloud_subscription_peering.production_my-project_default 20/41
+`, imports.String())
+}
+
+func TestFromSnapshot_NamesAreUniqueAndValid(t *testing.T) {
+	snapshot := &export.Snapshot{
+		Subscriptions: []*export.Subscription{
+			{Subscription: subscriptions.Subscription{ID: redis.Int(1), Name: redis.String("My Subscription")}},
+			{Subscription: subscriptions.Subscription{ID: redis.Int(2), Name: redis.String("my-subscription!")}},
+			{Subscription: subscriptions.Subscription{ID: redis.Int(3), Name: redis.String("my subscription")}},
+			{Subscription: subscriptions.Subscription{ID: redis.Int(4), Name: redis.String("2021 ${env}")}},
+		},
+	}
+
+	config := terraform.FromSnapshot(snapshot)
+
+	var names []string
+	for _, resource := range config.Resources {
+		names = append(names, resource.Name)
+	}
+	assert.Equal(t, []string{"my_subscription", "my-subscription", "my_subscription_2", "_2021_env"}, names)
+
+	var hcl bytes.Buffer
+	require.NoError(t, config.WriteHCL(&hcl))
+	assert.Contains(t, hcl.String(), `name = "2021 $${env}"`)
+}
+
+func TestGenerate(t *testing.T) {
+	fake := rediscloudtest.NewServer()
+	defer fake.Close()
+
+	sub := fake.AddSubscription(subscriptions.Subscription{Name: redis.String("example")})
+	_, err := fake.AddDatabase(sub, databases.Database{Name: redis.String("cache"), MemoryLimitInGB: redis.Float64(1)})
+	require.NoError(t, err)
+
+	client, err := rediscloud_api.NewClient(
+		rediscloud_api.BaseURL(fake.URL),
+		rediscloud_api.Auth("key", "secret"),
+		rediscloud_api.TaskPolling(rediscloud_api.TaskPollingPolicy{InitialDelay: time.Millisecond, MaxDelay: time.Millisecond}),
+	)
+	require.NoError(t, err)
+
+	config, err := terraform.Generate(context.Background(), client)
+	require.NoError(t, err)
+
+	require.Len(t, config.Resources, 1)
+	assert.Equal(t, "rediscloud_subscription.example", config.Resources[0].Address())
+
+	var hcl bytes.Buffer
+	require.NoError(t, config.WriteHCL(&hcl))
+	assert.Contains(t, hcl.String(), `memory_limit_in_gb = 1`)
+}
@@ -0,0 +1,107 @@
+// Package tracing defines the hooks that the client uses to trace its operations, so that they can be forwarded to a
+// tracing library such as OpenTelemetry.
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/RedisLabs/rediscloud-go-api/logging"
+)
+
+// TraceParentHeader is the W3C Trace Context header set on every request made while a span is being recorded.
+const TraceParentHeader = "traceparent"
+
+// The keys of the attributes that the client sets on its spans, in addition to those from the logging package such as
+// `logging.KeySubscriptionID` and `logging.KeyTaskID`.
+const (
+	KeyHTTPMethod     = "http.method"
+	KeyHTTPRoute      = "http.route"
+	KeyHTTPStatusCode = "http.status_code"
+	KeyHTTPAttempt    = "http.attempt"
+	KeyCommandType    = "commandType"
+)
+
+// Tracer starts the spans recorded by the client. Every operation, such as creating a database, is a span with a child
+// span for each HTTP request and for waiting on its task, which in turn has a child span for every poll of the task.
+type Tracer interface {
+	// Start begins a span as a child of any span in `ctx`, returning a context containing the new span. `keyvals`
+	// holds alternating keys and values, with the keys always being strings.
+	Start(ctx context.Context, name string, keyvals ...interface{}) (context.Context, Span)
+}
+
+// Span is a single unit of work being traced.
+type Span interface {
+	// SetAttributes adds alternating keys and values to the span.
+	SetAttributes(keyvals ...interface{})
+	// SpanContext identifies the span to the API, through the `traceparent` header. A span whose context isn't valid
+	// won't be propagated.
+	SpanContext() SpanContext
+	// End finishes the span, which failed if `err` isn't nil.
+	End(err error)
+}
+
+// SpanContext is the part of a span that is propagated to the API.
+type SpanContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Sampled bool
+}
+
+// IsValid is false if either the trace or the span ID is all zeros.
+func (s SpanContext) IsValid() bool {
+	return s.TraceID != [16]byte{} && s.SpanID != [8]byte{}
+}
+
+// TraceParent formats the span context as the value of a W3C `traceparent` header, e.g.
+// `00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01`.
+func (s SpanContext) TraceParent() string {
+	flags := "00"
+	if s.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", hex.EncodeToString(s.TraceID[:]), hex.EncodeToString(s.SpanID[:]), flags)
+}
+
+// Noop is a Tracer that doesn't record anything.
+var Noop Tracer = noopTracer{}
+
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, _ string, _ ...interface{}) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(...interface{}) {}
+
+func (noopSpan) SpanContext() SpanContext {
+	return SpanContext{}
+}
+
+func (noopSpan) End(error) {}
+
+type operationKey struct{}
+
+// StartOperation begins the span for an operation of the client, such as `update database 3 for subscription 12`,
+// and adds the operation and its keys and values to everything logged within it. If the context is already within an
+// operation, e.g. because `Update` calls `UpdateAsync`, the context is returned as it is and no span is started, so
+// that the outermost call is the operation.
+//
+// The outermost call also generates the correlation ID that all of the operation's requests are sent with, including
+// the polls of its task, unless the context already has one.
+func StartOperation(ctx context.Context, tracer Tracer, operation string, name string, keyvals ...interface{}) (context.Context, Span) {
+	if ctx.Value(operationKey{}) != nil {
+		return ctx, noopSpan{}
+	}
+
+	ctx = logging.ContextWithFields(ctx, append([]interface{}{logging.KeyOperation, operation}, keyvals...)...)
+
+	if id, _ := ctx.Value(correlationIDKey{}).(string); id == "" {
+		ctx = ContextWithCorrelationID(ctx, newCorrelationID())
+	}
+	ctx = context.WithValue(ctx, operationKey{}, operation)
+	return tracer.Start(ctx, name, append([]interface{}{logging.KeyOperation, operation}, keyvals...)...)
+}
@@ -0,0 +1,66 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RedisLabs/rediscloud-go-api/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpanContext_TraceParent(t *testing.T) {
+	subject := SpanContext{
+		TraceID: [16]byte{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:  [8]byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		Sampled: true,
+	}
+
+	assert.True(t, subject.IsValid())
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", subject.TraceParent())
+
+	subject.Sampled = false
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", subject.TraceParent())
+
+	assert.False(t, SpanContext{SpanID: subject.SpanID}.IsValid())
+	assert.False(t, SpanContext{TraceID: subject.TraceID}.IsValid())
+}
+
+type recordingTracer struct {
+	names []string
+	attrs [][]interface{}
+}
+
+func (r *recordingTracer) Start(ctx context.Context, name string, keyvals ...interface{}) (context.Context, Span) {
+	r.names = append(r.names, name)
+	r.attrs = append(r.attrs, keyvals)
+	return ctx, noopSpan{}
+}
+
+func TestStartOperation_onlyTheOutermostCallIsTheOperation(t *testing.T) {
+	tracer := &recordingTracer{}
+
+	ctx, _ := StartOperation(context.TODO(), tracer, "update database", "update database 3 for subscription 12",
+		logging.KeySubscriptionID, 12, logging.KeyDatabaseID, 3)
+	ctx, span := StartOperation(ctx, tracer, "get task", "get task 42", logging.KeyTaskID, "42")
+	span.End(nil)
+
+	assert.Equal(t, []string{"update database 3 for subscription 12"}, tracer.names)
+	assert.Equal(t, [][]interface{}{
+		{logging.KeyOperation, "update database", logging.KeySubscriptionID, 12, logging.KeyDatabaseID, 3},
+	}, tracer.attrs)
+	assert.Equal(t, []interface{}{
+		logging.KeyOperation, "update database", logging.KeySubscriptionID, 12, logging.KeyDatabaseID, 3,
+	}, logging.Fields(ctx))
+}
+
+func TestStartOperation_sharesOneCorrelationID(t *testing.T) {
+	ctx, _ := StartOperation(context.TODO(), Noop, "update database", "update database 3 for subscription 12")
+	nested, _ := StartOperation(ctx, Noop, "update database", "update database 3 for subscription 12")
+	assert.Equal(t, CorrelationID(ctx), CorrelationID(nested))
+
+	other, _ := StartOperation(context.TODO(), Noop, "update database", "update database 3 for subscription 12")
+	assert.NotEqual(t, CorrelationID(ctx), CorrelationID(other))
+
+	given, _ := StartOperation(ContextWithCorrelationID(context.TODO(), "correlation"), Noop, "update database", "update database 3 for subscription 12")
+	assert.Equal(t, "correlation", CorrelationID(given))
+}
@@ -173,3 +173,14 @@ func putRequest(t *testing.T, path string, request string, body string) endpoint
 		t:           t,
 	}
 }
+
+func putRequestWithStatus(t *testing.T, path string, request string, status int, body string) endpointRequest {
+	return endpointRequest{
+		method:      http.MethodPut,
+		path:        path,
+		body:        body,
+		requestBody: &request,
+		status:      status,
+		t:           t,
+	}
+}